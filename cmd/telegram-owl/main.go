@@ -18,7 +18,7 @@ func main() {
 	if err := run(); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
 
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
 
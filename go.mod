@@ -5,6 +5,7 @@ go 1.26.5
 require (
 	github.com/stretchr/testify v1.11.1
 	github.com/urfave/cli/v3 v3.10.1
+	github.com/yuin/goldmark v1.8.6
 	resty.dev/v3 v3.0.0-rc.3
 )
 
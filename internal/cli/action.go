@@ -5,15 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"unicode/utf8"
+	"path/filepath"
 
 	"github.com/beeyev/telegram-owl/internal/telegram"
 	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/textsplit"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendrichmessage"
 )
 
+// albumCounterSeparator puts the --album-counter line below the caption.
+const albumCounterSeparator = "\n\n"
+
 type action struct {
 	ctx              context.Context
 	client           *telegram.Client
@@ -22,15 +28,35 @@ type action struct {
 	chatID           string
 	message          string
 	MessageFormat    string
+	entities         []entity.MessageEntity
+	captionEntities  []entity.MessageEntity
 	attachmentsPaths []string
 	silent           bool
 	noLinkPreview    bool
 	spoiler          bool
 	protect          bool
 	threadID         string
+	overflow         string
+	overflowFileName string
+	overflowKeep     textsplit.Keep
+	splitCounter     bool
+	splitReply       bool
+	albumCounter     bool
+	// splitFiles were sent as parts and get a manifest message each.
+	splitFiles []*attachment.SplitFile
+	// fileIDs is nil with --no-cache.
+	fileIDs *fileIDCache
 }
 
 func (a *action) execute() error {
+	if err := a.send(); err != nil {
+		return err
+	}
+
+	return a.sendManifests()
+}
+
+func (a *action) send() error {
 	if a.message == "" && len(a.attachmentsPaths) == 0 {
 		return errors.New("nothing to send: provide a --message or --attach flag")
 	}
@@ -64,36 +90,94 @@ func (a *action) execute() error {
 		return nil
 	}
 
-	// Telegram applies the caption limit after parsing entities. Raw length can
-	// determine routing only for plain text; formatted captions must be submitted
-	// so Telegram can validate their parsed length.
-	if a.MessageFormat != "" || utf8.RuneCountInString(a.message) <= sendmediagroup.MaxCaptionLength {
-		return a.sendMediaGroup(a.message)
+	groups, err := a.loadGroups()
+	if err != nil {
+		return err
+	}
+
+	// Telegram applies the caption limit after parsing entities, so formatted
+	// text is measured by its parsed length, in UTF-16 code units like the
+	// --overflow policies measure it. Text that does not parse is still sent as
+	// the caption, where validation reports where the error is.
+	// --album-counter keeps room for the "Part n/total" line of the last
+	// album, which carries the caption.
+	captionLimit := sendmediagroup.MaxCaptionLength
+	if a.albumCounter && len(groups) > 1 {
+		captionLimit -= len(albumCounterSeparator + albumCounterLine(len(groups), len(groups)))
+	}
+	if _, parseErr := markup.Parse(a.message, a.MessageFormat); parseErr != nil ||
+		textsplit.Length(a.message, a.MessageFormat) <= captionLimit {
+		return a.sendAlbums(groups, a.message)
 	}
 
 	// Longer text cannot be a caption. Send the attachments first, then the
-	// complete text as a separate message. Do not send the text if upload fails.
-	if err := a.sendMediaGroup(""); err != nil {
+	// complete text as a separate message, which --overflow applies to. Do not
+	// send the text if upload fails.
+	if err = a.sendAlbums(groups, ""); err != nil {
 		return err
 	}
 
 	return a.sendMessage(a.message)
 }
 
+// sendManifests finishes a send with the checksums and reassembly command of
+// every file that went out as parts.
+func (a *action) sendManifests() error {
+	for _, file := range a.splitFiles {
+		_, err := a.client.SendMessage.Send(a.ctx, &sendmessage.Options{
+			ChatID:              a.chatID,
+			Text:                file.Manifest(),
+			DisableNotification: a.silent,
+			ProtectContent:      a.protect,
+			MessageThreadID:     a.threadID,
+			DisableLinkPreview:  true,
+		})
+		if err != nil {
+			return fmt.Errorf("parts of %q sent, but the manifest failed: %w", filepath.Base(file.Path), err)
+		}
+	}
+
+	return nil
+}
+
 func (a *action) sendMessage(message string) error {
 	if message == "" {
 		return errors.New("message is required")
 	}
 
-	return a.client.SendMessage.Send(a.ctx, &sendmessage.Options{
-		ChatID:              a.chatID,
-		Text:                message,
-		ParseMode:           a.MessageFormat,
-		DisableNotification: a.silent,
-		ProtectContent:      a.protect,
-		MessageThreadID:     a.threadID,
-		DisableLinkPreview:  a.noLinkPreview,
-	})
+	parts, err := a.fitMessage(message)
+	if err != nil {
+		return err
+	}
+
+	// Parts are sent in order and the first failure stops the rest, so the
+	// chat never shows a later part without the ones before it.
+	replyTo := 0
+	for i, part := range parts {
+		sent, sendErr := a.client.SendMessage.Send(a.ctx, &sendmessage.Options{
+			ChatID:              a.chatID,
+			Text:                part,
+			ParseMode:           a.MessageFormat,
+			Entities:            a.entities,
+			DisableNotification: a.silent,
+			ProtectContent:      a.protect,
+			MessageThreadID:     a.threadID,
+			DisableLinkPreview:  a.noLinkPreview,
+			ReplyToMessageID:    replyTo,
+		})
+		if sendErr != nil {
+			if len(parts) > 1 {
+				return fmt.Errorf("send part %d of %d: %w", i+1, len(parts), sendErr)
+			}
+
+			return sendErr
+		}
+		if a.splitReply && i == 0 {
+			replyTo = sent.MessageID
+		}
+	}
+
+	return nil
 }
 
 func (a *action) sendRichMessage(message string) error {
@@ -112,23 +196,130 @@ func (a *action) sendRichMessage(message string) error {
 }
 
 func (a *action) sendMediaGroup(message string) error {
+	groups, err := a.loadGroups()
+	if err != nil {
+		return err
+	}
+
+	return a.sendAlbums(groups, message)
+}
+
+// loadGroups opens the attachments and splits them into albums. The caller
+// owns the open files and hands them to sendAlbums.
+func (a *action) loadGroups() ([]attachment.Attachments, error) {
 	if len(a.attachmentsPaths) == 0 {
-		return errors.New("no attachments to send")
+		return nil, errors.New("no attachments to send")
 	}
 
-	attachments, err := a.attachLoader.LoadMultipleAttachments(a.attachmentsPaths)
+	groups, err := a.attachLoader.LoadGroups(a.attachmentsPaths)
 	if err != nil {
-		return fmt.Errorf("failed to load attachments: %w", err)
+		return nil, fmt.Errorf("failed to load attachments: %w", err)
 	}
 
+	return groups, nil
+}
+
+func (a *action) sendAlbums(groups []attachment.Attachments, message string) error {
+	// Albums go out in order with the caption on the last one, so it reads as
+	// the summary below every file. The first failure stops the rest, and the
+	// files of albums that were never sent are closed here.
+	for i, group := range groups {
+		caption, parseMode := "", ""
+		var captionEntities []entity.MessageEntity
+		if i == len(groups)-1 && message != "" {
+			caption, parseMode, captionEntities = message, a.MessageFormat, a.captionEntities
+		}
+		if a.albumCounter && len(groups) > 1 {
+			caption = withAlbumCounter(caption, i+1, len(groups))
+		}
+
+		if sendErr := a.sendAlbum(group, caption, parseMode, captionEntities); sendErr != nil {
+			for _, unsent := range groups[i+1:] {
+				sendErr = errors.Join(sendErr, unsent.Close())
+			}
+			if i > 0 {
+				return fmt.Errorf("albums 1-%d of %d sent, but album %d failed: %w", i, len(groups), i+1, sendErr)
+			}
+
+			return sendErr
+		}
+	}
+
+	return nil
+}
+
+// uploadAlbum sends an album, referencing content sent before by its cached
+// file_id. If Telegram rejects a cached file_id, the stale entries are dropped
+// and the album is uploaded in full once more.
+func (a *action) uploadAlbum(opts *sendmediagroup.Options) error {
+	if a.fileIDs == nil {
+		_, err := a.client.SendMediaGroup.Send(a.ctx, opts)
+
+		return err
+	}
+
+	keys, err := a.fileIDs.lookup(opts.Attachments)
+	if err != nil {
+		return err
+	}
+
+	messages, err := a.client.SendMediaGroup.Send(a.ctx, opts)
+	if isWrongFileID(err) {
+		forgotten, forgetErr := a.fileIDs.forget(opts.Attachments, keys)
+		switch {
+		case forgetErr != nil:
+			return errors.Join(err, forgetErr)
+		case !forgotten:
+			return err
+		case !rewind(opts.Attachments):
+			return fmt.Errorf("%w; the outdated cached file IDs were removed, so the next run uploads the files", err)
+		}
+		messages, err = a.client.SendMediaGroup.Send(a.ctx, opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	// The album is already sent, so a cache that cannot be written is only
+	// worth a warning.
+	if err = a.fileIDs.save(opts.Attachments, keys, messages); err != nil && a.warningWriter != nil {
+		_, _ = fmt.Fprintf(a.warningWriter, "warning: attachments sent, but caching their file IDs failed: %v\n", err)
+	}
+
+	return nil
+}
+
+// withAlbumCounter appends "Part n/total" after the caption, as plain text
+// that needs no escaping in either parse mode and leaves entity offsets valid.
+func withAlbumCounter(caption string, n, total int) string {
+	counter := albumCounterLine(n, total)
+	if caption == "" {
+		return counter
+	}
+
+	return caption + albumCounterSeparator + counter
+}
+
+// albumCounterLine is ASCII, so its length in bytes is its length in UTF-16
+// code units.
+func albumCounterLine(n, total int) string {
+	return fmt.Sprintf("Part %d/%d", n, total)
+}
+
+func (a *action) sendAlbum(
+	attachments attachment.Attachments,
+	caption, parseMode string,
+	captionEntities []entity.MessageEntity,
+) error {
 	// The loader transfers ownership of open files to this action. Keep them
 	// open through the synchronous upload, then close each file exactly once.
 	// The HTTP adapter hides io.Closer from Resty so Resty cannot close them.
-	sendErr := a.client.SendMediaGroup.Send(a.ctx, &sendmediagroup.Options{
+	sendErr := a.uploadAlbum(&sendmediagroup.Options{
 		ChatID:              a.chatID,
 		MessageThreadID:     a.threadID,
-		Caption:             message,
-		ParseMode:           a.MessageFormat,
+		Caption:             caption,
+		ParseMode:           parseMode,
+		CaptionEntities:     captionEntities,
 		HasSpoiler:          a.spoiler,
 		DisableNotification: a.silent,
 		ProtectContent:      a.protect,
@@ -53,7 +53,7 @@ func TestActionSendMediaGroup_ClosesAttachmentOnce(t *testing.T) {
 		assert.NoError(t, err)
 
 		w.Header().Set("Content-Type", "application/json")
-		_, err = w.Write([]byte(`{"ok": true}`))
+		_, err = w.Write([]byte(`{"ok": true, "result": []}`))
 		assert.NoError(t, err)
 	}))
 	t.Cleanup(server.Close)
@@ -95,7 +95,11 @@ func TestActionExecute_ContinuesAfterPostSendCloseError(t *testing.T) {
 		assert.NoError(t, err)
 
 		w.Header().Set("Content-Type", "application/json")
-		_, err = w.Write([]byte(`{"ok": true}`))
+		result := `{}`
+		if strings.HasSuffix(r.URL.Path, "/sendMediaGroup") {
+			result = `[]`
+		}
+		_, err = w.Write([]byte(`{"ok": true, "result": ` + result + `}`))
 		assert.NoError(t, err)
 	}))
 	t.Cleanup(server.Close)
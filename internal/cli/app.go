@@ -2,7 +2,9 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,21 +12,28 @@ import (
 
 	"github.com/beeyev/telegram-owl/internal/telegram"
 	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/textsplit"
 	"github.com/beeyev/telegram-owl/internal/version"
 )
 
 const (
 	author                      = "Alexander Tebiev - https://github.com/beeyev"
-	maxTotalAttachments         = 10
+	maxAlbumAttachments         = 10
 	maxPhotoAttachmentSizeBytes = 10 * attachment.BytesPerMegabyte
 	maxAttachmentSizeBytes      = 50 * attachment.BytesPerMegabyte
-	maxTotalSizeBytes           = 50 * attachment.BytesPerMegabyte
+	maxAlbumSizeBytes           = 50 * attachment.BytesPerMegabyte
+	// Telegram shows photos at up to 2560 pixels, so larger ones gain nothing.
+	defaultMaxPhotoDimension = 2560
+	// The whole download is not limited, since a 50 MB file can take a while,
+	// only how long a server may take to start answering.
+	downloadHeaderTimeout = 30 * time.Second
 )
 
 const usageText = `Examples:
   telegram-owl --token=$TOKEN --chat=@mychannel --message "Hello"
   echo "Hi there" | telegram-owl -t $TOKEN -c 123456789 --stdin
-  telegram-owl -t $TOKEN -c @group --attach file.jpg --spoiler`
+  telegram-owl -t $TOKEN -c @group --attach file.jpg --spoiler
+  make build 2>&1 | telegram-owl -t $TOKEN -c @ci --stream`
 
 // versionFlag keeps version rendering local to this command. Using urfave's
 // package-level VersionPrinter would mutate global state shared by every app in
@@ -35,6 +44,7 @@ func versionFlag() *cli.BoolFlag {
 		Usage:       "print the version",
 		Aliases:     []string{"v"},
 		OnlyOnce:    true,
+		Local:       true,
 		HideDefault: true,
 	}
 }
@@ -69,25 +79,186 @@ func flags() []cli.Flag {
 			Usage:    "Text message content. Use --stdin to read from standard input.",
 			Aliases:  []string{"m"},
 			OnlyOnce: true,
+			Local:    true,
 		},
 		&cli.StringFlag{
 			Name:     "format",
-			Usage:    "Message format options: markdown, html, rich-markdown, rich-html",
+			Usage:    "Message format options: markdown, html, rich-markdown, rich-html, gfm",
 			Aliases:  []string{"f"},
 			OnlyOnce: true,
+			Local:    true,
 			Config:   cli.StringConfig{TrimSpace: true},
 		},
+		&cli.StringFlag{
+			Name:     "gfm-target",
+			Usage:    "Format that --format gfm converts GitHub Markdown to: html, rich-html",
+			Value:    gfmTargetHTML,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.BoolFlag{
+			Name:        "escape",
+			Usage:       "Escape the whole message for --format, so it is shown exactly as written.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.StringFlag{
+			Name:      "template",
+			Usage:     "File with a Go template that is rendered into the message. See --var and --data.",
+			OnlyOnce:  true,
+			Local:     true,
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:     "template-string",
+			Usage:    "Go template that is rendered into the message, like --template but inline.",
+			OnlyOnce: true,
+			Local:    true,
+		},
+		varFlag(),
+		&cli.StringFlag{
+			Name: "data",
+			Usage: "JSON file that templates read as .data, or - to read it from stdin. " +
+				"Environment variables are available as .env.NAME.",
+			OnlyOnce:  true,
+			Local:     true,
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:      "entities",
+			Usage:     "JSON file with MessageEntity objects that format the message instead of --format.",
+			OnlyOnce:  true,
+			Local:     true,
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:      "caption-entities",
+			Usage:     "JSON file with MessageEntity objects for the attachment caption. Defaults to --entities.",
+			OnlyOnce:  true,
+			Local:     true,
+			TakesFile: true,
+		},
 		&cli.StringSliceFlag{
 			Name:      "attach",
-			Usage:     "File paths of attachments. Can be specified multiple times or comma-separated.",
+			Usage:     "Files, directories, URLs, globs like '*.png' or - for stdin. Repeatable or comma-separated.",
 			Aliases:   []string{"a"},
+			Local:     true,
 			TakesFile: true,
 		},
+		&cli.BoolFlag{
+			Name:        "recursive",
+			Usage:       "Include files in subdirectories of directories given to --attach.",
+			Aliases:     []string{"r"},
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "Only attach expanded files matching these patterns, like '*.png'. Can be repeated.",
+			Local: true,
+		},
+		&cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "Skip expanded files matching these patterns, like '*.log'. Can be repeated.",
+			Local: true,
+		},
+		&cli.StringFlag{
+			Name:     "sort",
+			Usage:    "Order of the files a pattern or directory expands to: name, mtime, size",
+			Value:    attachment.SortByName,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.StringFlag{
+			Name:     "detect",
+			Usage:    "How to tell photos, videos and audio from documents: extension, content, both",
+			Value:    attachment.DetectExtension,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.BoolFlag{
+			Name:        "optimize-photos",
+			Usage:       "Downscale and recompress photos Telegram would refuse, instead of sending them as documents.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.IntFlag{
+			Name:     "max-photo-dimension",
+			Usage:    "Longest side in pixels of photos resized by --optimize-photos.",
+			Value:    defaultMaxPhotoDimension,
+			OnlyOnce: true,
+			Local:    true,
+		},
+		&cli.BoolFlag{
+			Name:        "split-large-files",
+			Usage:       "Send files over 50 MB as numbered parts, followed by a SHA-256 manifest for \"join\".",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.StringFlag{
+			Name:     "attach-name",
+			Usage:    "File name for the data read from stdin with --attach -, e.g. dump.sql.gz",
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.BoolFlag{
+			Name:        "no-cache",
+			Usage:       "Upload every file instead of reusing the file IDs of identical files sent before.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.StringFlag{
+			Name:     "url-mode",
+			Usage:    "How --attach URLs are sent: download (upload the file), telegram (Telegram fetches the URL)",
+			Value:    attachment.URLModeDownload,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.StringFlag{
+			Name:     "archive",
+			Usage:    "Send the --attach files as one archive, built in memory up to 50 MB: zip, tar.gz",
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.StringFlag{
+			Name:     "archive-name",
+			Usage:    "File name of the --archive archive. Defaults to the directory name or \"attachments\".",
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
 		&cli.BoolFlag{
 			Name:        "as-document",
 			Usage:       "Send all attachments as documents (bypass media type detection).",
 			Aliases:     []string{"d"},
 			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.BoolFlag{
+			Name: "group-by-type",
+			Usage: "Send photos and videos, documents and audio as separate albums when they are mixed. " +
+				"With --group-by-type=false, mixed files are all sent as documents in one album.",
+			Value:    true,
+			OnlyOnce: true,
+			Local:    true,
+		},
+		&cli.BoolFlag{
+			Name:        "album-counter",
+			Usage:       "Add \"Part 1/3\" to every album when more than 10 attachments are sent as several albums.",
+			OnlyOnce:    true,
+			Local:       true,
 			HideDefault: true,
 		},
 		&cli.BoolFlag{
@@ -101,6 +272,7 @@ func flags() []cli.Flag {
 			Name:        "spoiler",
 			Usage:       "Cover media attachments with a spoiler animation.",
 			OnlyOnce:    true,
+			Local:       true,
 			HideDefault: true,
 		},
 		&cli.BoolFlag{
@@ -113,6 +285,7 @@ func flags() []cli.Flag {
 			Name:        "no-link-preview",
 			Usage:       "Disable automatic link previews for messages.",
 			OnlyOnce:    true,
+			Local:       true,
 			HideDefault: true,
 		},
 		&cli.StringFlag{
@@ -122,12 +295,100 @@ func flags() []cli.Flag {
 			Sources:  cli.EnvVars("TELEGRAM_OWL_THREAD"),
 			Config:   cli.StringConfig{TrimSpace: true},
 		},
+		topicFlag(),
+		&cli.BoolFlag{
+			Name:        "create-topic",
+			Usage:       "Create the --topic forum topic when it does not exist yet.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.StringFlag{
+			Name:     "topic-icon-color",
+			Usage:    "Icon color for a topic created by --create-topic: blue, yellow, violet, green, rose, red.",
+			OnlyOnce: true,
+			Local:    true,
+		},
+		&cli.StringFlag{
+			Name:     "topic-icon-emoji",
+			Usage:    "Custom emoji ID for the icon of a topic created by --create-topic.",
+			OnlyOnce: true,
+			Local:    true,
+		},
+		cacheDirFlag(),
+		&cli.StringFlag{
+			Name:     "overflow",
+			Usage:    "What to do with text that is too long for one message: error, split, document, truncate.",
+			Value:    overflowError,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.StringFlag{
+			Name:     "overflow-filename",
+			Usage:    "File name of the text sent by --overflow=document.",
+			Value:    defaultOverflowFileName,
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.StringFlag{
+			Name:     "overflow-keep",
+			Usage:    "Part of the text kept by --overflow=truncate: head, tail, both.",
+			Value:    string(textsplit.KeepBoth),
+			OnlyOnce: true,
+			Local:    true,
+			Config:   cli.StringConfig{TrimSpace: true},
+		},
+		&cli.BoolFlag{
+			Name:        "split",
+			Usage:       "Split a message that is too long into several messages. Same as --overflow=split.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.BoolFlag{
+			Name:        "split-counter",
+			Usage:       "Append a (1/3) counter to every part of a message split by --split.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.BoolFlag{
+			Name:        "split-reply",
+			Usage:       "Send every further part of a message split by --split as a reply to the first part.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
 		&cli.BoolFlag{
 			Name:        "stdin",
 			Usage:       "Read message content from stdin. Example: echo 'Hello, world!' | telegram-owl --stdin",
 			OnlyOnce:    true,
+			Local:       true,
 			HideDefault: true,
 		},
+		&cli.BoolFlag{
+			Name:        "stream",
+			Usage:       "Stream stdin line by line into a message that is edited as new lines arrive.",
+			OnlyOnce:    true,
+			Local:       true,
+			HideDefault: true,
+		},
+		&cli.IntFlag{
+			Name:     "stream-lines",
+			Usage:    "Number of latest lines shown by --stream. 0 keeps every line.",
+			Value:    defaultStreamLines,
+			OnlyOnce: true,
+			Local:    true,
+		},
+		&cli.DurationFlag{
+			Name:     "stream-interval",
+			Usage:    "Minimum time between edits made by --stream.",
+			Value:    defaultStreamInterval,
+			OnlyOnce: true,
+			Local:    true,
+		},
 		&cli.BoolFlag{
 			Name:        "verbose",
 			Usage:       "Print success messages.",
@@ -150,6 +411,20 @@ func NewApp(apiBotURL string) *cli.Command {
 		HideHelpCommand: true,
 		UsageText:       usageText,
 		Flags:           flags(),
+		// Errors, including exit codes chosen by commands, are returned to
+		// main instead of urfave calling os.Exit. See ExitCode.
+		ExitErrHandler: func(context.Context, *cli.Command, error) {},
+		Commands: []*cli.Command{
+			reactCommand(apiBotURL),
+			chatsCommand(apiBotURL),
+			doctorCommand(apiBotURL),
+			topicsCommand(apiBotURL),
+			askCommand(apiBotURL),
+			execCommand(apiBotURL),
+			lintCommand(),
+			joinCommand(),
+			cacheCommand(),
+		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			// This is an application-owned version flag, not urfave's global
 			// version handler. Handle it before validating Telegram inputs.
@@ -170,19 +445,75 @@ func NewApp(apiBotURL string) *cli.Command {
 			if err != nil {
 				return err
 			}
+			if message, err = renderMessage(cmd, message); err != nil {
+				return err
+			}
+			if message, err = convertMessage(cmd, message); err != nil {
+				return err
+			}
+			messageEntities, captionEntities, err := loadEntities(cmd, message)
+			if err != nil {
+				return err
+			}
+
+			telegramClient, err := newTelegramClient(apiBotURL, cmd)
+			if err != nil {
+				return err
+			}
+
+			threadID := cmd.String("thread")
+			var topics *topicResolver
+			var topicID int
+			var topicFromCache bool
+			if topicName := cmd.String("topic"); topicName != "" {
+				if topics, err = newTopicResolver(cmd, telegramClient); err != nil {
+					return err
+				}
+
+				topicID, topicFromCache, err = topics.resolveOrCreate(
+					ctx,
+					topicName,
+					cmd.Bool("create-topic"),
+					cmd.String("topic-icon-color"),
+					cmd.String("topic-icon-emoji"),
+				)
+				if err != nil {
+					return err
+				}
+				threadID = strconv.Itoa(topicID)
+			}
+
+			// forgetStaleTopic drops a cached topic that was deleted, so the
+			// next run looks the name up again.
+			forgetStaleTopic := func(err error) error {
+				if topicFromCache && isThreadNotFound(err) {
+					if forgetErr := topics.store.Forget(topics.key, topicID); forgetErr != nil {
+						return errors.Join(err, forgetErr)
+					}
+				}
+
+				return err
+			}
 
-			telegramClient, err := telegram.NewClient(apiBotURL, cmd.String("token"), cmd.String("proxy"))
+			if cmd.Bool("stream") {
+				return runStream(ctx, cmd, telegramClient, threadID, forgetStaleTopic)
+			}
+
+			attachments, err := resolveAttachments(ctx, cmd)
 			if err != nil {
-				return fmt.Errorf("create telegram client: %w", err)
+				return err
 			}
 
 			attachLoader := &attachment.Loader{
-				FileOpener:                  &attachment.OSFileOpener{},
+				FileOpener:                  attachments.opener,
 				IsEverythingDocument:        cmd.Bool("as-document"),
-				MaxTotalAttachments:         maxTotalAttachments,
+				GroupByType:                 cmd.Bool("group-by-type"),
+				Detect:                      cmd.String("detect"),
+				PhotoOptimizer:              photoOptimizer(cmd),
+				MaxTotalAttachments:         maxAlbumAttachments,
 				MaxPhotoAttachmentSizeBytes: maxPhotoAttachmentSizeBytes,
 				MaxAttachmentSizeBytes:      maxAttachmentSizeBytes,
-				MaxTotalSizeBytes:           maxTotalSizeBytes,
+				MaxTotalSizeBytes:           maxAlbumSizeBytes,
 			}
 
 			a := &action{
@@ -192,13 +523,29 @@ func NewApp(apiBotURL string) *cli.Command {
 				warningWriter:    cmd.ErrWriter,
 				chatID:           cmd.String("chat"),
 				message:          message,
-				MessageFormat:    cmd.String("format"),
-				attachmentsPaths: cmd.StringSlice("attach"),
+				MessageFormat:    messageFormat(cmd),
+				entities:         messageEntities,
+				captionEntities:  captionEntities,
+				attachmentsPaths: attachments.paths,
 				silent:           cmd.Bool("silent"),
 				noLinkPreview:    cmd.Bool("no-link-preview"),
 				spoiler:          cmd.Bool("spoiler"),
 				protect:          cmd.Bool("protect"),
-				threadID:         cmd.String("thread"),
+				threadID:         threadID,
+				overflow:         overflowMode(cmd),
+				overflowFileName: cmd.String("overflow-filename"),
+				overflowKeep:     textsplit.Keep(cmd.String("overflow-keep")),
+				splitCounter:     cmd.Bool("split-counter"),
+				splitReply:       cmd.Bool("split-reply"),
+				albumCounter:     cmd.Bool("album-counter"),
+			}
+			if attachments.split != nil {
+				a.splitFiles = attachments.split.Files
+			}
+			if len(attachments.paths) > 0 && !cmd.Bool("no-cache") {
+				if a.fileIDs, err = newFileIDCache(cmd); err != nil {
+					return err
+				}
 			}
 
 			verbose := cmd.Bool("verbose")
@@ -208,10 +555,16 @@ func NewApp(apiBotURL string) *cli.Command {
 			}
 
 			if err = a.execute(); err != nil {
-				return fmt.Errorf("failed to send message to chat ID %s: %w", a.chatID, err)
+				return fmt.Errorf("failed to send message to chat ID %s: %w", a.chatID, forgetStaleTopic(err))
 			}
 
 			if verbose {
+				if attachments.archive != nil {
+					_, _ = fmt.Fprintln(cmd.Writer, archiveSummary(attachments.archive))
+				}
+				if a.fileIDs != nil && a.fileIDs.reused > 0 {
+					_, _ = fmt.Fprintf(cmd.Writer, "Reused %d cached file(s) instead of uploading\n", a.fileIDs.reused)
+				}
 				_, _ = fmt.Fprintf(
 					cmd.Writer,
 					"Message sent successfully. Chat ID: %s. Duration: %s\n",
@@ -225,6 +578,17 @@ func NewApp(apiBotURL string) *cli.Command {
 	}
 }
 
+// newTelegramClient builds the client from the persistent --token and --proxy
+// flags, so every command reaches Telegram through the same transport.
+func newTelegramClient(apiBotURL string, cmd *cli.Command) (*telegram.Client, error) {
+	telegramClient, err := telegram.NewClient(apiBotURL, cmd.String("token"), cmd.String("proxy"))
+	if err != nil {
+		return nil, fmt.Errorf("create telegram client: %w", err)
+	}
+
+	return telegramClient, nil
+}
+
 // printVersion preserves the release convention of a single leading "v" even
 // when the linker injects an already-prefixed version.
 func printVersion(cmd *cli.Command) error {
@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/answercallbackquery"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editmessagetext"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getupdates"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
+)
+
+const askUsageText = `Examples:
+  telegram-owl ask -t $TOKEN -c @ops --allow-user 12345 -m "Deploy v1.4.2 to production?" \
+    --option Approve --option Reject --timeout 30m`
+
+const (
+	// askExitTimeout matches timeout(1), so pipelines can tell "nobody
+	// answered" apart from the options.
+	askExitTimeout = 124
+	// askExitFailure covers every other error, so a failed request never looks
+	// like one of the options.
+	askExitFailure = 125
+	// askExitOptionBase is added to the index of every option but the first,
+	// so a chosen option never exits 1 like a usage error.
+	askExitOptionBase = 10
+	// maxAskOptions keeps option exit codes well below askExitTimeout.
+	maxAskOptions = 10
+	// askPollInterval spaces getUpdates calls while updates ask leaves for
+	// other commands are pending, since Telegram returns those at once.
+	askPollInterval = 2 * time.Second
+	// askButtonsPerRow fits typical short labels such as Approve and Reject
+	// side by side on a phone screen.
+	askButtonsPerRow = 3
+	// askCallbackPrefix marks buttons created by ask. The rest of the callback
+	// data is a per-question nonce and the option index.
+	askCallbackPrefix = "owl"
+)
+
+// askCommand posts a question with one button per option and blocks until an
+// allowed user presses one. The first option exits 0 and can gate the next
+// pipeline step directly. Option N exits 10+N.
+func askCommand(apiBotURL string) *cli.Command {
+	return &cli.Command{
+		Name:      "ask",
+		Usage:     "Ask a question with buttons and wait for an allowed user to answer.",
+		UsageText: askUsageText,
+		Description: "Exits 0 when the first option is chosen and 10+N for option N " +
+			"(11 for the second option), 124 when --timeout expires, and 125 on other errors. " +
+			"The chosen option is printed to stdout.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "message",
+				Usage:    "Question text (required).",
+				Aliases:  []string{"m"},
+				OnlyOnce: true,
+			},
+			&cli.StringSliceFlag{
+				Name:  "option",
+				Usage: fmt.Sprintf("Button label, 1 to %d options. Can be repeated or comma-separated.", maxAskOptions),
+			},
+			&cli.Int64SliceFlag{
+				Name:    "allow-user",
+				Usage:   "Telegram user ID allowed to answer (required), can be repeated. environment variable:",
+				Sources: cli.EnvVars("TELEGRAM_OWL_ALLOW_USERS"),
+			},
+			&cli.DurationFlag{
+				Name:     "timeout",
+				Usage:    "Give up after this duration, for example 30m. Zero waits until interrupted.",
+				OnlyOnce: true,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			if err := runAsk(ctx, apiBotURL, cmd); err != nil {
+				if _, ok := errors.AsType[*exitError](err); ok {
+					return err
+				}
+
+				return &exitError{code: askExitFailure, err: err}
+			}
+
+			return nil
+		},
+	}
+}
+
+type askQuestion struct {
+	client       *telegram.Client
+	chatID       string
+	messageID    int
+	text         string
+	options      []string
+	allowedUsers []int64
+	nonce        string
+}
+
+func runAsk(ctx context.Context, apiBotURL string, cmd *cli.Command) error {
+	iv := &inputValues{cmd: cmd}
+	if err := iv.validateDestination(); err != nil {
+		return err
+	}
+	if err := iv.validateAsk(); err != nil {
+		return err
+	}
+
+	telegramClient, err := newTelegramClient(apiBotURL, cmd)
+	if err != nil {
+		return err
+	}
+
+	nonce, err := newAskNonce()
+	if err != nil {
+		return err
+	}
+
+	q := &askQuestion{
+		client:       telegramClient,
+		chatID:       cmd.String("chat"),
+		text:         cmd.String("message"),
+		options:      cmd.StringSlice("option"),
+		allowedUsers: cmd.Int64Slice("allow-user"),
+		nonce:        nonce,
+	}
+
+	sent, err := telegramClient.SendMessage.Send(ctx, &sendmessage.Options{
+		ChatID:              q.chatID,
+		MessageThreadID:     cmd.String("thread"),
+		Text:                q.text,
+		DisableNotification: cmd.Bool("silent"),
+		ProtectContent:      cmd.Bool("protect"),
+		ReplyMarkup:         q.keyboard(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send question to chat ID %s: %w", q.chatID, err)
+	}
+	if sent.MessageID == 0 {
+		return errors.New("telegram did not return the ID of the question message")
+	}
+	q.messageID = sent.MessageID
+
+	waitCtx := ctx
+	timeout := cmd.Duration("timeout")
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	choice, from, err := q.waitForAnswer(waitCtx)
+	if err != nil {
+		// Record the outcome even though the wait was cancelled, so the
+		// buttons do not stay live in the chat.
+		reportCtx := context.WithoutCancel(ctx)
+		if errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			q.closeQuestion(reportCtx, cmd, fmt.Sprintf("No answer within %s.", timeout))
+
+			return &exitError{code: askExitTimeout, err: fmt.Errorf("no answer within %s", timeout)}
+		}
+		if ctx.Err() != nil {
+			q.closeQuestion(reportCtx, cmd, "Question cancelled.")
+		}
+
+		return err
+	}
+
+	q.closeQuestion(ctx, cmd, fmt.Sprintf(
+		"%s by %s at %s",
+		q.options[choice],
+		userLabel(from),
+		time.Now().UTC().Format("2006-01-02 15:04 MST"),
+	))
+
+	if _, err = fmt.Fprintln(cmd.Writer, q.options[choice]); err != nil {
+		return err
+	}
+	if choice > 0 {
+		return &exitError{
+			code: askExitOptionBase + choice,
+			err:  fmt.Errorf("%q was chosen by %s", q.options[choice], userLabel(from)),
+		}
+	}
+
+	return nil
+}
+
+func (iv *inputValues) validateAsk() error {
+	if iv.cmd.String("message") == "" {
+		return errors.New("missing required flag: --message")
+	}
+
+	options := iv.cmd.StringSlice("option")
+	if len(options) == 0 || len(options) > maxAskOptions {
+		return fmt.Errorf("between 1 and %d --option values are required, got %d", maxAskOptions, len(options))
+	}
+	for _, option := range options {
+		if strings.TrimSpace(option) == "" {
+			return errors.New("--option values must not be empty")
+		}
+	}
+
+	if len(iv.cmd.Int64Slice("allow-user")) == 0 {
+		return errors.New(
+			"missing required flag: --allow-user, set it to the Telegram user IDs allowed to answer " +
+				"or use the TELEGRAM_OWL_ALLOW_USERS environment variable",
+		)
+	}
+
+	return nil
+}
+
+func newAskNonce() (string, error) {
+	nonce := make([]byte, 8)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate question ID: %w", err)
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
+
+func (q *askQuestion) keyboard() *sendmessage.InlineKeyboardMarkup {
+	var rows [][]sendmessage.InlineKeyboardButton
+	for i, option := range q.options {
+		if i%askButtonsPerRow == 0 {
+			rows = append(rows, nil)
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], sendmessage.InlineKeyboardButton{
+			Text:         option,
+			CallbackData: askCallbackPrefix + ":" + q.nonce + ":" + strconv.Itoa(i),
+		})
+	}
+
+	return &sendmessage.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+// waitForAnswer long-polls getUpdates for presses on ask's buttons. Presses on
+// other questions and by users outside the allowlist are answered and ignored.
+//
+// getUpdates confirms every update below its offset, so the offset only moves
+// past the leading presses on ask's buttons. Messages and other updates stay
+// pending for chats and topics. allowed_updates is not used to skip them,
+// because Telegram keeps that setting for later getUpdates calls. A full page
+// that does not let the offset move means the answer can never be read, so
+// that is an error rather than a wait until the timeout.
+func (q *askQuestion) waitForAnswer(ctx context.Context) (int, getupdates.User, error) {
+	offset := 0
+	handled := make(map[int]bool)
+	for {
+		updates, err := q.client.GetUpdates.Get(ctx, &getupdates.Options{
+			Offset:         offset,
+			TimeoutSeconds: getupdates.MaxTimeoutSeconds,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, getupdates.User{}, fmt.Errorf("wait for answer: %w", ctx.Err())
+			}
+
+			return 0, getupdates.User{}, fmt.Errorf("wait for answer: %w", err)
+		}
+
+		fresh := false
+		for _, update := range updates {
+			if handled[update.UpdateID] {
+				continue
+			}
+			handled[update.UpdateID] = true
+			fresh = true
+
+			if update.CallbackQuery == nil {
+				continue
+			}
+			choice, ok := q.handleCallback(ctx, update.CallbackQuery)
+			if ok {
+				if confirmed := askOffset(updates, offset); confirmed > offset {
+					q.confirmUpdates(ctx, confirmed)
+				}
+
+				return choice, update.CallbackQuery.From, nil
+			}
+		}
+		next := askOffset(updates, offset)
+		if next == offset && len(updates) >= getupdates.MaxLimit {
+			return 0, getupdates.User{}, fmt.Errorf(
+				"wait for answer: %d or more pending updates for other commands come before the answer; "+
+					"clear them with \"chats --ack\" and ask again",
+				getupdates.MaxLimit,
+			)
+		}
+		offset = next
+
+		// Only updates left for other commands are pending, and Telegram
+		// returns them without waiting.
+		if !fresh && len(updates) > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, getupdates.User{}, fmt.Errorf("wait for answer: %w", ctx.Err())
+			case <-time.After(askPollInterval):
+			}
+		}
+	}
+}
+
+// askOffset returns the offset that confirms the leading presses on ask's
+// buttons in updates and nothing after them.
+func askOffset(updates []getupdates.Update, offset int) int {
+	for _, update := range updates {
+		if update.CallbackQuery == nil || !strings.HasPrefix(update.CallbackQuery.Data, askCallbackPrefix+":") {
+			break
+		}
+		offset = update.UpdateID + 1
+	}
+
+	return offset
+}
+
+// handleCallback answers one button press and reports whether it decides
+// the question. Answer failures are not fatal: the press itself was received.
+func (q *askQuestion) handleCallback(ctx context.Context, query *getupdates.CallbackQuery) (int, bool) {
+	parts := strings.Split(query.Data, ":")
+	if len(parts) != 3 || parts[0] != askCallbackPrefix {
+		// Another program's button. Leave it for that program to answer.
+		return 0, false
+	}
+
+	answer := func(text string, alert bool) {
+		_ = q.client.AnswerCallback.Answer(ctx, &answercallbackquery.Options{
+			CallbackQueryID: query.ID,
+			Text:            text,
+			ShowAlert:       alert,
+		})
+	}
+
+	choice, err := strconv.Atoi(parts[2])
+	if parts[1] != q.nonce || err != nil || choice < 0 || choice >= len(q.options) {
+		answer("This question is no longer active.", false)
+
+		return 0, false
+	}
+
+	if !slices.Contains(q.allowedUsers, query.From.ID) {
+		answer("You are not allowed to answer this question.", true)
+
+		return 0, false
+	}
+
+	answer("You chose: "+q.options[choice], false)
+
+	return choice, true
+}
+
+// confirmUpdates acknowledges the presses up to the deciding one so the next
+// run does not read them again. If that fails, or updates for other commands
+// come first, the next run sees a press on an inactive question and only
+// answers it, so the error is ignored.
+func (q *askQuestion) confirmUpdates(ctx context.Context, offset int) {
+	_, _ = q.client.GetUpdates.Get(ctx, &getupdates.Options{Offset: offset, Limit: 1})
+}
+
+// closeQuestion appends the outcome to the question and removes the buttons.
+// A failure only leaves stale buttons, so it is reported as a warning.
+func (q *askQuestion) closeQuestion(ctx context.Context, cmd *cli.Command, outcome string) {
+	err := q.client.EditMessageText.Edit(ctx, &editmessagetext.Options{
+		ChatID:    q.chatID,
+		MessageID: q.messageID,
+		Text:      q.text + "\n\n" + outcome,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrWriter, "warning: failed to record the outcome in the question: %v\n", err)
+	}
+}
+
+func userLabel(user getupdates.User) string {
+	name := strings.TrimSpace(user.FirstName + " " + user.LastName)
+	if user.Username != "" {
+		name += " (@" + user.Username + ")"
+	}
+
+	return fmt.Sprintf("%s [ID %d]", name, user.ID)
+}
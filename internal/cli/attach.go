@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+// attachOnlyFlags only change how --attach files are found or sent.
+var attachOnlyFlags = []string{
+	"recursive", "include", "exclude", "sort", "detect", "optimize-photos", "max-photo-dimension",
+	"split-large-files", "no-cache", "url-mode", "album-counter", "archive", "archive-name",
+}
+
+func (iv *inputValues) validateAttach() error {
+	sortBy := iv.cmd.String("sort")
+	if sortBy != attachment.SortByName && sortBy != attachment.SortByMtime && sortBy != attachment.SortBySize {
+		return errors.New("incorrect value for --sort flag, possible values: name, mtime, size")
+	}
+
+	detect := iv.cmd.String("detect")
+	if detect != attachment.DetectExtension && detect != attachment.DetectContent && detect != attachment.DetectBoth {
+		return errors.New("incorrect value for --detect flag, possible values: extension, content, both")
+	}
+
+	if iv.cmd.IsSet("max-photo-dimension") && !iv.cmd.Bool("optimize-photos") {
+		return errors.New("--max-photo-dimension requires --optimize-photos")
+	}
+	if iv.cmd.Int("max-photo-dimension") <= 0 {
+		return errors.New("--max-photo-dimension must be positive")
+	}
+
+	archive := iv.cmd.String("archive")
+	if archive != "" && archive != attachment.ArchiveZip && archive != attachment.ArchiveTarGz {
+		return errors.New("incorrect value for --archive flag, possible values: zip, tar.gz")
+	}
+	if iv.cmd.IsSet("archive-name") && archive == "" {
+		return errors.New("--archive-name requires --archive")
+	}
+	if archive != "" && iv.cmd.Bool("split-large-files") {
+		return errors.New("--split-large-files cannot be combined with --archive")
+	}
+	if archive != "" && slices.ContainsFunc(iv.cmd.StringSlice("attach"), attachment.IsURL) {
+		return errors.New("--archive cannot be combined with URL attachments")
+	}
+
+	urlMode := iv.cmd.String("url-mode")
+	if urlMode != attachment.URLModeDownload && urlMode != attachment.URLModeTelegram {
+		return errors.New("incorrect value for --url-mode flag, possible values: download, telegram")
+	}
+
+	if err := iv.validateStdinAttachment(); err != nil {
+		return err
+	}
+
+	if len(iv.cmd.StringSlice("attach")) > 0 {
+		return nil
+	}
+	for _, flag := range attachOnlyFlags {
+		if iv.cmd.IsSet(flag) {
+			return fmt.Errorf("--%s requires --attach", flag)
+		}
+	}
+
+	return nil
+}
+
+// validateStdinAttachment checks --attach -, which needs --attach-name because
+// a pipe has no name, and cannot share standard input with other flags.
+func (iv *inputValues) validateStdinAttachment() error {
+	stdinCount := 0
+	for _, path := range iv.cmd.StringSlice("attach") {
+		if path == attachment.StdinPath {
+			stdinCount++
+		}
+	}
+	fromStdin := stdinCount > 0
+	name := iv.cmd.String("attach-name")
+
+	switch {
+	case stdinCount > 1:
+		return errors.New("--attach - can be given only once, standard input is read once")
+	case !fromStdin && iv.cmd.IsSet("attach-name"):
+		return errors.New("--attach-name requires --attach -")
+	case !fromStdin:
+		return nil
+	case name == "":
+		return errors.New("--attach - requires --attach-name, the file name to send standard input under")
+	case strings.ContainsAny(name, `/\`):
+		return errors.New("--attach-name must be a file name, not a path")
+	case iv.cmd.Bool("stdin"):
+		return errors.New("--attach - and --stdin cannot both read standard input")
+	case iv.cmd.String("data") == "-":
+		return errors.New("--attach - and --data - cannot both read standard input")
+	case iv.cmd.String("archive") != "":
+		return errors.New("--archive cannot be combined with --attach -")
+	}
+
+	return nil
+}
+
+// attachmentSource is what --attach resolves to: the paths the loader opens
+// and the opener it opens them with. archive and split are set when the
+// opener packs or splits the files, for reporting what was sent.
+type attachmentSource struct {
+	paths   []string
+	opener  attachment.FileOpener
+	archive *attachment.ArchiveOpener
+	split   *attachment.SplitOpener
+}
+
+// resolveAttachments expands the glob patterns and directories given to
+// --attach into files, so the loader applies its limits to what is actually
+// sent. With --archive the files are packed into one archive served under a
+// single path. With --split-large-files, files over the upload limit are
+// replaced by their parts. URLs are kept and opened according to --url-mode,
+// and "-" reads the file from standard input.
+func resolveAttachments(ctx context.Context, cmd *cli.Command) (*attachmentSource, error) {
+	args := cmd.StringSlice("attach")
+	if len(args) == 0 {
+		return &attachmentSource{opener: &attachment.OSFileOpener{}}, nil
+	}
+
+	opts := attachment.ExpandOptions{
+		Recursive: cmd.Bool("recursive"),
+		Include:   cmd.StringSlice("include"),
+		Exclude:   cmd.StringSlice("exclude"),
+		SortBy:    cmd.String("sort"),
+	}
+
+	if format := cmd.String("archive"); format != "" {
+		archive, err := attachment.NewArchiveOpener(
+			format, cmd.String("archive-name"), args, opts, maxAttachmentSizeBytes,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("resolve --attach: %w", err)
+		}
+
+		return &attachmentSource{paths: []string{archive.Name}, opener: archive, archive: archive}, nil
+	}
+
+	paths, err := attachment.Expand(args, opts)
+	if err != nil {
+		return nil, fmt.Errorf("resolve --attach: %w", err)
+	}
+
+	source := &attachmentSource{paths: paths, opener: &attachment.OSFileOpener{}}
+	if cmd.Bool("split-large-files") {
+		source.split, source.paths, err = attachment.NewSplitOpener(paths, maxAttachmentSizeBytes)
+		if err != nil {
+			return nil, fmt.Errorf("split --attach files: %w", err)
+		}
+		source.opener = source.split
+	}
+	if slices.Contains(paths, attachment.StdinPath) {
+		source.opener = &attachment.StdinFileOpener{
+			Reader:       cmd.Reader,
+			Name:         cmd.String("attach-name"),
+			BufferBytes:  maxPhotoAttachmentSizeBytes,
+			MaxSizeBytes: maxAttachmentSizeBytes,
+			Files:        source.opener,
+		}
+	}
+	if slices.ContainsFunc(paths, attachment.IsURL) {
+		var client *http.Client
+		if client, err = downloadClient(cmd.String("proxy")); err != nil {
+			return nil, err
+		}
+		source.opener = &attachment.URLFileOpener{
+			Mode:    cmd.String("url-mode"),
+			Files:   source.opener,
+			Client:  client,
+			Context: ctx,
+		}
+	}
+
+	return source, nil
+}
+
+// downloadClient returns the client --attach URLs are downloaded with, going
+// through --proxy like the requests to Telegram.
+func downloadClient(proxyURL string) (*http.Client, error) {
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		ResponseHeaderTimeout: downloadHeaderTimeout,
+	}
+	if proxyURL != "" {
+		parsed, err := url.ParseRequestURI(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(parsed)
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// photoOptimizer returns the optimizer for --optimize-photos, or nil when
+// photos are sent as they are.
+func photoOptimizer(cmd *cli.Command) *attachment.PhotoOptimizer {
+	if !cmd.Bool("optimize-photos") {
+		return nil
+	}
+
+	return &attachment.PhotoOptimizer{
+		MaxSizeBytes: maxPhotoAttachmentSizeBytes,
+		MaxDimension: cmd.Int("max-photo-dimension"),
+	}
+}
+
+// archiveSummary reports how well the files sent with --archive compressed.
+func archiveSummary(archive *attachment.ArchiveOpener) string {
+	ratio := 0.0
+	if archive.InputBytes > 0 {
+		ratio = float64(archive.ArchiveBytes) / float64(archive.InputBytes) * 100
+	}
+
+	return fmt.Sprintf(
+		"Archive %s: %d file(s), %d bytes compressed to %d bytes (%.1f%%)",
+		archive.Name, len(archive.Files), archive.InputBytes, archive.ArchiveBytes, ratio,
+	)
+}
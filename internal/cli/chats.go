@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getupdates"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const chatsUsageText = `Examples:
+  telegram-owl chats -t $TOKEN
+  telegram-owl chats -t $TOKEN --wait --output json`
+
+type discoveredTopic struct {
+	ID   int    `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type discoveredChat struct {
+	ID       int64             `json:"id"`
+	Type     string            `json:"type"`
+	Title    string            `json:"title,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Topics   []discoveredTopic `json:"topics,omitempty"`
+}
+
+// chatsCommand replaces the manual getUpdates walk-through for finding chat
+// and topic IDs. It reads pending updates without an offset, so the updates
+// stay queued for whatever else consumes them unless --ack is given.
+func chatsCommand(apiBotURL string) *cli.Command {
+	return &cli.Command{
+		Name:      "chats",
+		Usage:     "List chats and forum topics the bot has seen in pending updates.",
+		UsageText: chatsUsageText,
+		Flags: []cli.Flag{
+			outputFlag(),
+			&cli.BoolFlag{
+				Name:        "wait",
+				Usage:       "Wait until someone messages the bot when no updates are pending.",
+				OnlyOnce:    true,
+				HideDefault: true,
+			},
+			&cli.DurationFlag{
+				Name:     "timeout",
+				Usage:    "Give up waiting after this duration, for example 5m. Zero waits until interrupted.",
+				OnlyOnce: true,
+			},
+			&cli.BoolFlag{
+				Name:        "ack",
+				Usage:       "Acknowledge the listed updates so Telegram stops returning them.",
+				OnlyOnce:    true,
+				HideDefault: true,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			iv := &inputValues{cmd: cmd}
+			if err := iv.validateToken(); err != nil {
+				return err
+			}
+			output, err := iv.outputFormat()
+			if err != nil {
+				return err
+			}
+
+			telegramClient, err := newTelegramClient(apiBotURL, cmd)
+			if err != nil {
+				return err
+			}
+
+			pollCtx := ctx
+			if timeout := cmd.Duration("timeout"); timeout > 0 {
+				var cancel context.CancelFunc
+				pollCtx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			updates, err := pollUpdates(pollCtx, telegramClient.GetUpdates, cmd.Bool("wait"))
+			if err != nil {
+				if errors.Is(pollCtx.Err(), context.DeadlineExceeded) {
+					return fmt.Errorf("no updates received within %s", cmd.Duration("timeout"))
+				}
+
+				return fmt.Errorf("failed to read updates: %w", err)
+			}
+
+			if err = writeChats(cmd.Writer, output, discoverChats(updates)); err != nil {
+				return err
+			}
+
+			if cmd.Bool("ack") && len(updates) > 0 {
+				lastUpdateID := updates[len(updates)-1].UpdateID
+				// An offset above an update ID confirms it. Limit the request to
+				// one update so newer ones are only peeked at, not confirmed.
+				_, err = telegramClient.GetUpdates.Get(ctx, &getupdates.Options{Offset: lastUpdateID + 1, Limit: 1})
+				if err != nil {
+					return fmt.Errorf("failed to acknowledge updates: %w", err)
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+// outputFlag is shared by commands that print machine-readable results.
+func outputFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "output",
+		Usage:    "Output format: table or json.",
+		Aliases:  []string{"o"},
+		Value:    outputTable,
+		OnlyOnce: true,
+		Config:   cli.StringConfig{TrimSpace: true},
+	}
+}
+
+func (iv *inputValues) outputFormat() (string, error) {
+	output := iv.cmd.String("output")
+	if output != outputTable && output != outputJSON {
+		return "", errors.New("incorrect value for --output flag, possible values: table, json")
+	}
+
+	return output, nil
+}
+
+// pollUpdates never sets an offset, so nothing it reads is acknowledged. When
+// wait is set, empty long polls repeat until an update arrives or ctx ends.
+func pollUpdates(ctx context.Context, getter getupdates.Getter, wait bool) ([]getupdates.Update, error) {
+	opts := &getupdates.Options{}
+	if wait {
+		opts.TimeoutSeconds = getupdates.MaxTimeoutSeconds
+	}
+
+	for {
+		updates, err := getter.Get(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(updates) > 0 || !wait {
+			return updates, nil
+		}
+	}
+}
+
+// discoverChats deduplicates chats in the order they first appear. Topic IDs
+// come from message_thread_id; names are filled in when a service message or
+// a reply to one reveals them.
+func discoverChats(updates []getupdates.Update) []discoveredChat {
+	chats := make([]discoveredChat, 0, len(updates))
+	topicNames := make(map[int64]map[int]string)
+
+	for _, update := range updates {
+		chat := update.Chat()
+		if chat == nil {
+			continue
+		}
+
+		if _, seen := topicNames[chat.ID]; !seen {
+			chats = append(chats, discoveredChat{
+				ID:       chat.ID,
+				Type:     chat.Type,
+				Title:    chatTitle(chat),
+				Username: chat.Username,
+			})
+			topicNames[chat.ID] = make(map[int]string)
+		}
+
+		for _, message := range update.Messages() {
+			if !message.IsTopicMessage || message.MessageThreadID == 0 {
+				continue
+			}
+
+			if name := message.TopicName(); name != "" || topicNames[chat.ID][message.MessageThreadID] == "" {
+				topicNames[chat.ID][message.MessageThreadID] = name
+			}
+		}
+	}
+
+	for i := range chats {
+		for id, name := range topicNames[chats[i].ID] {
+			chats[i].Topics = append(chats[i].Topics, discoveredTopic{ID: id, Name: name})
+		}
+		slices.SortFunc(chats[i].Topics, func(a, b discoveredTopic) int {
+			return cmp.Compare(a.ID, b.ID)
+		})
+	}
+
+	return chats
+}
+
+// chatTitle names private chats after the user, since they have no title.
+func chatTitle(chat *getupdates.Chat) string {
+	if chat.Title != "" {
+		return chat.Title
+	}
+
+	return strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+}
+
+func writeChats(w io.Writer, output string, chats []discoveredChat) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(chats)
+	}
+
+	if len(chats) == 0 {
+		_, err := fmt.Fprintln(w, "No chats found. Send a message to the bot, or add it to a group or channel "+
+			"and post there, then run this command again. Use --wait to block until that happens.")
+
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "CHAT ID\tTYPE\tTITLE\tUSERNAME\tTOPICS")
+	for _, chat := range chats {
+		_, _ = fmt.Fprintf(
+			tw,
+			"%d\t%s\t%s\t%s\t%s\n",
+			chat.ID,
+			chat.Type,
+			valueOrDash(chat.Title),
+			valueOrDash(usernameLabel(chat.Username)),
+			valueOrDash(topicsLabel(chat.Topics)),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func usernameLabel(username string) string {
+	if username == "" {
+		return ""
+	}
+
+	return "@" + username
+}
+
+func topicsLabel(topics []discoveredTopic) string {
+	labels := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		label := strconv.Itoa(topic.ID)
+		if topic.Name != "" {
+			label += " (" + topic.Name + ")"
+		}
+		labels = append(labels, label)
+	}
+
+	return strings.Join(labels, ", ")
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
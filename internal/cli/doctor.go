@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchat"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchatmember"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getme"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendchataction"
+)
+
+const doctorUsageText = `Examples:
+  telegram-owl doctor -t $TOKEN -c @mychannel
+  telegram-owl doctor -t $TOKEN -c -1001234567890 --thread 11 --output json`
+
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+type doctorCheck struct {
+	ID     string `json:"id"`
+	Label  string `json:"-"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type doctorReport struct {
+	OK     bool          `json:"ok"`
+	Checks []doctorCheck `json:"checks"`
+}
+
+// chatRights is what the bot may do in the target chat, resolved from its
+// member status and, for ordinary group members, the chat's default rights.
+type chatRights struct {
+	sendMessages bool
+	missingMedia []string
+	pinMessages  bool
+	manageTopics bool
+}
+
+// doctorCommand runs the same lookups Telegram would fail on at send time and
+// reports them all at once. It exits non-zero only on failed checks; missing
+// optional rights such as pinning are reported as warnings.
+func doctorCommand(apiBotURL string) *cli.Command {
+	return &cli.Command{
+		Name:      "doctor",
+		Usage:     "Check the token, chat access, bot rights and topic before sending.",
+		UsageText: doctorUsageText,
+		Description: "With --thread, the topic is checked by sending a chat action, " +
+			"so members of the topic briefly see the bot \"typing…\".",
+		Flags: []cli.Flag{outputFlag()},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			iv := &inputValues{cmd: cmd}
+			if err := iv.validateDestination(); err != nil {
+				return err
+			}
+			output, err := iv.outputFormat()
+			if err != nil {
+				return err
+			}
+
+			telegramClient, err := newTelegramClient(apiBotURL, cmd)
+			if err != nil {
+				return err
+			}
+
+			report := runDoctor(ctx, telegramClient, cmd.String("chat"), cmd.String("thread"))
+			if err = writeDoctorReport(cmd.Writer, output, report); err != nil {
+				return err
+			}
+
+			if !report.OK {
+				failed := 0
+				for _, check := range report.Checks {
+					if check.Status == checkFail {
+						failed++
+					}
+				}
+
+				return fmt.Errorf("doctor found %d failing check(s)", failed)
+			}
+
+			return nil
+		},
+	}
+}
+
+// runDoctor stops calling Telegram once a check makes the following ones
+// meaningless, and reports those as skipped instead.
+func runDoctor(ctx context.Context, client *telegram.Client, chatID, threadID string) *doctorReport {
+	report := &doctorReport{OK: true}
+	add := func(id, label, status, detail string) {
+		report.Checks = append(report.Checks, doctorCheck{ID: id, Label: label, Status: status, Detail: detail})
+		if status == checkFail {
+			report.OK = false
+		}
+	}
+
+	bot, err := client.GetMe.Get(ctx)
+	if err != nil {
+		add("token", "Token", checkFail, err.Error())
+		add("chat", "Chat", checkSkip, "requires a valid token")
+
+		return report
+	}
+	add("token", "Token", checkPass, botLabel(bot))
+
+	chat, err := client.GetChat.Get(ctx, &getchat.Options{ChatID: chatID})
+	if err != nil {
+		add("chat", "Chat", checkFail, err.Error())
+		add("membership", "Membership", checkSkip, "requires an accessible chat")
+
+		return report
+	}
+	add("chat", "Chat", checkPass, chatLabel(chat))
+
+	var rights chatRights
+	if chat.Type == getchat.TypePrivate {
+		add("membership", "Membership", checkPass, "private chat with the bot")
+		rights = chatRights{sendMessages: true, pinMessages: true}
+	} else {
+		member, memberErr := client.GetChatMember.Get(ctx, &getchatmember.Options{ChatID: chatID, UserID: bot.ID})
+		if memberErr != nil {
+			add("membership", "Membership", checkFail, memberErr.Error())
+
+			return report
+		}
+
+		rights = resolveChatRights(chat, member)
+		if !isChatMember(member) {
+			add("membership", "Membership", checkFail, "bot is not a member ("+member.Status+")")
+		} else {
+			add("membership", "Membership", checkPass, member.Status)
+		}
+	}
+
+	if rights.sendMessages {
+		add("send_messages", "Send messages", checkPass, "")
+	} else {
+		add("send_messages", "Send messages", checkFail, "the bot is not allowed to post in this chat")
+	}
+
+	switch {
+	case !rights.sendMessages:
+		add("send_media", "Send media", checkFail, "the bot is not allowed to post in this chat")
+	case len(rights.missingMedia) > 0:
+		add("send_media", "Send media", checkWarn, "not allowed: "+strings.Join(rights.missingMedia, ", "))
+	default:
+		add("send_media", "Send media", checkPass, "")
+	}
+
+	if rights.pinMessages {
+		add("pin_messages", "Pin messages", checkPass, "")
+	} else {
+		add("pin_messages", "Pin messages", checkWarn, "the bot cannot pin messages")
+	}
+
+	if chat.IsForum {
+		if rights.manageTopics {
+			add("manage_topics", "Manage topics", checkPass, "")
+		} else {
+			add("manage_topics", "Manage topics", checkWarn, "the bot cannot create or edit topics")
+		}
+	}
+
+	switch {
+	case threadID == "" && chat.IsForum:
+		add("thread", "Thread", checkSkip, "--thread is not set; messages go to the General topic")
+	case threadID == "":
+	case !chat.IsForum:
+		add("thread", "Thread", checkFail, "--thread is set but the chat is not a forum")
+	case !rights.sendMessages:
+		add("thread", "Thread", checkSkip, "requires the right to post")
+	default:
+		// A chat action is the only side-effect-free request that Telegram
+		// rejects for an unknown topic. Users in the topic briefly see
+		// "typing...".
+		err = client.SendChatAction.Send(ctx, &sendchataction.Options{
+			ChatID:          chatID,
+			MessageThreadID: threadID,
+			Action:          sendchataction.ActionTyping,
+		})
+		if err != nil {
+			add("thread", "Thread", checkFail, err.Error())
+		} else {
+			add("thread", "Thread", checkPass, "topic "+threadID+" exists")
+		}
+	}
+
+	return report
+}
+
+// isChatMember reports whether the bot is in the chat. A restricted user may
+// have left the chat and keeps the restricted status.
+func isChatMember(member *getchatmember.ChatMember) bool {
+	switch member.Status {
+	case getchatmember.StatusLeft, getchatmember.StatusKicked:
+		return false
+	case getchatmember.StatusRestricted:
+		return member.IsMember
+	default:
+		return true
+	}
+}
+
+// resolveChatRights follows Telegram's rules for each member status. Channel
+// members can only read, and channel administrators pin with the right to edit
+// messages rather than a dedicated pin right.
+func resolveChatRights(chat *getchat.Chat, member *getchatmember.ChatMember) chatRights {
+	isChannel := chat.Type == getchat.TypeChannel
+
+	switch member.Status {
+	case getchatmember.StatusCreator:
+		return chatRights{sendMessages: true, pinMessages: true, manageTopics: true}
+	case getchatmember.StatusAdministrator:
+		if isChannel {
+			return chatRights{sendMessages: member.CanPostMessages, pinMessages: member.CanEditMessages}
+		}
+
+		return chatRights{
+			sendMessages: true,
+			pinMessages:  member.CanPinMessages,
+			manageTopics: member.CanManageTopics,
+		}
+	case getchatmember.StatusMember:
+		if isChannel || chat.Permissions == nil {
+			return chatRights{}
+		}
+		p := chat.Permissions
+
+		return chatRights{
+			sendMessages: p.CanSendMessages,
+			missingMedia: missingMediaRights(p.CanSendPhotos, p.CanSendVideos, p.CanSendAudios, p.CanSendDocuments),
+			pinMessages:  p.CanPinMessages,
+			manageTopics: p.CanManageTopics,
+		}
+	case getchatmember.StatusRestricted:
+		if !member.IsMember {
+			return chatRights{}
+		}
+
+		rights := chatRights{
+			sendMessages: member.CanSendMessages,
+			missingMedia: missingMediaRights(
+				member.CanSendPhotos, member.CanSendVideos, member.CanSendAudios, member.CanSendDocuments,
+			),
+		}
+		if chat.Permissions != nil {
+			rights.pinMessages = chat.Permissions.CanPinMessages
+			rights.manageTopics = chat.Permissions.CanManageTopics
+		}
+
+		return rights
+	default:
+		return chatRights{}
+	}
+}
+
+// missingMediaRights lists the attachment kinds the CLI sends that the bot is
+// not allowed to post.
+func missingMediaRights(photos, videos, audios, documents bool) []string {
+	var missing []string
+	for _, right := range []struct {
+		name    string
+		allowed bool
+	}{
+		{"photos", photos},
+		{"videos", videos},
+		{"audios", audios},
+		{"documents", documents},
+	} {
+		if !right.allowed {
+			missing = append(missing, right.name)
+		}
+	}
+
+	return missing
+}
+
+func botLabel(bot *getme.User) string {
+	label := bot.FirstName
+	if bot.Username != "" {
+		label = "@" + bot.Username
+	}
+
+	return fmt.Sprintf("%s (ID %d)", label, bot.ID)
+}
+
+func chatLabel(chat *getchat.Chat) string {
+	title := chat.Title
+	if title == "" {
+		title = strings.TrimSpace(chat.FirstName + " " + chat.LastName)
+	}
+
+	kind := chat.Type
+	if chat.IsForum {
+		kind += " forum"
+	}
+
+	return fmt.Sprintf("%s (%s, ID %s)", title, kind, strconv.FormatInt(chat.ID, 10))
+}
+
+func writeDoctorReport(w io.Writer, output string, report *doctorReport) error {
+	if output == outputJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(report)
+	}
+
+	labelWidth := 0
+	for _, check := range report.Checks {
+		labelWidth = max(labelWidth, len(check.Label))
+	}
+
+	for _, check := range report.Checks {
+		line := fmt.Sprintf("[%s] %-*s  %s", strings.ToUpper(check.Status), labelWidth, check.Label, check.Detail)
+		if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
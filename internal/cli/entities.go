@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+)
+
+func (iv *inputValues) validateEntities() error {
+	hasEntities := iv.cmd.IsSet("entities")
+	hasCaptionEntities := iv.cmd.IsSet("caption-entities")
+	if !hasEntities && !hasCaptionEntities {
+		return nil
+	}
+
+	flag := "--entities"
+	if !hasEntities {
+		flag = "--caption-entities"
+	}
+
+	switch mode := overflowMode(iv.cmd); {
+	case iv.cmd.String("format") != "":
+		return fmt.Errorf("%s cannot be combined with --format, entities replace the parse mode", flag)
+	case hasCaptionEntities && len(iv.cmd.StringSlice("attach")) == 0:
+		return errors.New("--caption-entities requires --attach")
+	case iv.cmd.Bool("stream"):
+		return fmt.Errorf("%s cannot be combined with --stream", flag)
+	case mode == overflowSplit || mode == overflowTruncate:
+		return fmt.Errorf(
+			"%s cannot be combined with --overflow=%s, which changes the text the offsets point into", flag, mode,
+		)
+	}
+
+	return nil
+}
+
+// loadEntities reads the --entities and --caption-entities files. The text
+// becomes the caption when attachments are sent with it, so captions fall back
+// to --entities. Both are checked against the text before anything is sent.
+func loadEntities(cmd *cli.Command, message string) ([]entity.MessageEntity, []entity.MessageEntity, error) {
+	messageEntities, err := readEntities(cmd, "entities", message)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cmd.IsSet("caption-entities") {
+		return messageEntities, messageEntities, nil
+	}
+
+	captionEntities, err := readEntities(cmd, "caption-entities", message)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return messageEntities, captionEntities, nil
+}
+
+func readEntities(cmd *cli.Command, flag, message string) ([]entity.MessageEntity, error) {
+	path := cmd.String(flag)
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read --%s file: %w", flag, err)
+	}
+
+	var entities []entity.MessageEntity
+	if err = json.Unmarshal(content, &entities); err != nil {
+		return nil, fmt.Errorf("parse --%s file %s as a JSON array of MessageEntity: %w", flag, path, err)
+	}
+	if err = entity.Validate(message, entities); err != nil {
+		return nil, fmt.Errorf("invalid --%s: %w", flag, err)
+	}
+
+	return entities, nil
+}
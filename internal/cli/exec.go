@@ -0,0 +1,481 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf16"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/execstate"
+	"github.com/beeyev/telegram-owl/internal/telegram"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
+)
+
+const execUsageText = `Examples:
+  telegram-owl exec -t $TOKEN -c @ops -- ./backup.sh --full
+  telegram-owl exec -t $TOKEN -c @ops --on change --name nightly-backup -- ./backup.sh`
+
+const (
+	execOnFailure = "failure"
+	execOnAlways  = "always"
+	execOnChange  = "change"
+
+	// execLogLimitBytes bounds the memory kept for the child's output. Only the
+	// end of longer output is reported.
+	execLogLimitBytes = 1 << 20
+	// execLabelLength caps the job name and command line in the summary, so
+	// the summary always leaves room for output in a caption.
+	execLabelLength = 256
+
+	// Shell conventions for a command that cannot be run.
+	execExitCannotRun = 126
+	execExitNotFound  = 127
+	// execExitSignalBase is added to the signal number when the child is
+	// killed by a signal, as shells do.
+	execExitSignalBase = 128
+)
+
+// execCommand wraps a command for cron jobs and CI steps. It keeps the child's
+// stdio attached to the terminal, reports the outcome to Telegram according to
+// --on and exits with the child's exit code.
+func execCommand(apiBotURL string) *cli.Command {
+	stopAfterCommand := 1
+
+	return &cli.Command{
+		Name:      "exec",
+		Usage:     "Run a command and report its exit code, duration and output.",
+		UsageText: execUsageText,
+		Description: "Flags after the command name are passed to the command. " +
+			"Exits with the command's exit code, 127 when the command is not found " +
+			"and 126 when it cannot be started. Output too long for one message is attached " +
+			"as a log file holding its last 1 MiB.",
+		StopOnNthArg: &stopAfterCommand,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name: "on",
+				Usage: "When to notify: failure (non-zero exit), always, " +
+					"or change (the outcome differs from the last run).",
+				Value:    execOnFailure,
+				OnlyOnce: true,
+				Config:   cli.StringConfig{TrimSpace: true},
+			},
+			&cli.StringFlag{
+				Name:     "name",
+				Usage:    "Job name shown in the report and used to track --on change (default: the command).",
+				OnlyOnce: true,
+				Config:   cli.StringConfig{TrimSpace: true},
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			iv := &inputValues{cmd: cmd}
+			if err := iv.validateDestination(); err != nil {
+				return err
+			}
+			if err := iv.validateExec(); err != nil {
+				return err
+			}
+
+			telegramClient, err := newTelegramClient(apiBotURL, cmd)
+			if err != nil {
+				return err
+			}
+
+			args := cmd.Args().Slice()
+			job := cmd.String("name")
+			if job == "" {
+				job = commandLine(args)
+			}
+
+			result := runChild(cmd, args)
+
+			// The report must go out even when a forwarded signal also
+			// cancelled ctx.
+			reportCtx := context.WithoutCancel(ctx)
+			r := &execReporter{client: telegramClient, cmd: cmd, job: job, args: args}
+			if err = r.report(reportCtx, result); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrWriter, "warning: failed to report the command outcome: %v\n", err)
+			}
+
+			if result.exitCode != 0 {
+				return &exitError{
+					code: result.exitCode,
+					err:  fmt.Errorf("%s %s", filepath.Base(args[0]), result.status),
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func (iv *inputValues) validateExec() error {
+	if iv.cmd.Args().Len() == 0 {
+		return errors.New("missing command to run, pass it after the flags: telegram-owl exec [flags] -- command args")
+	}
+
+	switch iv.cmd.String("on") {
+	case execOnFailure, execOnAlways, execOnChange:
+		return nil
+	default:
+		return fmt.Errorf("incorrect value for --on flag, possible values: %s, %s, %s",
+			execOnFailure, execOnAlways, execOnChange)
+	}
+}
+
+type execResult struct {
+	exitCode int
+	// status completes a sentence that starts with the job name, for example
+	// "failed with exit code 2".
+	status   string
+	duration time.Duration
+	output   *tailBuffer
+}
+
+// runChild runs args with the CLI's stdio. SIGINT, SIGTERM, SIGHUP and SIGQUIT
+// do not stop the CLI, so the child decides when to exit and its outcome is
+// still reported. They are forwarded to the child, except that with a
+// terminal Ctrl-C and Ctrl-\ already reach the child, which shares the
+// terminal's foreground process group, and would otherwise arrive twice.
+func runChild(cmd *cli.Command, args []string) *execResult {
+	output := &tailBuffer{limit: execLogLimitBytes}
+	result := &execResult{output: output}
+
+	child := exec.Command(args[0], args[1:]...) //nolint:gosec // Running the user's command is the purpose of exec.
+	child.Stdin = cmd.Reader
+	child.Stdout = io.MultiWriter(cmd.Writer, output)
+	child.Stderr = io.MultiWriter(cmd.ErrWriter, output)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(signals)
+
+	startedAt := time.Now()
+	if err := child.Start(); err != nil {
+		result.exitCode = execExitCannotRun
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, fs.ErrNotExist) {
+			result.exitCode = execExitNotFound
+		}
+		result.status = "could not start: " + err.Error()
+
+		return result
+	}
+
+	fromTerminal := hasControllingTerminal()
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if fromTerminal && (sig == os.Interrupt || sig == syscall.SIGQUIT) {
+					continue
+				}
+				// Not every platform can deliver every signal. The child
+				// exits on its own terms either way.
+				_ = child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	waitErr := child.Wait()
+	close(done)
+	result.duration = time.Since(startedAt)
+
+	state := child.ProcessState
+	switch {
+	case state == nil:
+		result.exitCode = execExitCannotRun
+		result.status = "failed: " + waitErr.Error()
+	case state.ExitCode() >= 0:
+		result.exitCode = state.ExitCode()
+		if result.exitCode == 0 {
+			result.status = "succeeded"
+		} else {
+			result.status = "failed with exit code " + strconv.Itoa(result.exitCode)
+		}
+	default:
+		result.exitCode = execExitCannotRun
+		result.status = "was terminated"
+		if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+			result.exitCode = execExitSignalBase + int(ws.Signal())
+			result.status = "was killed by signal " + ws.Signal().String()
+		}
+	}
+
+	return result
+}
+
+type execReporter struct {
+	client *telegram.Client
+	cmd    *cli.Command
+	job    string
+	args   []string
+}
+
+// report applies --on and sends the summary. With --on change the run is
+// recorded only once no notification is pending, so a failed send is retried
+// by the next run with the same outcome.
+func (r *execReporter) report(ctx context.Context, result *execResult) error {
+	policy := r.cmd.String("on")
+	run := execstate.Run{ExitCode: result.exitCode, FinishedAt: time.Now().UTC()}
+
+	var store *execstate.Store
+	var key execstate.Key
+	notify := policy == execOnAlways || !run.Succeeded()
+	if policy == execOnChange {
+		dir, err := cacheDir(r.cmd)
+		if err != nil {
+			return err
+		}
+		store = execstate.New(dir)
+		botID, _, _ := strings.Cut(r.cmd.String("token"), ":")
+		key = execstate.Key{BotID: botID, ChatID: r.cmd.String("chat")}
+
+		last, found, err := store.Last(key, r.job)
+		if err != nil {
+			// Without the previous outcome, notify as --on failure would.
+			_, _ = fmt.Fprintf(r.cmd.ErrWriter, "warning: %v\n", err)
+		} else if found {
+			notify = last.Succeeded() != run.Succeeded()
+		}
+	}
+
+	if notify {
+		if err := r.send(ctx, result); err != nil {
+			return err
+		}
+		// stdout belongs to the child, so progress goes to stderr.
+		if r.cmd.Bool("verbose") {
+			_, _ = fmt.Fprintf(r.cmd.ErrWriter, "Report sent successfully. Chat ID: %s\n", r.cmd.String("chat"))
+		}
+	}
+
+	if store != nil {
+		return store.Record(key, r.job, run)
+	}
+
+	return nil
+}
+
+// send posts the summary with the output in a <pre> block. Output that does
+// not fit in one message, or that was cut by the buffer, is attached as a log
+// file instead, with its end in the caption.
+func (r *execReporter) send(ctx context.Context, result *execResult) error {
+	summary := r.summary(result)
+	output, dropped := result.output.snapshot()
+
+	// Telegram counts the parsed text. The summary's markup only makes the
+	// estimate conservative.
+	if dropped == 0 && entity.UTF16Len(summary)+2+entity.UTF16Len(output) <= sendmessage.MaxTextLength {
+		text := summary + "\n\n<i>No output.</i>"
+		if output != "" {
+			text = summary + "\n\n<pre>" + html.EscapeString(output) + "</pre>"
+		}
+
+		_, err := r.client.SendMessage.Send(ctx, &sendmessage.Options{
+			ChatID:              r.cmd.String("chat"),
+			MessageThreadID:     r.cmd.String("thread"),
+			Text:                text,
+			ParseMode:           "html",
+			DisableNotification: r.cmd.Bool("silent"),
+			ProtectContent:      r.cmd.Bool("protect"),
+			DisableLinkPreview:  true,
+		})
+
+		return err
+	}
+
+	caption := summary
+	if tail := tailWithin(output, sendmediagroup.MaxCaptionLength-entity.UTF16Len(summary)-2); tail != "" {
+		caption += "\n\n<pre>" + html.EscapeString(tail) + "</pre>"
+	}
+
+	logFile := output
+	if dropped > 0 {
+		logFile = fmt.Sprintf("[first %d bytes of output omitted]\n", dropped) + output
+	}
+
+	_, err := r.client.SendMediaGroup.Send(ctx, &sendmediagroup.Options{
+		ChatID:              r.cmd.String("chat"),
+		MessageThreadID:     r.cmd.String("thread"),
+		Caption:             caption,
+		ParseMode:           "html",
+		DisableNotification: r.cmd.Bool("silent"),
+		ProtectContent:      r.cmd.Bool("protect"),
+		Attachments: attachment.Attachments{{
+			AType:     attachment.Document,
+			FileName:  logFileName(r.job),
+			SizeBytes: int64(len(logFile)),
+			File:      attachment.NewMemoryFile([]byte(logFile)),
+		}},
+	})
+
+	return err
+}
+
+func (r *execReporter) summary(result *execResult) string {
+	icon := "✅"
+	if result.exitCode != 0 {
+		icon = "❌"
+	}
+
+	lines := []string{fmt.Sprintf("%s <b>%s</b> %s", icon, escapeLabel(r.job), html.EscapeString(result.status))}
+	// Without --name the job is already the command line.
+	if command := commandLine(r.args); command != r.job {
+		lines = append(lines, "Command: <code>"+escapeLabel(command)+"</code>")
+	}
+	lines = append(lines, "Duration: "+result.duration.Round(time.Millisecond).String())
+	if host, err := os.Hostname(); err == nil {
+		lines = append(lines, "Host: "+escapeLabel(host))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func escapeLabel(label string) string {
+	if truncated := entity.TruncateUTF16(label, execLabelLength); truncated != label {
+		label = truncated + "…"
+	}
+
+	return html.EscapeString(label)
+}
+
+// commandLine quotes arguments that a shell would split, so the reported
+// command can be copied back into a terminal.
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+// logFileNameUnsafe matches the characters replaced in log file names.
+var logFileNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func logFileName(job string) string {
+	name := logFileNameUnsafe.ReplaceAllString(job, "_")
+	name = strings.Trim(name, "._")
+	if name == "" || len(name) > 64 {
+		name = "output"
+	}
+
+	return name + ".log"
+}
+
+// hasControllingTerminal reports whether the CLI runs in a terminal, which
+// sends the keyboard signals to every process in its foreground group.
+func hasControllingTerminal() bool {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	_ = tty.Close()
+
+	return true
+}
+
+// tailWithin returns the longest run of whole trailing lines of text that fits
+// in limit UTF-16 code units. A last line longer than limit is cut from the
+// start.
+func tailWithin(text string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	if entity.UTF16Len(text) <= limit {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	start := len(lines)
+	size := 0
+	for start > 0 {
+		next := entity.UTF16Len(lines[start-1])
+		if start < len(lines) {
+			next++
+		}
+		if size+next > limit {
+			break
+		}
+		size += next
+		start--
+	}
+	if start == len(lines) {
+		last := []rune(lines[len(lines)-1])
+		i := len(last)
+		for size = 0; i > 0 && size+utf16.RuneLen(last[i-1]) <= limit; i-- {
+			size += utf16.RuneLen(last[i-1])
+		}
+
+		return string(last[i:])
+	}
+
+	return strings.Join(lines[start:], "\n")
+}
+
+// tailBuffer keeps the last limit bytes written to it. The child's stdout and
+// stderr are copied concurrently, so writes are serialized.
+type tailBuffer struct {
+	mu      sync.Mutex
+	limit   int
+	data    []byte
+	dropped int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	// Trim in batches, so that a chatty child does not cause a copy on
+	// every write.
+	if len(b.data) > 2*b.limit {
+		excess := len(b.data) - b.limit
+		b.dropped += int64(excess)
+		b.data = bytes.Clone(b.data[excess:])
+	}
+
+	return len(p), nil
+}
+
+// snapshot returns the kept output as valid UTF-8 without the final line
+// ending, and the number of bytes dropped before it.
+func (b *tailBuffer) snapshot() (string, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	data := b.data
+	dropped := b.dropped
+	if len(data) > b.limit {
+		dropped += int64(len(data) - b.limit)
+		data = data[len(data)-b.limit:]
+	}
+
+	output := strings.ToValidUTF8(string(data), "�")
+
+	return strings.TrimRight(output, "\r\n"), dropped
+}
@@ -0,0 +1,51 @@
+package cli //nolint:testpackage // Exercise the unexported output helpers directly.
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTailBuffer_KeepsEnd(t *testing.T) {
+	t.Parallel()
+
+	b := &tailBuffer{limit: 10}
+	for range 5 {
+		_, _ = b.Write([]byte("0123456789"))
+	}
+	_, _ = b.Write([]byte("abc\n"))
+
+	output, dropped := b.snapshot()
+	assert.Equal(t, "456789abc", output, "the last 10 bytes without the final line ending")
+	assert.Equal(t, int64(44), dropped)
+}
+
+func TestTailWithin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "fits", text: "one\ntwo", limit: 7, want: "one\ntwo"},
+		{name: "whole lines only", text: "one\ntwo\nthree", limit: 10, want: "two\nthree"},
+		{
+			name:  "long last line is cut from the start",
+			text:  "one\n" + strings.Repeat("x", 8) + "yz",
+			limit: 3,
+			want:  "xyz",
+		},
+		{name: "no room", text: "one", limit: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tailWithin(tt.text, tt.limit))
+		})
+	}
+}
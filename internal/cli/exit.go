@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+)
+
+// exitError carries a process exit code chosen by a command, such as the
+// option picked in ask. urfave's own exit handling is disabled in NewApp so
+// that main can exit after its deferred cleanup has run.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode implements urfave's ExitCoder.
+func (e *exitError) ExitCode() int {
+	return e.code
+}
+
+// ExitCode returns the process exit code for an error returned by the app:
+// the code chosen by the command, or 1 for any other error.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if exitErr, ok := errors.AsType[*exitError](err); ok {
+		return exitErr.code
+	}
+
+	return 1
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/filecache"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
+)
+
+const cacheUsageText = `Examples:
+  telegram-owl cache prune
+  telegram-owl cache prune --older-than 168h
+  telegram-owl cache prune --all`
+
+// defaultCacheMaxAge is how long a cached file_id is kept without being sent.
+const defaultCacheMaxAge = 30 * 24 * time.Hour
+
+// fileIDCache sends content uploaded before by the file_id Telegram returned
+// for it, so the same logo or report is not uploaded on every run.
+type fileIDCache struct {
+	store *filecache.Store
+	botID string
+	// reused counts the attachments sent by file_id instead of uploaded.
+	reused int
+}
+
+func newFileIDCache(cmd *cli.Command) (*fileIDCache, error) {
+	dir, err := cacheDir(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like the topic cache, the key holds only the bot ID from the token.
+	botID, _, _ := strings.Cut(cmd.String("token"), ":")
+
+	return &fileIDCache{store: filecache.New(dir), botID: botID}, nil
+}
+
+// lookup hashes every attachment that can be read twice and sets FileID on
+// content sent before. It returns a key per attachment, zero for attachments
+// that were not hashed, such as URLs and downloads.
+func (c *fileIDCache) lookup(attachments attachment.Attachments) ([]filecache.Key, error) {
+	keys := make([]filecache.Key, len(attachments))
+	for i, attach := range attachments {
+		file, ok := attach.File.(io.ReadSeeker)
+		if !ok || attach.URL != "" {
+			continue
+		}
+
+		hash := sha256.New()
+		if _, err := io.Copy(hash, file); err != nil {
+			return nil, fmt.Errorf("hash %q: %w", attach.FileName, err)
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("hash %q: %w", attach.FileName, err)
+		}
+
+		keys[i] = filecache.Key{
+			BotID:     c.botID,
+			Type:      attach.AType.String(),
+			SHA256:    hex.EncodeToString(hash.Sum(nil)),
+			SizeBytes: attach.SizeBytes,
+		}
+		fileID, found, err := c.store.Lookup(keys[i])
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			attach.FileID = fileID
+		}
+	}
+
+	return keys, nil
+}
+
+// forget drops the cached file IDs of attachments after Telegram rejected
+// them, and reports whether any were dropped. The attachments are uploaded
+// again on the next send.
+func (c *fileIDCache) forget(attachments attachment.Attachments, keys []filecache.Key) (bool, error) {
+	var stale []filecache.Key
+	for i, attach := range attachments {
+		if attach.FileID != "" {
+			stale = append(stale, keys[i])
+			attach.FileID = ""
+		}
+	}
+	if len(stale) == 0 {
+		return false, nil
+	}
+
+	return true, c.store.Forget(stale...)
+}
+
+// save records the file IDs Telegram returned for a sent album. Reused file
+// IDs are saved again, so pruning only drops files that stopped being sent.
+func (c *fileIDCache) save(
+	attachments attachment.Attachments,
+	keys []filecache.Key,
+	messages []sendmediagroup.Message,
+) error {
+	fileIDs := make(map[filecache.Key]string)
+	for i, key := range keys {
+		if key.SHA256 == "" || i >= len(messages) {
+			continue
+		}
+		if attachments[i].FileID != "" {
+			c.reused++
+		}
+		if fileID := messages[i].FileID(); fileID != "" {
+			fileIDs[key] = fileID
+		}
+	}
+
+	return c.store.Save(fileIDs, time.Now())
+}
+
+// isWrongFileID recognizes Telegram's errors for a file_id it no longer
+// accepts, for example after the file was deleted from its servers.
+func isWrongFileID(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "wrong file identifier") ||
+		strings.Contains(err.Error(), "wrong remote file identifier"))
+}
+
+// rewind moves every uploaded file back to its start for another upload, and
+// reports false if one of them cannot be read again.
+func rewind(attachments attachment.Attachments) bool {
+	for _, attach := range attachments {
+		if attach.File == nil || attach.URL != "" || attach.FileID != "" {
+			continue
+		}
+		file, ok := attach.File.(io.Seeker)
+		if !ok {
+			return false
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cacheCommand manages the local caches in --cache-dir.
+func cacheCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "cache",
+		Usage:     "Manage the local caches.",
+		UsageText: cacheUsageText,
+		Commands: []*cli.Command{
+			{
+				Name:  "prune",
+				Usage: "Remove cached file IDs that were not sent recently, so their files are uploaded again.",
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:     "older-than",
+						Usage:    "Remove file IDs not sent within this duration",
+						Value:    defaultCacheMaxAge,
+						OnlyOnce: true,
+					},
+					&cli.BoolFlag{
+						Name:        "all",
+						Usage:       "Remove every cached file ID",
+						OnlyOnce:    true,
+						HideDefault: true,
+					},
+				},
+				Action: func(_ context.Context, cmd *cli.Command) error {
+					if cmd.Duration("older-than") <= 0 {
+						return errors.New("--older-than must be positive, use --all to remove every file ID")
+					}
+
+					dir, err := cacheDir(cmd)
+					if err != nil {
+						return err
+					}
+
+					var cutoff time.Time
+					if !cmd.Bool("all") {
+						cutoff = time.Now().Add(-cmd.Duration("older-than"))
+					}
+					pruned, err := filecache.New(dir).Prune(cutoff)
+					if err != nil {
+						return err
+					}
+
+					_, _ = fmt.Fprintf(cmd.Writer, "Removed %d cached file ID(s)\n", pruned)
+
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/gfm"
+)
+
+const (
+	formatGFM         = "gfm"
+	gfmTargetHTML     = "html"
+	gfmTargetRichHTML = "rich-html"
+)
+
+// messageFormat returns the format the message is sent in. GitHub Markdown is
+// converted before sending, so --format gfm is sent as its --gfm-target.
+func messageFormat(cmd *cli.Command) string {
+	if format := cmd.String("format"); format != formatGFM {
+		return format
+	}
+
+	return cmd.String("gfm-target")
+}
+
+// convertMessage converts a --format gfm message to its --gfm-target. Other
+// formats are sent as written.
+func convertMessage(cmd *cli.Command, message string) (string, error) {
+	if cmd.String("format") != formatGFM || message == "" {
+		return message, nil
+	}
+
+	if cmd.String("gfm-target") == gfmTargetRichHTML {
+		return gfm.ToRichHTML(message)
+	}
+
+	return gfm.ToHTML(message), nil
+}
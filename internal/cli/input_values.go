@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/beeyev/telegram-owl/internal/telegram/common/textsplit"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendrichmessage"
 )
 
@@ -17,6 +20,162 @@ type inputValues struct {
 }
 
 func (iv *inputValues) validate() error {
+	if err := iv.validateDestination(); err != nil {
+		return err
+	}
+
+	if err := iv.validateTopic(); err != nil {
+		return err
+	}
+
+	if err := iv.validateStream(); err != nil {
+		return err
+	}
+
+	if err := iv.validateFormat(); err != nil {
+		return err
+	}
+
+	if sendrichmessage.IsFormat(messageFormat(iv.cmd)) && iv.cmd.Bool("no-link-preview") {
+		return errors.New("--no-link-preview is not supported with rich message formats")
+	}
+
+	if err := iv.validateAttach(); err != nil {
+		return err
+	}
+
+	if err := iv.validateEscape(); err != nil {
+		return err
+	}
+
+	if err := iv.validateTemplate(); err != nil {
+		return err
+	}
+
+	if err := iv.validateEntities(); err != nil {
+		return err
+	}
+
+	return iv.validateOverflow()
+}
+
+func (iv *inputValues) validateFormat() error {
+	format := iv.cmd.String("format")
+	if format != "" && format != "markdown" && format != "html" && format != formatGFM &&
+		!sendrichmessage.IsFormat(format) {
+		return errors.New(
+			`incorrect value for --format flag, possible values: markdown, html, rich-markdown, rich-html, gfm`,
+		)
+	}
+
+	if target := iv.cmd.String("gfm-target"); target != gfmTargetHTML && target != gfmTargetRichHTML {
+		return errors.New("incorrect value for --gfm-target flag, possible values: html, rich-html")
+	}
+	if iv.cmd.IsSet("gfm-target") && format != formatGFM {
+		return errors.New("--gfm-target requires --format gfm")
+	}
+
+	return nil
+}
+
+func (iv *inputValues) validateEscape() error {
+	hasVars := iv.cmd.IsSet("var")
+
+	switch {
+	case iv.cmd.Bool("escape") && iv.cmd.String("format") == "":
+		return errors.New("--escape requires --format, plain text needs no escaping")
+	case iv.cmd.Bool("escape") && hasVars:
+		return errors.New("--escape cannot be combined with --var, use {{ .name | escape }} in the message instead")
+	case iv.cmd.Bool("stream") && (iv.cmd.Bool("escape") || hasVars):
+		return errors.New("--stream escapes every line itself and cannot be combined with --escape or --var")
+	}
+
+	return nil
+}
+
+func (iv *inputValues) validateTemplate() error {
+	hasTemplate := iv.cmd.IsSet("template") || iv.cmd.IsSet("template-string")
+	hasData := iv.cmd.IsSet("data")
+
+	switch {
+	case iv.cmd.IsSet("template") && iv.cmd.IsSet("template-string"):
+		return errors.New("--template and --template-string cannot be combined")
+	case hasTemplate && (iv.cmd.String("message") != "" || iv.cmd.Bool("stdin")):
+		return errors.New("--template and --template-string replace the message and cannot be combined with " +
+			"--message or --stdin")
+	case iv.cmd.String("data") == "-" && iv.cmd.Bool("stdin"):
+		return errors.New("--data - and --stdin cannot both read standard input")
+	case iv.cmd.Bool("escape") && (hasTemplate || hasData):
+		return errors.New(
+			"--escape cannot be combined with templates, use {{ .name | escape }} in the template instead",
+		)
+	case iv.cmd.Bool("stream") && (hasTemplate || hasData):
+		return errors.New("--stream cannot be combined with --template, --template-string or --data")
+	}
+
+	return nil
+}
+
+func (iv *inputValues) validateOverflow() error {
+	mode := overflowMode(iv.cmd)
+	if !slices.Contains(overflowModes, mode) {
+		return fmt.Errorf(
+			"incorrect value for --overflow flag, possible values: %s",
+			strings.Join(overflowModes, ", "),
+		)
+	}
+	if iv.cmd.Bool("split") && iv.cmd.IsSet("overflow") && iv.cmd.String("overflow") != overflowSplit {
+		return fmt.Errorf("--split cannot be combined with --overflow=%s", iv.cmd.String("overflow"))
+	}
+
+	// --split is the short form, so errors name the flag that was used.
+	flag := "--overflow=" + mode
+	if iv.cmd.Bool("split") {
+		flag = "--split"
+	}
+
+	switch {
+	case mode != overflowSplit && iv.cmd.Bool("split-counter"):
+		return errors.New("--split-counter requires --split")
+	case mode != overflowSplit && iv.cmd.Bool("split-reply"):
+		return errors.New("--split-reply requires --split")
+	case mode != overflowDocument && iv.cmd.IsSet("overflow-filename"):
+		return errors.New("--overflow-filename requires --overflow=document")
+	case mode != overflowTruncate && iv.cmd.IsSet("overflow-keep"):
+		return errors.New("--overflow-keep requires --overflow=truncate")
+	case mode == overflowError:
+		return nil
+	case sendrichmessage.IsFormat(messageFormat(iv.cmd)):
+		return fmt.Errorf("%s is not supported with rich message formats", flag)
+	case iv.cmd.Bool("stream"):
+		return fmt.Errorf("%s cannot be combined with --stream", flag)
+	}
+
+	if name := iv.cmd.String("overflow-filename"); name == "" || filepath.Base(name) != name {
+		return errors.New("--overflow-filename must be a file name without a directory")
+	}
+
+	keep := textsplit.Keep(iv.cmd.String("overflow-keep"))
+	if keep != textsplit.KeepHead && keep != textsplit.KeepTail && keep != textsplit.KeepBoth {
+		return errors.New("incorrect value for --overflow-keep flag, possible values: head, tail, both")
+	}
+
+	return nil
+}
+
+// validateDestination checks the flags every Telegram request needs. Commands
+// that target a chat share it with the root send action.
+func (iv *inputValues) validateDestination() error {
+	if err := iv.validateToken(); err != nil {
+		return err
+	}
+
+	return iv.validateChat()
+}
+
+// validateToken is also used on its own by commands that address the bot
+// rather than a chat.
+func (iv *inputValues) validateToken() error {
 	if iv.cmd.String("token") == "" {
 		//nolint:revive,staticcheck // Multiline CLI guidance intentionally uses sentence casing and punctuation.
 		return errors.New(`missing required flag: --token
@@ -28,6 +187,10 @@ Example:
 Run with --help to see all options.`)
 	}
 
+	return nil
+}
+
+func (iv *inputValues) validateChat() error {
 	if iv.cmd.String("chat") == "" {
 		//nolint:revive,staticcheck // Multiline CLI guidance intentionally uses sentence casing and punctuation.
 		return errors.New(`missing required flag: --chat
@@ -39,18 +202,6 @@ Example:
 Run with --help to see all options.`)
 	}
 
-	format := iv.cmd.String("format")
-
-	if format != "" && format != "markdown" && format != "html" && !sendrichmessage.IsFormat(format) {
-		return errors.New(
-			`incorrect value for --format flag, possible values: markdown, html, rich-markdown, rich-html`,
-		)
-	}
-
-	if sendrichmessage.IsFormat(format) && iv.cmd.Bool("no-link-preview") {
-		return errors.New("--no-link-preview is not supported with rich message formats")
-	}
-
 	return nil
 }
 
@@ -61,15 +212,16 @@ func (iv *inputValues) getMessage() (string, error) {
 		return msg, nil
 	}
 
-	if !iv.cmd.Bool("stdin") {
+	// --stream consumes stdin line by line after the message is resolved.
+	if !iv.cmd.Bool("stdin") || iv.cmd.Bool("stream") {
 		return "", nil
 	}
 
-	stat, err := os.Stdin.Stat()
+	piped, err := stdinIsPiped(iv.cmd.Reader)
 	if err != nil {
-		return "", fmt.Errorf("inspect stdin: %w", err)
+		return "", err
 	}
-	if stat.Mode()&os.ModeCharDevice != 0 {
+	if !piped {
 		// --stdin may accompany attachments to request an optional caption. An
 		// interactive stdin therefore means "no caption" when attachments exist,
 		// but is an error for a text-only send where it would send nothing.
@@ -80,16 +232,33 @@ func (iv *inputValues) getMessage() (string, error) {
 		return "", errors.New("stdin does not contain piped data")
 	}
 
-	data, err := io.ReadAll(os.Stdin)
+	data, err := io.ReadAll(iv.cmd.Reader)
 	if err != nil {
 		return "", fmt.Errorf("read stdin: %w", err)
 	}
 
 	// Shell pipelines commonly append one line ending. Remove only that ending
 	// so indentation and other intentional whitespace remain available to rich
-	// Markdown and HTML.
-	message := strings.TrimSuffix(string(data), "\n")
-	message = strings.TrimSuffix(message, "\r")
+	// Markdown and HTML. Template files are trimmed the same way.
+	return trimLineEnding(string(data)), nil
+}
+
+func trimLineEnding(s string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(s, "\n"), "\r")
+}
+
+// stdinIsPiped reports whether stdin is a pipe or file rather than a terminal.
+// A reader that is not a file, such as one given by a test, is piped input.
+func stdinIsPiped(stdin io.Reader) (bool, error) {
+	f, ok := stdin.(*os.File)
+	if !ok {
+		return true, nil
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return false, fmt.Errorf("inspect stdin: %w", err)
+	}
 
-	return message, nil
+	return stat.Mode()&os.ModeCharDevice == 0, nil
 }
@@ -0,0 +1,217 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+const joinUsageText = `Examples:
+  telegram-owl join --manifest backup.tar.sha256 backup.tar.part*
+  telegram-owl join --output restored.tar ~/Downloads/backup.tar.part*`
+
+// partName matches the name of a part written by --split-large-files.
+var partName = regexp.MustCompile(`^(.+)\.part(\d+)$`)
+
+// joinPart is one downloaded part and its position in the original file.
+type joinPart struct {
+	path   string
+	number int
+}
+
+// joinCommand reassembles the parts --split-large-files sends, verifying them
+// against the manifest message when it is given, so a corrupt or missing part
+// is reported instead of producing a broken file.
+func joinCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "join",
+		Usage:     "Verify and reassemble the parts of a file sent with --split-large-files.",
+		UsageText: joinUsageText,
+		ArgsUsage: "PART...",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "manifest",
+				Usage:     "Manifest message saved to a file, to verify the SHA-256 of every part and the result.",
+				OnlyOnce:  true,
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:      "output",
+				Usage:     "File to write. Defaults to the part name without its .partNNN suffix.",
+				Aliases:   []string{"o"},
+				OnlyOnce:  true,
+				TakesFile: true,
+			},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			name, parts, err := orderParts(cmd.Args().Slice())
+			if err != nil {
+				return err
+			}
+
+			var sums map[string]string
+			if manifestPath := cmd.String("manifest"); manifestPath != "" {
+				if sums, err = readManifest(manifestPath, name, len(parts)); err != nil {
+					return err
+				}
+			}
+
+			output := cmd.String("output")
+			if output == "" {
+				output = filepath.Join(filepath.Dir(parts[0].path), name)
+			}
+
+			size, sum, err := joinParts(parts, output, name, sums)
+			if err != nil {
+				return err
+			}
+
+			verified := ""
+			if sums != nil {
+				verified = ", verified against the manifest"
+			}
+			_, _ = fmt.Fprintf(
+				cmd.Writer,
+				"Joined %d parts into %s: %d bytes, SHA-256 %s%s\n",
+				len(parts), output, size, sum, verified,
+			)
+
+			return nil
+		},
+	}
+}
+
+// orderParts sorts the parts by number and returns the name of the original
+// file. Every part from 1 to the highest number must be present exactly once.
+func orderParts(paths []string) (string, []joinPart, error) {
+	if len(paths) == 0 {
+		return "", nil, errors.New("join requires the PART files to reassemble")
+	}
+
+	var name string
+	parts := make([]joinPart, 0, len(paths))
+	for _, path := range paths {
+		match := partName.FindStringSubmatch(filepath.Base(path))
+		if match == nil {
+			return "", nil, fmt.Errorf("%q is not a part: the name must end with .partNNN", path)
+		}
+		if name == "" {
+			name = match[1]
+		} else if match[1] != name {
+			return "", nil, fmt.Errorf("parts of different files: %q and %q", name, match[1])
+		}
+
+		number, err := strconv.Atoi(match[2])
+		if err != nil {
+			return "", nil, fmt.Errorf("%q is not a part: %w", path, err)
+		}
+		parts = append(parts, joinPart{path: path, number: number})
+	}
+
+	slices.SortFunc(parts, func(a, b joinPart) int {
+		return a.number - b.number
+	})
+	for i, part := range parts {
+		switch {
+		case part.number < i+1:
+			return "", nil, fmt.Errorf("part %d of %q is given twice", part.number, name)
+		case part.number > i+1:
+			return "", nil, fmt.Errorf("part %d of %q is missing", i+1, name)
+		}
+	}
+
+	return name, parts, nil
+}
+
+// readManifest parses the manifest and checks it describes name in exactly
+// partCount parts, so a missing last part is caught too.
+func readManifest(path, name string, partCount int) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	defer file.Close()
+
+	sums, err := attachment.ParseManifest(file)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+	if _, ok := sums[name]; !ok {
+		return nil, fmt.Errorf("manifest %s has no SHA-256 for %q", path, name)
+	}
+
+	listed := 0
+	for fileName := range sums {
+		if match := partName.FindStringSubmatch(fileName); match != nil && match[1] == name {
+			listed++
+		}
+	}
+	if listed != partCount {
+		return nil, fmt.Errorf("manifest %s lists %d parts of %q, got %d", path, listed, name, partCount)
+	}
+
+	return sums, nil
+}
+
+// joinParts writes the parts to a new output file, checking each against sums
+// when given. The output is removed if anything fails, so no partial file is
+// left that looks complete.
+func joinParts(parts []joinPart, output, name string, sums map[string]string) (int64, string, error) {
+	out, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return 0, "", fmt.Errorf("create output: %w", err)
+	}
+
+	size, sum, err := copyParts(out, parts, sums)
+	if err == nil && sums != nil && sum != sums[name] {
+		err = fmt.Errorf("%s: SHA-256 mismatch: manifest has %s, got %s", name, sums[name], sum)
+	}
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close output: %w", closeErr)
+	}
+	if err != nil {
+		return 0, "", errors.Join(err, os.Remove(output))
+	}
+
+	return size, sum, nil
+}
+
+func copyParts(out io.Writer, parts []joinPart, sums map[string]string) (int64, string, error) {
+	whole := sha256.New()
+	var size int64
+	for _, part := range parts {
+		file, err := os.Open(part.path)
+		if err != nil {
+			return 0, "", fmt.Errorf("read part: %w", err)
+		}
+
+		partSum := sha256.New()
+		written, err := io.Copy(io.MultiWriter(out, whole, partSum), file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return 0, "", fmt.Errorf("join part %s: %w", part.path, err)
+		}
+		size += written
+
+		base := filepath.Base(part.path)
+		if got := hex.EncodeToString(partSum.Sum(nil)); sums != nil && got != sums[base] {
+			return 0, "", fmt.Errorf("%s: SHA-256 mismatch: manifest has %s, got %s", base, sums[base], got)
+		}
+	}
+
+	return size, hex.EncodeToString(whole.Sum(nil)), nil
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
+)
+
+const lintUsageText = `Examples:
+  telegram-owl lint --format html release-notes.html
+  telegram-owl lint --format markdown --caption -m 'Build *passed*\.'
+  ./render-report.sh | telegram-owl lint --format markdown`
+
+// lintInput is one text to check and the name its problems are reported under.
+type lintInput struct {
+	name string
+	text string
+}
+
+// lintCommand parses formatted text the way Telegram would, without a token
+// or network access, so templates and generated reports can be checked in CI
+// before anything is sent.
+func lintCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "lint",
+		Usage:     "Check HTML or MarkdownV2 text for syntax errors and length before sending.",
+		UsageText: lintUsageText,
+		ArgsUsage: "[FILE]...",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "format",
+				Usage:    "Format of the text: markdown, html",
+				Aliases:  []string{"f"},
+				OnlyOnce: true,
+				Config:   cli.StringConfig{TrimSpace: true},
+			},
+			&cli.StringFlag{
+				Name:     "message",
+				Usage:    "Text to check instead of files or piped stdin.",
+				Aliases:  []string{"m"},
+				OnlyOnce: true,
+			},
+			&cli.BoolFlag{
+				Name:        "caption",
+				Usage:       "Check the text against the caption limit instead of the message limit.",
+				OnlyOnce:    true,
+				HideDefault: true,
+			},
+		},
+		Action: func(_ context.Context, cmd *cli.Command) error {
+			format := cmd.String("format")
+			if format != "markdown" && format != "html" {
+				return errors.New("incorrect value for --format flag, possible values: markdown, html")
+			}
+
+			inputs, err := lintInputs(cmd)
+			if err != nil {
+				return err
+			}
+
+			limit := sendmessage.MaxTextLength
+			if cmd.Bool("caption") {
+				limit = sendmediagroup.MaxCaptionLength
+			}
+
+			problems := 0
+			for _, input := range inputs {
+				if !lintText(cmd.Writer, input, format, limit) {
+					problems++
+				}
+			}
+			if problems > 0 {
+				return fmt.Errorf("lint found %d problem(s)", problems)
+			}
+
+			return nil
+		},
+	}
+}
+
+func lintInputs(cmd *cli.Command) ([]lintInput, error) {
+	message := cmd.String("message")
+	files := cmd.Args().Slice()
+	if message != "" && len(files) > 0 {
+		return nil, errors.New("--message cannot be combined with FILE arguments")
+	}
+	if message != "" {
+		return []lintInput{{name: "message", text: message}}, nil
+	}
+
+	if len(files) == 0 {
+		piped, err := stdinIsPiped(cmd.Reader)
+		if err != nil {
+			return nil, err
+		}
+		if !piped {
+			return nil, errors.New("lint requires FILE arguments, --message or text piped to stdin")
+		}
+
+		data, err := io.ReadAll(cmd.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+
+		return []lintInput{{name: "stdin", text: trimLineEnding(string(data))}}, nil
+	}
+
+	inputs := make([]lintInput, 0, len(files))
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		// Files are trimmed like --template files and piped stdin, which are
+		// what they usually end up as.
+		inputs = append(inputs, lintInput{name: path, text: trimLineEnding(string(data))})
+	}
+
+	return inputs, nil
+}
+
+// lintText writes one line per input in the "name:line:column: message" form
+// editors and CI annotations understand, and reports whether it passed.
+func lintText(w io.Writer, input lintInput, format string, limit int) bool {
+	parsed, err := markup.Parse(input.text, format)
+	if syntaxErr, ok := errors.AsType[*markup.SyntaxError](err); ok {
+		_, _ = fmt.Fprintf(w, "%s:%d:%d: %s\n", input.name, syntaxErr.Line, syntaxErr.Column, syntaxErr.Message)
+
+		return false
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "%s: %s\n", input.name, err)
+
+		return false
+	}
+
+	length := parsed.Length()
+	switch {
+	case length == 0:
+		_, _ = fmt.Fprintf(w, "%s: text is empty after parsing\n", input.name)
+
+		return false
+	case length > limit:
+		_, _ = fmt.Fprintf(
+			w, "%s: too long: must be <= %d characters after parsing, got %d\n", input.name, limit, length,
+		)
+
+		return false
+	}
+
+	_, _ = fmt.Fprintf(w, "%s: ok, %d characters, %d entities\n", input.name, length, len(parsed.Entities))
+
+	return true
+}
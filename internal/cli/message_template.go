@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/msgtemplate"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/escape"
+)
+
+// templateVarName matches the names that templates can reference as .name.
+var templateVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Template data keys that hold the environment and the --data JSON, so --var
+// cannot use them.
+const (
+	templateEnvKey  = "env"
+	templateDataKey = "data"
+)
+
+// templateVars collects repeated --var name=value flags. Unlike a slice flag,
+// a value is never split on commas.
+type templateVars map[string]string
+
+func (v templateVars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	if !templateVarName.MatchString(name) {
+		return fmt.Errorf("variable name %q must start with a letter or underscore and contain only letters, "+
+			"digits and underscores", name)
+	}
+	if name == templateEnvKey || name == templateDataKey {
+		return fmt.Errorf("variable name %q is reserved, templates use .%s for it", name, name)
+	}
+	if _, exists := v[name]; exists {
+		return fmt.Errorf("variable %q is set more than once", name)
+	}
+	v[name] = value
+
+	return nil
+}
+
+func (v templateVars) String() string {
+	pairs := make([]string, 0, len(v))
+	for _, name := range slices.Sorted(maps.Keys(v)) {
+		pairs = append(pairs, name+"="+v[name])
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func (v templateVars) Get() any {
+	return map[string]string(v)
+}
+
+func varFlag() *cli.GenericFlag {
+	return &cli.GenericFlag{
+		Name: "var",
+		Usage: "Template variable as name=value. Can be repeated. The message is then rendered as a Go template: " +
+			"{{ .name | escape }} inserts the value escaped for --format.",
+		Value:       templateVars{},
+		Local:       true,
+		HideDefault: true,
+	}
+}
+
+// renderMessage applies --escape or renders the message as a template. A
+// --message or --stdin text is a template only when --var or --data is set, so
+// plain text with braces is sent as written.
+func renderMessage(cmd *cli.Command, message string) (string, error) {
+	format := cmd.String("format")
+	if cmd.Bool("escape") {
+		return escape.Text(format, message), nil
+	}
+
+	vars, _ := cmd.Value("var").(map[string]string)
+	text, isTemplate, err := templateText(cmd, message)
+	if err != nil {
+		return "", err
+	}
+	if (!isTemplate && len(vars) == 0 && !cmd.IsSet("data")) || text == "" {
+		return text, nil
+	}
+
+	data, err := templateData(cmd, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return msgtemplate.Render(text, format, data)
+}
+
+// templateText returns the --template or --template-string text, and whether
+// one of them was set. Otherwise it returns the message.
+func templateText(cmd *cli.Command, message string) (string, bool, error) {
+	if cmd.IsSet("template") {
+		content, err := os.ReadFile(cmd.String("template"))
+		if err != nil {
+			return "", false, fmt.Errorf("read template: %w", err)
+		}
+
+		return trimLineEnding(string(content)), true, nil
+	}
+	if cmd.IsSet("template-string") {
+		return cmd.String("template-string"), true, nil
+	}
+
+	return message, false, nil
+}
+
+// templateData collects the --var values, the environment as .env and the
+// --data JSON as .data.
+func templateData(cmd *cli.Command, vars map[string]string) (map[string]any, error) {
+	env := make(map[string]string)
+	for _, pair := range os.Environ() {
+		if name, value, ok := strings.Cut(pair, "="); ok && name != "" {
+			env[name] = value
+		}
+	}
+
+	data := make(map[string]any, len(vars)+2)
+	for name, value := range vars {
+		data[name] = value
+	}
+	data[templateEnvKey] = env
+
+	if path := cmd.String("data"); path != "" {
+		value, err := readTemplateData(path, cmd.Reader)
+		if err != nil {
+			return nil, err
+		}
+		data[templateDataKey] = value
+	}
+
+	return data, nil
+}
+
+// readTemplateData decodes the JSON in path, or in stdin for "-". Numbers keep
+// their text, so large IDs are not printed as 1.2e+09.
+func readTemplateData(path string, stdin io.Reader) (any, error) {
+	r := stdin
+	if path == "-" {
+		piped, err := stdinIsPiped(stdin)
+		if err != nil {
+			return nil, err
+		}
+		if !piped {
+			return nil, errors.New("--data - expects JSON piped to stdin")
+		}
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("read template data: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("parse template data from %s: no JSON value", path)
+		}
+
+		return nil, fmt.Errorf("parse template data from %s: %w", path, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("parse template data from %s: unexpected content after the JSON value", path)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/textsplit"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
+)
+
+// Overflow policies for text that does not fit in one message. With the
+// default, the text is sent as is and the length check is left to Telegram.
+const (
+	overflowError    = "error"
+	overflowSplit    = "split"
+	overflowDocument = "document"
+	overflowTruncate = "truncate"
+)
+
+var overflowModes = []string{overflowError, overflowSplit, overflowDocument, overflowTruncate}
+
+const defaultOverflowFileName = "message.txt"
+
+// overflowMode resolves --overflow, of which --split is the short form.
+func overflowMode(cmd *cli.Command) string {
+	if cmd.Bool("split") {
+		return overflowSplit
+	}
+
+	return cmd.String("overflow")
+}
+
+// fitMessage applies the overflow policy and returns the messages to send. It
+// returns no messages when the text went out as a document instead.
+func (a *action) fitMessage(message string) ([]string, error) {
+	if textsplit.Length(message, a.MessageFormat) <= sendmessage.MaxTextLength {
+		return []string{message}, nil
+	}
+
+	opts := textsplit.Options{ParseMode: a.MessageFormat, Limit: sendmessage.MaxTextLength, Counters: a.splitCounter}
+
+	switch a.overflow {
+	case overflowSplit:
+		return textsplit.Split(message, opts), nil
+	case overflowTruncate:
+		return []string{textsplit.Truncate(message, opts, a.overflowKeep)}, nil
+	case overflowDocument:
+		return nil, a.sendOverflowDocument(message)
+	default:
+		return []string{message}, nil
+	}
+}
+
+// sendOverflowDocument uploads the text as a file with a short plain caption.
+// The text is kept as written, markup included, so nothing is lost.
+func (a *action) sendOverflowDocument(message string) error {
+	document := *a
+	document.attachLoader = &attachment.Loader{
+		FileOpener: &attachment.MemoryFileOpener{
+			Files: map[string][]byte{a.overflowFileName: []byte(message)},
+		},
+		IsEverythingDocument:        true,
+		MaxTotalAttachments:         1,
+		MaxPhotoAttachmentSizeBytes: maxPhotoAttachmentSizeBytes,
+		MaxAttachmentSizeBytes:      maxAttachmentSizeBytes,
+		MaxTotalSizeBytes:           maxAlbumSizeBytes,
+	}
+	document.attachmentsPaths = []string{a.overflowFileName}
+	document.MessageFormat = ""
+	document.captionEntities = nil
+	document.spoiler = false
+
+	lines := strings.Count(strings.TrimSuffix(message, "\n"), "\n") + 1
+	caption := fmt.Sprintf(
+		"The message is too long for Telegram (%d characters, %d lines), so it is attached as a file.",
+		textsplit.Length(message, a.MessageFormat),
+		lines,
+	)
+
+	return document.sendMediaGroup(caption)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/setmessagereaction"
+)
+
+const reactUsageText = `Examples:
+  telegram-owl react -t $TOKEN -c @mychannel --message-id 42 👍
+  telegram-owl react -t $TOKEN -c 123456789 --message-id 42 --big 🔥 🎉`
+
+// reactCommand marks an existing message instead of posting a new one. The
+// emoji are positional so a CI step can pick one with a shell expression.
+func reactCommand(apiBotURL string) *cli.Command {
+	return &cli.Command{
+		Name:      "react",
+		Usage:     "Set reactions on an existing message.",
+		UsageText: reactUsageText,
+		ArgsUsage: "<emoji or custom emoji ID>...",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:        "message-id",
+				Usage:       "ID of the message to react to (required).",
+				OnlyOnce:    true,
+				HideDefault: true,
+			},
+			&cli.BoolFlag{
+				Name:        "big",
+				Usage:       "Show the reaction with a big animation.",
+				OnlyOnce:    true,
+				HideDefault: true,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			iv := &inputValues{cmd: cmd}
+			if err := iv.validateDestination(); err != nil {
+				return err
+			}
+
+			telegramClient, err := newTelegramClient(apiBotURL, cmd)
+			if err != nil {
+				return err
+			}
+
+			chatID := cmd.String("chat")
+			messageID := cmd.Int("message-id")
+			err = telegramClient.SetMessageReaction.Send(ctx, &setmessagereaction.Options{
+				ChatID:    chatID,
+				MessageID: messageID,
+				Reactions: cmd.Args().Slice(),
+				IsBig:     cmd.Bool("big"),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to react to message %d in chat ID %s: %w", messageID, chatID, err)
+			}
+
+			if cmd.Bool("verbose") {
+				_, _ = fmt.Fprintf(cmd.Writer, "Reaction set successfully. Chat ID: %s. Message ID: %d\n", chatID, messageID)
+			}
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editmessagetext"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
+)
+
+const (
+	defaultStreamLines    = 20
+	defaultStreamInterval = 3 * time.Second
+	// maxStreamEditFailures tolerates short rate limiting. Failed edits are
+	// retried on the next tick because the message stays out of date.
+	maxStreamEditFailures = 3
+)
+
+func (iv *inputValues) validateStream() error {
+	if !iv.cmd.Bool("stream") {
+		return nil
+	}
+
+	switch {
+	case iv.cmd.String("message") != "":
+		return errors.New("--stream reads the message from stdin and cannot be combined with --message")
+	case len(iv.cmd.StringSlice("attach")) > 0:
+		return errors.New("--stream cannot be combined with --attach")
+	case iv.cmd.String("format") != "":
+		return errors.New("--stream always sends preformatted HTML and cannot be combined with --format")
+	case iv.cmd.Int("stream-lines") < 0:
+		return errors.New("--stream-lines must not be negative")
+	case iv.cmd.Duration("stream-interval") <= 0:
+		return errors.New("--stream-interval must be positive")
+	}
+
+	piped, err := stdinIsPiped(iv.cmd.Reader)
+	if err != nil {
+		return err
+	}
+	if !piped {
+		return errors.New("--stream requires piped data on stdin")
+	}
+
+	return nil
+}
+
+func runStream(
+	ctx context.Context,
+	cmd *cli.Command,
+	client *telegram.Client,
+	threadID string,
+	forgetStaleTopic func(error) error,
+) error {
+	s := &logStream{
+		client:   client,
+		warnings: cmd.ErrWriter,
+		chatID:   cmd.String("chat"),
+		threadID: threadID,
+		silent:   cmd.Bool("silent"),
+		protect:  cmd.Bool("protect"),
+		maxLines: cmd.Int("stream-lines"),
+		interval: cmd.Duration("stream-interval"),
+	}
+
+	startedAt := time.Now()
+	if err := s.run(ctx, cmd.Reader); err != nil {
+		return fmt.Errorf("failed to stream to chat ID %s: %w", s.chatID, forgetStaleTopic(err))
+	}
+
+	if cmd.Bool("verbose") {
+		_, _ = fmt.Fprintf(
+			cmd.Writer,
+			"Stream sent successfully. Chat ID: %s. Lines: %d. Messages: %d. Duration: %s\n",
+			s.chatID,
+			s.total,
+			s.messages,
+			time.Since(startedAt).Round(time.Millisecond),
+		)
+	}
+
+	return nil
+}
+
+// logStream mirrors a line-oriented input into Telegram. The current message
+// shows the latest lines in a <pre> block and is edited at most once per
+// interval. When the block would exceed the message length limit, the current
+// message keeps its last state and a new message is started.
+type logStream struct {
+	client    *telegram.Client
+	warnings  io.Writer
+	chatID    string
+	threadID  string
+	silent    bool
+	protect   bool
+	maxLines  int
+	interval  time.Duration
+	lines     []string
+	messageID int
+	dirty     bool
+	failures  int
+	total     int
+	messages  int
+}
+
+type streamLine struct {
+	text string
+	err  error
+}
+
+// run returns once r is exhausted or ctx is cancelled. Either way the latest
+// lines are flushed and a final status message is posted.
+func (s *logStream) run(ctx context.Context, r io.Reader) error {
+	startedAt := time.Now()
+
+	done := make(chan struct{})
+	defer close(done)
+	input := readStreamLines(r, done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			reportCtx := context.WithoutCancel(ctx)
+
+			return errors.Join(
+				ctx.Err(),
+				s.finish(reportCtx, fmt.Sprintf("Stream interrupted after %s.", s.linesLabel())),
+			)
+		case <-ticker.C:
+			if err := s.flush(ctx); err != nil {
+				return err
+			}
+		case line, ok := <-input:
+			if !ok {
+				return s.finish(ctx, fmt.Sprintf(
+					"Stream ended: %s in %s.",
+					s.linesLabel(),
+					time.Since(startedAt).Round(time.Second),
+				))
+			}
+			if line.err != nil {
+				return errors.Join(
+					fmt.Errorf("read stdin: %w", line.err),
+					s.finish(ctx, fmt.Sprintf("Stream failed after %s.", s.linesLabel())),
+				)
+			}
+
+			if err := s.add(ctx, line.text); err != nil {
+				return err
+			}
+			// The first lines of every message are posted right away. Only
+			// edits wait for the ticker.
+			if s.messageID == 0 {
+				if err := s.flush(ctx); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// readStreamLines reads in its own goroutine because a blocked read on stdin
+// cannot be cancelled. done releases the goroutine once run returns.
+func readStreamLines(r io.Reader, done <-chan struct{}) <-chan streamLine {
+	input := make(chan streamLine)
+
+	go func() {
+		defer close(input)
+
+		reader := bufio.NewReader(r)
+		for {
+			text, err := reader.ReadString('\n')
+			if text != "" {
+				text = strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r")
+				select {
+				case input <- streamLine{text: text}:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					select {
+					case input <- streamLine{err: err}:
+					case <-done:
+					}
+				}
+
+				return
+			}
+		}
+	}()
+
+	return input
+}
+
+func (s *logStream) add(ctx context.Context, line string) error {
+	s.total++
+	line = entity.TruncateUTF16(line, sendmessage.MaxTextLength)
+
+	lines := append(s.lines, line)
+	if s.maxLines > 0 && len(lines) > s.maxLines {
+		lines = lines[len(lines)-s.maxLines:]
+	}
+
+	if entity.UTF16Len(strings.Join(lines, "\n")) > sendmessage.MaxTextLength && len(s.lines) > 0 {
+		if err := s.rollover(ctx); err != nil {
+			return err
+		}
+		lines = []string{line}
+	}
+
+	s.lines = lines
+	s.dirty = true
+
+	return nil
+}
+
+// rollover brings the current message up to date and leaves it behind, so the
+// next line starts a new message. The left message is never edited again, so
+// a failed final edit is reported instead of retried.
+func (s *logStream) rollover(ctx context.Context) error {
+	if s.messageID == 0 {
+		if err := s.flush(ctx); err != nil {
+			return err
+		}
+	} else if err := s.edit(ctx); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("update stream message: %w", err)
+		}
+		_, _ = fmt.Fprintf(s.warnings, "warning: the stream message is missing its last lines: %v\n", err)
+	}
+
+	s.messageID = 0
+	s.failures = 0
+
+	return nil
+}
+
+// render returns the current lines as a <pre> block, or false when there is
+// no visible text, which Telegram rejects. Telegram counts the length of the
+// text inside the block, not the markup.
+func (s *logStream) render() (string, bool) {
+	content := strings.Join(s.lines, "\n")
+	if strings.TrimSpace(content) == "" {
+		return "", false
+	}
+
+	return "<pre>" + html.EscapeString(content) + "</pre>", true
+}
+
+// edit replaces the text of the current message when it is out of date.
+func (s *logStream) edit(ctx context.Context) error {
+	text, ok := s.render()
+	if !s.dirty || !ok {
+		return nil
+	}
+
+	err := s.client.EditMessageText.Edit(ctx, &editmessagetext.Options{
+		ChatID:             s.chatID,
+		MessageID:          s.messageID,
+		Text:               text,
+		ParseMode:          "html",
+		DisableLinkPreview: true,
+	})
+	if err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		return err
+	}
+	s.dirty = false
+
+	return nil
+}
+
+// flush posts or edits the current message when it is out of date.
+func (s *logStream) flush(ctx context.Context) error {
+	text, ok := s.render()
+	if !s.dirty || !ok {
+		return nil
+	}
+
+	if s.messageID == 0 {
+		sent, err := s.client.SendMessage.Send(ctx, &sendmessage.Options{
+			ChatID:              s.chatID,
+			MessageThreadID:     s.threadID,
+			Text:                text,
+			ParseMode:           "html",
+			DisableNotification: s.silent,
+			ProtectContent:      s.protect,
+			DisableLinkPreview:  true,
+		})
+		if err != nil {
+			return err
+		}
+		if sent.MessageID == 0 {
+			return errors.New("telegram did not return the ID of the stream message")
+		}
+		s.messageID = sent.MessageID
+		s.messages++
+		s.dirty = false
+
+		return nil
+	}
+
+	if err := s.edit(ctx); err != nil {
+		s.failures++
+		if s.failures >= maxStreamEditFailures || ctx.Err() != nil {
+			return fmt.Errorf("update stream message: %w", err)
+		}
+		_, _ = fmt.Fprintf(s.warnings, "warning: failed to update the stream message, will retry: %v\n", err)
+
+		return nil
+	}
+	s.failures = 0
+
+	return nil
+}
+
+// finish shows the last lines and posts the status as a separate message, so
+// that the chat is notified when the stream ends even though edits are silent.
+func (s *logStream) finish(ctx context.Context, status string) error {
+	// Ignore the retry budget: this is the last chance to show the final lines.
+	s.failures = 0
+	flushErr := s.flush(ctx)
+
+	_, err := s.client.SendMessage.Send(ctx, &sendmessage.Options{
+		ChatID:              s.chatID,
+		MessageThreadID:     s.threadID,
+		Text:                status,
+		DisableNotification: s.silent,
+		ProtectContent:      s.protect,
+	})
+	if err != nil {
+		err = fmt.Errorf("send stream status: %w", err)
+	}
+
+	return errors.Join(flushErr, err)
+}
+
+func (s *logStream) linesLabel() string {
+	if s.total == 1 {
+		return "1 line"
+	}
+
+	return fmt.Sprintf("%d lines", s.total)
+}
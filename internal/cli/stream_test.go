@@ -0,0 +1,91 @@
+package cli //nolint:testpackage // Drive the unexported stream with a deterministic reader.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram"
+)
+
+type streamCall struct {
+	Method    string
+	MessageID int    `json:"message_id"`
+	Text      string `json:"text"`
+}
+
+func newStreamServer(t *testing.T) (*telegram.Client, *[]streamCall) {
+	t.Helper()
+
+	var calls []streamCall
+	nextID := 100
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var call streamCall
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&call))
+		call.Method = path.Base(r.URL.Path)
+		calls = append(calls, call)
+
+		w.Header().Set("Content-Type", "application/json")
+		if call.Method == "sendMessage" {
+			nextID++
+			_, _ = fmt.Fprintf(w, `{"ok":true,"result":{"message_id":%d}}`, nextID)
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := telegram.NewClient(server.URL, "token", "")
+	require.NoError(t, err)
+
+	return client, &calls
+}
+
+func TestLogStream_RollsOverBeforeLengthLimit(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newStreamServer(t)
+	s := &logStream{client: client, chatID: "123", interval: time.Hour}
+
+	// Four 1000-character lines fit in one message, the fifth does not.
+	line := strings.Repeat("x", 1000)
+	input := strings.Repeat(line+"\n", 9)
+
+	require.NoError(t, s.run(t.Context(), strings.NewReader(input)))
+
+	pre := func(n int) string {
+		return "<pre>" + strings.Repeat(line+"\n", n-1) + line + "</pre>"
+	}
+	require.Len(t, *calls, 6)
+	assert.Equal(t, streamCall{Method: "sendMessage", Text: pre(1)}, (*calls)[0])
+	assert.Equal(t, streamCall{Method: "editMessageText", MessageID: 101, Text: pre(4)}, (*calls)[1])
+	assert.Equal(t, streamCall{Method: "sendMessage", Text: pre(1)}, (*calls)[2])
+	assert.Equal(t, streamCall{Method: "editMessageText", MessageID: 102, Text: pre(4)}, (*calls)[3])
+	assert.Equal(t, streamCall{Method: "sendMessage", Text: pre(1)}, (*calls)[4])
+	assert.Equal(t, "sendMessage", (*calls)[5].Method)
+	assert.Contains(t, (*calls)[5].Text, "Stream ended: 9 lines")
+	assert.Equal(t, 3, s.messages)
+}
+
+func TestLogStream_KeepsLatestLines(t *testing.T) {
+	t.Parallel()
+
+	client, calls := newStreamServer(t)
+	s := &logStream{client: client, chatID: "123", maxLines: 2, interval: time.Hour}
+
+	require.NoError(t, s.run(t.Context(), strings.NewReader("one\r\n\ntwo & <three>\nfour")))
+
+	require.Len(t, *calls, 3)
+	assert.Equal(t, "<pre>one</pre>", (*calls)[0].Text)
+	assert.Equal(t, "<pre>two &amp; &lt;three&gt;\nfour</pre>", (*calls)[1].Text)
+	assert.Contains(t, (*calls)[2].Text, "Stream ended: 4 lines")
+}
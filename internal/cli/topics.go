@@ -0,0 +1,407 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/beeyev/telegram-owl/internal/telegram"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/closeforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/createforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/deleteforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/reopenforumtopic"
+	"github.com/beeyev/telegram-owl/internal/topiccache"
+)
+
+const topicsUsageText = `Examples:
+  telegram-owl topics create -t $TOKEN -c @forumgroup --name Deployments --icon-color green
+  telegram-owl topics edit -t $TOKEN -c @forumgroup --topic Deployments --name Releases
+  telegram-owl topics close -t $TOKEN -c @forumgroup --thread 11`
+
+// errTopicNotFound is returned when a topic name is neither cached nor
+// visible in pending updates.
+var errTopicNotFound = errors.New("topic not found")
+
+// topicFlag names a forum topic instead of passing its numeric --thread ID.
+func topicFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:     "topic",
+		Usage:    "Forum topic name, resolved to its thread ID and cached locally. environment variable:",
+		OnlyOnce: true,
+		Local:    true,
+		Sources:  cli.EnvVars("TELEGRAM_OWL_TOPIC"),
+		Config:   cli.StringConfig{TrimSpace: true},
+	}
+}
+
+// cacheDirFlag is persistent so every command that caches lookups shares it.
+func cacheDirFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:      "cache-dir",
+		Usage:     "Directory for cached topic and file IDs (default: user cache directory). environment variable:",
+		OnlyOnce:  true,
+		Sources:   cli.EnvVars("TELEGRAM_OWL_CACHE_DIR"),
+		Config:    cli.StringConfig{TrimSpace: true},
+		TakesFile: true,
+	}
+}
+
+// cacheDir resolves --cache-dir, falling back to a telegram-owl directory in
+// the platform's user cache location.
+func cacheDir(cmd *cli.Command) (string, error) {
+	if dir := cmd.String("cache-dir"); dir != "" {
+		return dir, nil
+	}
+
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locate cache directory, set --cache-dir instead: %w", err)
+	}
+
+	return filepath.Join(userCacheDir, "telegram-owl"), nil
+}
+
+func (iv *inputValues) validateTopic() error {
+	topic := iv.cmd.String("topic")
+
+	if topic != "" && iv.cmd.String("thread") != "" {
+		return errors.New("--topic and --thread cannot be used together")
+	}
+	if iv.cmd.Bool("create-topic") && topic == "" {
+		return errors.New("--create-topic requires --topic")
+	}
+	hasIcon := iv.cmd.String("topic-icon-color") != "" || iv.cmd.String("topic-icon-emoji") != ""
+	if hasIcon && !iv.cmd.Bool("create-topic") {
+		return errors.New("--topic-icon-color and --topic-icon-emoji require --create-topic")
+	}
+
+	return nil
+}
+
+// topicResolver maps topic names to thread IDs for one bot and chat.
+type topicResolver struct {
+	client *telegram.Client
+	store  *topiccache.Store
+	key    topiccache.Key
+}
+
+func newTopicResolver(cmd *cli.Command, client *telegram.Client) (*topicResolver, error) {
+	dir, err := cacheDir(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// The numeric prefix of a token is the bot's user ID. Keying the cache by
+	// it keeps the secret part of the token out of the cache file.
+	botID, _, _ := strings.Cut(cmd.String("token"), ":")
+
+	return &topicResolver{
+		client: client,
+		store:  topiccache.New(dir),
+		key:    topiccache.Key{BotID: botID, ChatID: cmd.String("chat")},
+	}, nil
+}
+
+// resolve looks name up in the cache, then in pending updates, where topic
+// creation and rename service messages carry names. Found IDs are cached.
+// fromCache reports whether the ID may be stale.
+func (r *topicResolver) resolve(ctx context.Context, name string) (threadID int, fromCache bool, err error) {
+	threadID, found, err := r.store.Lookup(r.key, name)
+	if err != nil {
+		return 0, false, err
+	}
+	if found {
+		return threadID, true, nil
+	}
+
+	updates, err := pollUpdates(ctx, r.client.GetUpdates, false)
+	if err != nil {
+		return 0, false, fmt.Errorf("look up topic %q in pending updates: %w", name, err)
+	}
+
+	for _, chat := range discoverChats(updates) {
+		if !chatMatches(chat, r.key.ChatID) {
+			continue
+		}
+		for _, topic := range chat.Topics {
+			if topic.Name == name {
+				return topic.ID, false, r.store.Save(r.key, name, topic.ID)
+			}
+		}
+	}
+
+	return 0, false, fmt.Errorf("%w: %q in chat %s", errTopicNotFound, name, r.key.ChatID)
+}
+
+func (r *topicResolver) create(ctx context.Context, opts *createforumtopic.Options) (int, error) {
+	topic, err := r.client.CreateForumTopic.Create(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return topic.MessageThreadID, r.store.Save(r.key, topic.Name, topic.MessageThreadID)
+}
+
+// resolveOrCreate is used by the send action. With create set, a topic that is
+// not found is created, as is one that cannot be looked up because the bot has
+// a webhook and so cannot read updates; the created topic is cached for later
+// runs anyway. Other lookup errors are returned, so a failing cache or network
+// never creates a duplicate topic.
+func (r *topicResolver) resolveOrCreate(
+	ctx context.Context,
+	name string,
+	create bool,
+	iconColor, iconEmoji string,
+) (threadID int, fromCache bool, err error) {
+	threadID, fromCache, err = r.resolve(ctx, name)
+	if err == nil || !create || !errors.Is(err, errTopicNotFound) && !isWebhookActive(err) {
+		if errors.Is(err, errTopicNotFound) {
+			err = fmt.Errorf("%w; add --create-topic to create it, or pass its ID with --thread", err)
+		}
+
+		return threadID, fromCache, err
+	}
+
+	threadID, err = r.create(ctx, &createforumtopic.Options{
+		ChatID:            r.key.ChatID,
+		Name:              name,
+		IconColor:         iconColor,
+		IconCustomEmojiID: iconEmoji,
+	})
+	if err != nil {
+		return 0, false, fmt.Errorf("create topic %q: %w", name, err)
+	}
+
+	return threadID, false, nil
+}
+
+// chatMatches compares a discovered chat with a --chat value, which may be a
+// numeric ID or an @username.
+func chatMatches(chat discoveredChat, chatRef string) bool {
+	if username, ok := strings.CutPrefix(chatRef, "@"); ok {
+		return strings.EqualFold(chat.Username, username)
+	}
+
+	return strconv.FormatInt(chat.ID, 10) == chatRef
+}
+
+// isThreadNotFound recognizes Telegram's error for a deleted or unknown topic.
+func isThreadNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "message thread not found")
+}
+
+// isWebhookActive recognizes Telegram's refusal to return updates to a bot
+// that has a webhook set.
+func isWebhookActive(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "webhook is active")
+}
+
+// topicsCommand manages forum topics. Subcommands keep the topic cache in step
+// with the changes they make.
+func topicsCommand(apiBotURL string) *cli.Command {
+	return &cli.Command{
+		Name:      "topics",
+		Usage:     "Create, edit, close, reopen, or delete forum topics.",
+		UsageText: topicsUsageText,
+		Commands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create a topic and print its thread ID.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "Topic name (required).",
+						OnlyOnce: true,
+					},
+					&cli.StringFlag{
+						Name: "icon-color",
+						Usage: "Icon color: " + strings.Join(createforumtopic.IconColorNames(), ", ") +
+							", or its RGB value.",
+						OnlyOnce: true,
+					},
+					&cli.StringFlag{
+						Name:     "icon-emoji",
+						Usage:    "Custom emoji ID for the topic icon.",
+						OnlyOnce: true,
+					},
+				},
+				Action: topicAction(apiBotURL, func(ctx context.Context, cmd *cli.Command, r *topicResolver) error {
+					threadID, err := r.create(ctx, &createforumtopic.Options{
+						ChatID:            r.key.ChatID,
+						Name:              cmd.String("name"),
+						IconColor:         cmd.String("icon-color"),
+						IconCustomEmojiID: cmd.String("icon-emoji"),
+					})
+					if err != nil {
+						return fmt.Errorf("failed to create topic in chat ID %s: %w", r.key.ChatID, err)
+					}
+
+					// The bare ID can be captured by a shell and passed to --thread.
+					_, err = fmt.Fprintln(cmd.Writer, threadID)
+
+					return err
+				}),
+			},
+			{
+				Name:  "edit",
+				Usage: "Rename a topic or change its icon.",
+				Flags: []cli.Flag{
+					topicFlag(),
+					&cli.StringFlag{
+						Name:     "name",
+						Usage:    "New topic name.",
+						OnlyOnce: true,
+					},
+					&cli.StringFlag{
+						Name:     "icon-emoji",
+						Usage:    "New custom emoji ID for the topic icon.",
+						OnlyOnce: true,
+					},
+				},
+				Action: topicAction(apiBotURL, func(ctx context.Context, cmd *cli.Command, r *topicResolver) error {
+					threadID, err := topicThreadID(ctx, cmd, r)
+					if err != nil {
+						return err
+					}
+
+					name := cmd.String("name")
+					err = r.client.EditForumTopic.Edit(ctx, &editforumtopic.Options{
+						ChatID:            r.key.ChatID,
+						MessageThreadID:   threadID,
+						Name:              name,
+						IconCustomEmojiID: cmd.String("icon-emoji"),
+					})
+					if err != nil {
+						return fmt.Errorf("failed to edit topic %d in chat ID %s: %w", threadID, r.key.ChatID, err)
+					}
+					if name != "" {
+						if err = r.store.Save(r.key, name, threadID); err != nil {
+							return err
+						}
+					}
+
+					return printTopicVerbose(cmd, threadID, "edited")
+				}),
+			},
+			topicStateCommand(apiBotURL, "close", "Close a topic to new messages.", "closed",
+				func(ctx context.Context, client *telegram.Client, chatID string, threadID int) error {
+					return client.CloseForumTopic.Close(
+						ctx,
+						&closeforumtopic.Options{ChatID: chatID, MessageThreadID: threadID},
+					)
+				}),
+			topicStateCommand(apiBotURL, "reopen", "Reopen a closed topic.", "reopened",
+				func(ctx context.Context, client *telegram.Client, chatID string, threadID int) error {
+					return client.ReopenForumTopic.Reopen(
+						ctx,
+						&reopenforumtopic.Options{ChatID: chatID, MessageThreadID: threadID},
+					)
+				}),
+			topicStateCommand(apiBotURL, "delete", "Delete a topic and all of its messages.", "deleted",
+				func(ctx context.Context, client *telegram.Client, chatID string, threadID int) error {
+					return client.DeleteForumTopic.Delete(
+						ctx,
+						&deleteforumtopic.Options{ChatID: chatID, MessageThreadID: threadID},
+					)
+				}),
+		},
+	}
+}
+
+// topicStateCommand builds the close, reopen, and delete subcommands, which
+// differ only in the method they call.
+func topicStateCommand(
+	apiBotURL, name, usage, pastTense string,
+	apply func(ctx context.Context, client *telegram.Client, chatID string, threadID int) error,
+) *cli.Command {
+	return &cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: []cli.Flag{topicFlag()},
+		Action: topicAction(apiBotURL, func(ctx context.Context, cmd *cli.Command, r *topicResolver) error {
+			threadID, err := topicThreadID(ctx, cmd, r)
+			if err != nil {
+				return err
+			}
+
+			if err = apply(ctx, r.client, r.key.ChatID, threadID); err != nil {
+				return fmt.Errorf("failed to %s topic %d in chat ID %s: %w", name, threadID, r.key.ChatID, err)
+			}
+			if name == "delete" {
+				if err = r.store.Forget(r.key, threadID); err != nil {
+					return err
+				}
+			}
+
+			return printTopicVerbose(cmd, threadID, pastTense)
+		}),
+	}
+}
+
+// topicAction validates the shared flags and builds the resolver before
+// running a topics subcommand.
+func topicAction(
+	apiBotURL string,
+	run func(ctx context.Context, cmd *cli.Command, r *topicResolver) error,
+) cli.ActionFunc {
+	return func(ctx context.Context, cmd *cli.Command) error {
+		iv := &inputValues{cmd: cmd}
+		if err := iv.validateDestination(); err != nil {
+			return err
+		}
+		if err := iv.validateTopic(); err != nil {
+			return err
+		}
+
+		telegramClient, err := newTelegramClient(apiBotURL, cmd)
+		if err != nil {
+			return err
+		}
+
+		resolver, err := newTopicResolver(cmd, telegramClient)
+		if err != nil {
+			return err
+		}
+
+		return run(ctx, cmd, resolver)
+	}
+}
+
+// topicThreadID reads the target topic from --topic or --thread.
+func topicThreadID(ctx context.Context, cmd *cli.Command, r *topicResolver) (int, error) {
+	if name := cmd.String("topic"); name != "" {
+		threadID, _, err := r.resolve(ctx, name)
+
+		return threadID, err
+	}
+
+	thread := cmd.String("thread")
+	if thread == "" {
+		return 0, errors.New("either --topic or --thread is required")
+	}
+
+	threadID, err := strconv.Atoi(thread)
+	if err != nil || threadID <= 0 {
+		return 0, fmt.Errorf("--thread must be a positive number, got %q", thread)
+	}
+
+	return threadID, nil
+}
+
+func printTopicVerbose(cmd *cli.Command, threadID int, pastTense string) error {
+	if !cmd.Bool("verbose") {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(cmd.Writer, "Topic %s successfully. Chat ID: %s. Thread ID: %d\n",
+		pastTense, cmd.String("chat"), threadID)
+
+	return err
+}
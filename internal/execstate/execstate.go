@@ -0,0 +1,76 @@
+// Package execstate remembers the outcome of the last run of each job wrapped
+// by the exec command, so a notification can be limited to runs whose outcome
+// changed.
+package execstate
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/beeyev/telegram-owl/internal/jsonstore"
+)
+
+// FileName is the state file created inside the cache directory.
+const FileName = "exec.json"
+
+// Key scopes job names to one bot and one chat reference, so the same job
+// reporting to two chats notifies each of them on its own changes.
+type Key struct {
+	BotID  string
+	ChatID string
+}
+
+// Run is the recorded outcome of one job run.
+type Run struct {
+	ExitCode   int       `json:"exit_code"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Succeeded reports whether the run exited with code 0.
+func (r Run) Succeeded() bool {
+	return r.ExitCode == 0
+}
+
+// Store reads and writes the state file on every call. Each run of a job
+// touches it twice at most, so nothing is kept in memory.
+type Store struct {
+	file *jsonstore.File
+}
+
+// entries maps bot ID, then chat ID, then job name to its last run.
+type entries map[string]map[string]map[string]Run
+
+// New returns a store backed by FileName inside dir. The directory is created
+// on the first write.
+func New(dir string) *Store {
+	return &Store{file: jsonstore.New(filepath.Join(dir, FileName), "exec state")}
+}
+
+// Last returns the previous run of job, if one was recorded.
+func (s *Store) Last(key Key, job string) (Run, bool, error) {
+	data := make(entries)
+	if err := s.file.Load(&data); err != nil {
+		return Run{}, false, err
+	}
+
+	run, ok := data[key.BotID][key.ChatID][job]
+
+	return run, ok, nil
+}
+
+// Record replaces the previous run of job.
+func (s *Store) Record(key Key, job string, run Run) error {
+	data := make(entries)
+
+	return s.file.Update(&data, func() error {
+		if data[key.BotID] == nil {
+			data[key.BotID] = make(map[string]map[string]Run)
+		}
+		if data[key.BotID][key.ChatID] == nil {
+			data[key.BotID][key.ChatID] = make(map[string]Run)
+		}
+		data[key.BotID][key.ChatID][job] = run
+
+		return nil
+	})
+}
@@ -0,0 +1,50 @@
+package execstate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/execstate"
+)
+
+func TestStore_LastRecord(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "nested")
+	store := execstate.New(dir)
+	key := execstate.Key{BotID: "123", ChatID: "-1002"}
+
+	_, found, err := store.Last(key, "backup")
+	require.NoError(t, err)
+	assert.False(t, found, "missing state file means no previous run")
+
+	finishedAt := time.Date(2026, 3, 1, 4, 5, 6, 0, time.UTC)
+	require.NoError(t, store.Record(key, "backup", execstate.Run{ExitCode: 2, FinishedAt: finishedAt}))
+	require.NoError(t, store.Record(execstate.Key{BotID: "123", ChatID: "-1003"}, "backup", execstate.Run{}))
+
+	run, found, err := store.Last(key, "backup")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, execstate.Run{ExitCode: 2, FinishedAt: finishedAt}, run)
+	assert.False(t, run.Succeeded())
+
+	require.NoError(t, store.Record(key, "backup", execstate.Run{FinishedAt: finishedAt}))
+	run, _, err = store.Last(key, "backup")
+	require.NoError(t, err)
+	assert.True(t, run.Succeeded())
+}
+
+func TestStore_CorruptFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, execstate.FileName), []byte("{"), 0o600))
+
+	_, _, err := execstate.New(dir).Last(execstate.Key{BotID: "1", ChatID: "2"}, "backup")
+	require.ErrorContains(t, err, "delete the file to reset it")
+}
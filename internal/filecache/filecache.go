@@ -0,0 +1,128 @@
+// Package filecache remembers the file_id Telegram returns for uploaded
+// files, so sending identical content again references the stored file
+// instead of uploading it once more.
+package filecache
+
+import (
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/beeyev/telegram-owl/internal/jsonstore"
+)
+
+// FileName is the cache file created inside the cache directory.
+const FileName = "files.json"
+
+// Key identifies uploaded content for one bot. File IDs are only valid for
+// the bot that received them, and a file_id returned for a photo cannot be
+// sent as a document, so the media type is part of the key.
+type Key struct {
+	BotID     string
+	Type      string
+	SHA256    string
+	SizeBytes int64
+}
+
+// Entry is a cached file_id with the time it was last sent.
+type Entry struct {
+	FileID string    `json:"file_id"`
+	UsedAt time.Time `json:"used_at"`
+}
+
+// Store reads and writes the cache file on every call. Each CLI run touches
+// it a few times at most, once per album, so nothing is kept in memory.
+// Changes from runs that send at the same time are all kept, since updates
+// take a lock on the file.
+type Store struct {
+	file *jsonstore.File
+}
+
+// entries maps bot ID, then "type:sha256:size" to an entry.
+type entries map[string]map[string]Entry
+
+// New returns a store backed by FileName inside dir. The directory is created
+// on the first write.
+func New(dir string) *Store {
+	return &Store{file: jsonstore.New(filepath.Join(dir, FileName), "file cache")}
+}
+
+// Lookup returns the file_id cached for key.
+func (s *Store) Lookup(key Key) (string, bool, error) {
+	data := make(entries)
+	if err := s.file.Load(&data); err != nil {
+		return "", false, err
+	}
+
+	entry, ok := data[key.BotID][key.content()]
+
+	return entry.FileID, ok, nil
+}
+
+// Save records the file IDs of one sent album, marking them used at usedAt.
+func (s *Store) Save(fileIDs map[Key]string, usedAt time.Time) error {
+	if len(fileIDs) == 0 {
+		return nil
+	}
+
+	data := make(entries)
+
+	return s.file.Update(&data, func() error {
+		for key, fileID := range fileIDs {
+			if data[key.BotID] == nil {
+				data[key.BotID] = make(map[string]Entry)
+			}
+			data[key.BotID][key.content()] = Entry{FileID: fileID, UsedAt: usedAt.UTC()}
+		}
+
+		return nil
+	})
+}
+
+// Forget drops the given keys, for example after Telegram rejected their file
+// IDs.
+func (s *Store) Forget(keys ...Key) error {
+	data := make(entries)
+
+	return s.file.Update(&data, func() error {
+		for _, key := range keys {
+			delete(data[key.BotID], key.content())
+		}
+
+		return nil
+	})
+}
+
+// Prune drops entries last used before cutoff and returns how many were
+// dropped. A zero cutoff drops everything.
+func (s *Store) Prune(cutoff time.Time) (int, error) {
+	data := make(entries)
+	pruned := 0
+	err := s.file.Update(&data, func() error {
+		for botID, files := range data {
+			for content, entry := range files {
+				if cutoff.IsZero() || entry.UsedAt.Before(cutoff) {
+					delete(files, content)
+					pruned++
+				}
+			}
+			if len(files) == 0 {
+				delete(data, botID)
+			}
+		}
+		if pruned == 0 {
+			return jsonstore.ErrUnchanged
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+func (k Key) content() string {
+	return k.Type + ":" + k.SHA256 + ":" + strconv.FormatInt(k.SizeBytes, 10)
+}
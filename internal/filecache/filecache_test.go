@@ -0,0 +1,87 @@
+package filecache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/filecache"
+)
+
+func TestStore_SaveLookupForget(t *testing.T) {
+	t.Parallel()
+
+	store := filecache.New(filepath.Join(t.TempDir(), "nested"))
+	logo := filecache.Key{BotID: "123", Type: "photo", SHA256: "abc", SizeBytes: 10}
+	report := filecache.Key{BotID: "123", Type: "document", SHA256: "def", SizeBytes: 20}
+
+	_, found, err := store.Lookup(logo)
+	require.NoError(t, err)
+	assert.False(t, found, "missing cache file means an empty cache")
+
+	require.NoError(t, store.Save(map[filecache.Key]string{logo: "logo-id", report: "report-id"}, time.Now()))
+
+	fileID, found, err := store.Lookup(logo)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "logo-id", fileID)
+
+	// The same content under another bot or media type is a different file.
+	for _, other := range []filecache.Key{
+		{BotID: "456", Type: "photo", SHA256: "abc", SizeBytes: 10},
+		{BotID: "123", Type: "document", SHA256: "abc", SizeBytes: 10},
+	} {
+		_, found, err = store.Lookup(other)
+		require.NoError(t, err)
+		assert.False(t, found, other)
+	}
+
+	require.NoError(t, store.Forget(logo))
+	_, found, err = store.Lookup(logo)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	fileID, _, err = store.Lookup(report)
+	require.NoError(t, err)
+	assert.Equal(t, "report-id", fileID)
+}
+
+func TestStore_Prune(t *testing.T) {
+	t.Parallel()
+
+	store := filecache.New(t.TempDir())
+	now := time.Now()
+	old := filecache.Key{BotID: "1", Type: "photo", SHA256: "old", SizeBytes: 1}
+	recent := filecache.Key{BotID: "1", Type: "photo", SHA256: "recent", SizeBytes: 1}
+	require.NoError(t, store.Save(map[filecache.Key]string{old: "old-id"}, now.Add(-48*time.Hour)))
+	require.NoError(t, store.Save(map[filecache.Key]string{recent: "recent-id"}, now))
+
+	pruned, err := store.Prune(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+
+	_, found, err := store.Lookup(old)
+	require.NoError(t, err)
+	assert.False(t, found)
+	_, found, err = store.Lookup(recent)
+	require.NoError(t, err)
+	assert.True(t, found)
+
+	pruned, err = store.Prune(time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+}
+
+func TestStore_CorruptFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, filecache.FileName), []byte("{"), 0o600))
+
+	_, _, err := filecache.New(dir).Lookup(filecache.Key{BotID: "1"})
+	require.ErrorContains(t, err, "delete the file to reset it")
+}
@@ -0,0 +1,153 @@
+// Package jsonstore reads and atomically replaces the small JSON files the
+// local caches keep in the cache directory.
+package jsonstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// lockTimeout bounds the wait for another run's update to finish.
+	lockTimeout = 15 * time.Second
+	// staleLockAge is the age after which a lock is assumed to be left by a
+	// run that was killed. Updates take milliseconds.
+	staleLockAge = 10 * time.Second
+	// lockRetryInterval is the pause between attempts to take the lock.
+	lockRetryInterval = 20 * time.Millisecond
+)
+
+// ErrUnchanged is returned by an Update callback that left the value as it
+// was, so the file is not rewritten.
+var ErrUnchanged = errors.New("unchanged")
+
+// File is one JSON file. Its description, such as "topic cache", names it in
+// errors.
+type File struct {
+	path        string
+	description string
+}
+
+// New returns the file at path. Its directory is created on the first write.
+func New(path, description string) *File {
+	return &File{path: path, description: description}
+}
+
+// Path returns the location of the file.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Load decodes the file into v. A missing file leaves v as it is, so callers
+// pass an empty value for an empty store.
+func (f *File) Load(v any) error {
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", f.description, err)
+	}
+
+	if err = json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("read %s %s: %w; delete the file to reset it", f.description, f.path, err)
+	}
+
+	return nil
+}
+
+// Update loads the file into v, lets modify change it, and saves the result.
+// Runs started at the same time, such as parallel cron jobs, update the file
+// one after another through a lock file next to it, so no run loses another
+// run's changes.
+func (f *File) Update(v any, modify func() error) error {
+	unlock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err = f.Load(v); err != nil {
+		return err
+	}
+	if err = modify(); errors.Is(err, ErrUnchanged) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return f.Save(v)
+}
+
+// lock creates the lock file, waiting while another run holds it, and returns
+// the function that removes it.
+func (f *File) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return nil, fmt.Errorf("create cache directory: %w", err)
+	}
+
+	lockPath := f.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			_ = lockFile.Close()
+
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("lock %s: %w", f.description, err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			_ = os.Remove(lockPath)
+
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf(
+				"lock %s: %s is held by another run; delete it if no run is active", f.description, lockPath,
+			)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Save replaces the file atomically, so a concurrent run never reads a
+// partially written file.
+func (f *File) Save(v any) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", f.description, err)
+	}
+
+	dir := filepath.Dir(f.path)
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, filepath.Base(f.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", f.description, err)
+	}
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	if _, err = tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+
+		return fmt.Errorf("write %s: %w", f.description, err)
+	}
+	if err = tmpFile.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", f.description, err)
+	}
+	if err = os.Rename(tmpFile.Name(), f.path); err != nil {
+		return fmt.Errorf("write %s: %w", f.description, err)
+	}
+
+	return nil
+}
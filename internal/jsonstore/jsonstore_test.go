@@ -0,0 +1,113 @@
+package jsonstore_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/jsonstore"
+)
+
+func TestFile_LoadUpdate(t *testing.T) {
+	t.Parallel()
+
+	file := jsonstore.New(filepath.Join(t.TempDir(), "nested", "state.json"), "test state")
+
+	data := map[string]int{}
+	require.NoError(t, file.Load(&data))
+	assert.Empty(t, data, "missing file means an empty store")
+
+	require.NoError(t, file.Update(&data, func() error {
+		data["runs"] = 1
+
+		return nil
+	}))
+
+	data = map[string]int{}
+	require.NoError(t, file.Update(&data, func() error {
+		data["runs"]++
+
+		return nil
+	}))
+
+	loaded := map[string]int{}
+	require.NoError(t, file.Load(&loaded))
+	assert.Equal(t, map[string]int{"runs": 2}, loaded)
+
+	entries, err := os.ReadDir(filepath.Dir(file.Path()))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary files are removed")
+}
+
+func TestFile_UpdateFailure(t *testing.T) {
+	t.Parallel()
+
+	file := jsonstore.New(filepath.Join(t.TempDir(), "state.json"), "test state")
+
+	data := map[string]int{}
+	err := file.Update(&data, func() error { return errors.New("boom") })
+	require.EqualError(t, err, "boom")
+
+	_, err = os.Stat(file.Path())
+	assert.ErrorIs(t, err, os.ErrNotExist, "a failed update writes nothing")
+}
+
+func TestFile_CorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+
+	err := jsonstore.New(path, "test state").Load(&map[string]int{})
+	require.ErrorContains(t, err, "read test state "+path)
+	require.ErrorContains(t, err, "delete the file to reset it")
+}
+
+func TestFile_ConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	const runs = 20
+	var wg sync.WaitGroup
+	for range runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Each run opens the file on its own, as separate processes do.
+			file := jsonstore.New(path, "test state")
+			data := map[string]int{}
+			assert.NoError(t, file.Update(&data, func() error {
+				data["runs"]++
+
+				return nil
+			}))
+		}()
+	}
+	wg.Wait()
+
+	loaded := map[string]int{}
+	require.NoError(t, jsonstore.New(path, "test state").Load(&loaded))
+	assert.Equal(t, runs, loaded["runs"])
+
+	_, err := os.Stat(path + ".lock")
+	assert.ErrorIs(t, err, os.ErrNotExist, "the lock is released")
+}
+
+func TestFile_UnchangedIsNotWritten(t *testing.T) {
+	t.Parallel()
+
+	file := jsonstore.New(filepath.Join(t.TempDir(), "state.json"), "test state")
+
+	data := map[string]int{}
+	require.NoError(t, file.Update(&data, func() error { return jsonstore.ErrUnchanged }))
+
+	_, err := os.Stat(file.Path())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,150 @@
+package msgtemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// statusEmoji maps the status words of CI systems and job runners to an icon.
+var statusEmoji = map[string]string{
+	"success":     "✅",
+	"succeeded":   "✅",
+	"successful":  "✅",
+	"passed":      "✅",
+	"pass":        "✅",
+	"ok":          "✅",
+	"done":        "✅",
+	"completed":   "✅",
+	"failure":     "❌",
+	"failed":      "❌",
+	"fail":        "❌",
+	"error":       "❌",
+	"errored":     "❌",
+	"broken":      "❌",
+	"cancelled":   "🚫",
+	"canceled":    "🚫",
+	"aborted":     "🚫",
+	"skipped":     "⏭",
+	"neutral":     "⏭",
+	"running":     "⏳",
+	"in_progress": "⏳",
+	"pending":     "⏳",
+	"queued":      "⏳",
+	"waiting":     "⏳",
+	"started":     "⏳",
+	"warning":     "⚠️",
+	"unstable":    "⚠️",
+	"degraded":    "⚠️",
+}
+
+// truncate shortens v to at most n characters, ending with "…" when anything
+// was cut.
+func truncate(n int, v any) string {
+	runes := []rune(fmt.Sprint(v))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	if n <= 0 {
+		return ""
+	}
+
+	return string(runes[:n-1]) + "…"
+}
+
+// duration formats a time.Duration, a Go duration string such as "90s" or a
+// number of seconds, rounded to what a reader cares about.
+func duration(v any) (string, error) {
+	d, err := toDuration(v)
+	if err != nil {
+		return "", err
+	}
+	if d.Abs() >= time.Second {
+		return d.Round(time.Second).String(), nil
+	}
+
+	return d.Round(time.Millisecond).String(), nil
+}
+
+func toDuration(v any) (time.Duration, error) {
+	switch v := v.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		if d, err := time.ParseDuration(strings.TrimSpace(v)); err == nil {
+			return d, nil
+		}
+	}
+
+	seconds, err := toFloat(v)
+	if err != nil {
+		return 0, fmt.Errorf("duration: %w, expected a Go duration or a number of seconds", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// date formats a time.Time, an RFC 3339 string or a Unix timestamp in seconds
+// with a Go layout such as "2006-01-02 15:04". Unix timestamps are shown in UTC.
+func date(layout string, v any) (string, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(layout), nil
+	}
+	if s, ok := v.(string); ok {
+		if t, err := time.Parse(time.RFC3339, strings.TrimSpace(s)); err == nil {
+			return t.Format(layout), nil
+		}
+	}
+
+	seconds, err := toFloat(v)
+	if err != nil {
+		return "", fmt.Errorf("date: %w, expected an RFC 3339 time or a Unix timestamp", err)
+	}
+
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC().Format(layout), nil
+}
+
+// status returns the icon for a status word or a process exit code.
+func status(v any) string {
+	word := strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+	if icon, ok := statusEmoji[word]; ok {
+		return icon
+	}
+	if code, err := strconv.Atoi(word); err == nil {
+		if code == 0 {
+			return "✅"
+		}
+
+		return "❌"
+	}
+
+	return "❔"
+}
+
+func toFloat(v any) (float64, error) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case json.Number:
+		return parseFloat(v.String())
+	case string:
+		return parseFloat(v)
+	default:
+		return 0, fmt.Errorf("cannot use %T", v)
+	}
+}
+
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot use %q", s)
+	}
+
+	return f, nil
+}
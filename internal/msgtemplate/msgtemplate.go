@@ -0,0 +1,51 @@
+// Package msgtemplate renders message text as a Go text/template, so scripts
+// can fill in values without breaking the markup of the message format.
+package msgtemplate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/escape"
+)
+
+// Funcs returns the helpers available in templates. The escape helpers follow
+// the CLI format, so the same template works for plain text, markdown and html:
+//
+//	{{ .branch | escape }}            text
+//	{{ .error | escapeCode }}         inside code and pre
+//	{{ .url | escapeURL }}            inside a link URL
+//	{{ .log | truncate 200 }}         at most 200 characters
+//	{{ .seconds | duration }}         "1m30s" from seconds or a Go duration
+//	{{ now | date "2006-01-02" }}     a time, RFC 3339 string or Unix timestamp
+//	{{ .status | statusEmoji }}       "✅" for success, "❌" for failure, ...
+func Funcs(format string) template.FuncMap {
+	return template.FuncMap{
+		"escape":      func(v any) string { return escape.Text(format, fmt.Sprint(v)) },
+		"escapeCode":  func(v any) string { return escape.Code(format, fmt.Sprint(v)) },
+		"escapeURL":   func(v any) string { return escape.URL(format, fmt.Sprint(v)) },
+		"truncate":    truncate,
+		"duration":    duration,
+		"date":        date,
+		"now":         time.Now,
+		"statusEmoji": status,
+	}
+}
+
+// Render executes text as a template over data. A reference to a missing
+// variable is an error rather than "<no value>" in the message.
+func Render(text, format string, data map[string]any) (string, error) {
+	tmpl, err := template.New("message").Option("missingkey=error").Funcs(Funcs(format)).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse message template: %w", err)
+	}
+
+	var b strings.Builder
+	if err = tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render message template: %w", err)
+	}
+
+	return b.String(), nil
+}
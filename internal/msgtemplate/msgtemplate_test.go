@@ -0,0 +1,124 @@
+package msgtemplate_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/msgtemplate"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{"branch": "fix/login-v1.2", "url": "https://ci.example.com/run(1)", "log": "a`b"}
+
+	tests := []struct {
+		name   string
+		text   string
+		format string
+		want   string
+	}{
+		{
+			name:   "markdown",
+			text:   "*Build* {{ .branch | escape }} [log]({{ .url | escapeURL }}) `{{ .log | escapeCode }}`",
+			format: "markdown",
+			want:   "*Build* fix/login\\-v1\\.2 [log](https://ci.example.com/run(1\\)) `a\\`b`",
+		},
+		{
+			name:   "html",
+			text:   `<b>Build</b> {{ .branch | escape }} <a href="{{ .url | escapeURL }}">log</a>`,
+			format: "html",
+			want:   `<b>Build</b> fix/login-v1.2 <a href="https://ci.example.com/run(1)">log</a>`,
+		},
+		{
+			name: "plain",
+			text: "Build {{ .branch | escape }}",
+			want: "Build fix/login-v1.2",
+		},
+		{
+			name:   "unescaped values are kept as is",
+			text:   "{{ .branch }}",
+			format: "markdown",
+			want:   "fix/login-v1.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := msgtemplate.Render(tt.text, tt.format, data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRender_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := msgtemplate.Render("{{ .missing }}", "", map[string]any{})
+	require.ErrorContains(t, err, `render message template`)
+	require.ErrorContains(t, err, `map has no entry for key "missing"`)
+
+	_, err = msgtemplate.Render("{{ .broken", "", nil)
+	require.ErrorContains(t, err, "parse message template")
+}
+
+func TestRender_Helpers(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"log":      "0123456789",
+		"seconds":  json.Number("95.4"),
+		"elapsed":  "1500ms",
+		"fast":     0.25,
+		"started":  "2026-03-01T10:30:00+02:00",
+		"unix":     json.Number("1767225600"),
+		"status":   "Failed",
+		"exitCode": 0,
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "truncate", text: "{{ .log | truncate 5 }}|{{ .log | truncate 10 }}", want: "0123…|0123456789"},
+		{name: "duration from seconds", text: "{{ .seconds | duration }}", want: "1m35s"},
+		{name: "duration from a Go duration", text: "{{ .elapsed | duration }}", want: "2s"},
+		{name: "short duration", text: "{{ .fast | duration }}", want: "250ms"},
+		{
+			name: "date from RFC 3339",
+			text: `{{ .started | date "2006-01-02 15:04 -07:00" }}`,
+			want: "2026-03-01 10:30 +02:00",
+		},
+		{name: "date from Unix timestamp", text: `{{ .unix | date "2006-01-02 15:04" }}`, want: "2026-01-01 00:00"},
+		{name: "status word", text: "{{ .status | statusEmoji }}", want: "❌"},
+		{name: "exit code", text: "{{ .exitCode | statusEmoji }}", want: "✅"},
+		{name: "unknown status", text: `{{ "mystery" | statusEmoji }}`, want: "❔"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := msgtemplate.Render(tt.text, "", data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRender_HelperErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := msgtemplate.Render("{{ .v | duration }}", "", map[string]any{"v": "soon"})
+	require.ErrorContains(t, err, `duration: cannot use "soon", expected a Go duration or a number of seconds`)
+
+	_, err = msgtemplate.Render(`{{ .v | date "2006" }}`, "", map[string]any{"v": true})
+	require.ErrorContains(t, err, "date: cannot use bool, expected an RFC 3339 time or a Unix timestamp")
+}
@@ -5,16 +5,42 @@ package telegram
 
 import (
 	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/answercallbackquery"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/closeforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/createforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/deleteforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editmessagetext"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchat"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchatmember"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getme"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getupdates"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/reopenforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendchataction"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendrichmessage"
+	"github.com/beeyev/telegram-owl/internal/telegram/method/setmessagereaction"
 )
 
 // Client groups the Telegram operations exposed to the CLI.
 type Client struct {
-	SendMessage     sendmessage.Sender
-	SendMediaGroup  sendmediagroup.Sender
-	SendRichMessage sendrichmessage.Sender
+	SendMessage        sendmessage.Sender
+	SendMediaGroup     sendmediagroup.Sender
+	SendRichMessage    sendrichmessage.Sender
+	SetMessageReaction setmessagereaction.Sender
+	GetUpdates         getupdates.Getter
+	GetMe              getme.Getter
+	GetChat            getchat.Getter
+	GetChatMember      getchatmember.Getter
+	SendChatAction     sendchataction.Sender
+	CreateForumTopic   createforumtopic.Creator
+	EditForumTopic     editforumtopic.Editor
+	CloseForumTopic    closeforumtopic.Closer
+	ReopenForumTopic   reopenforumtopic.Reopener
+	DeleteForumTopic   deleteforumtopic.Deleter
+	AnswerCallback     answercallbackquery.Answerer
+	EditMessageText    editmessagetext.Editor
 }
 
 // NewClient builds all method senders over one configured HTTP transport.
@@ -25,8 +51,21 @@ func NewClient(apiBotURL, token, proxyURL string) (*Client, error) {
 	}
 
 	return &Client{
-		SendMessage:     sendmessage.New(httpClient),
-		SendMediaGroup:  sendmediagroup.New(httpClient),
-		SendRichMessage: sendrichmessage.New(httpClient),
+		SendMessage:        sendmessage.New(httpClient),
+		SendMediaGroup:     sendmediagroup.New(httpClient),
+		SendRichMessage:    sendrichmessage.New(httpClient),
+		SetMessageReaction: setmessagereaction.New(httpClient),
+		GetUpdates:         getupdates.New(httpClient),
+		GetMe:              getme.New(httpClient),
+		GetChat:            getchat.New(httpClient),
+		GetChatMember:      getchatmember.New(httpClient),
+		SendChatAction:     sendchataction.New(httpClient),
+		CreateForumTopic:   createforumtopic.New(httpClient),
+		EditForumTopic:     editforumtopic.New(httpClient),
+		CloseForumTopic:    closeforumtopic.New(httpClient),
+		ReopenForumTopic:   reopenforumtopic.New(httpClient),
+		DeleteForumTopic:   deleteforumtopic.New(httpClient),
+		AnswerCallback:     answercallbackquery.New(httpClient),
+		EditMessageText:    editmessagetext.New(httpClient),
 	}, nil
 }
@@ -0,0 +1,267 @@
+package attachment
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Archive formats for ArchiveOpener.
+const (
+	ArchiveZip   = "zip"
+	ArchiveTarGz = "tar.gz"
+)
+
+// errArchiveTooLarge stops compression as soon as the archive outgrows the
+// limit, so a huge directory is not compressed in full only to be rejected.
+var errArchiveTooLarge = errors.New("archive too large")
+
+// ArchiveOpener packs files into a single zip or tar.gz archive that Loader
+// opens under Name. The archive is streamed: files are compressed while the
+// upload reads it, so neither a temporary file nor the whole archive is kept.
+// Its size is unknown until it ends, so it reports MaxSizeBytes, and it fails
+// as soon as the compressed output grows past MaxSizeBytes.
+type ArchiveOpener struct {
+	Format string
+	Name   string
+	// Files are stored under their path relative to BaseDir.
+	Files        []string
+	BaseDir      string
+	MaxSizeBytes int64
+
+	// InputBytes and ArchiveBytes are set once the archive has been read to
+	// the end, for reporting the compression ratio.
+	InputBytes   int64
+	ArchiveBytes int64
+}
+
+// NewArchiveOpener expands args like Expand and stores every file under its
+// path relative to the common parent of the arguments, so archiving the
+// directory "build/artifacts" stores "artifacts/...". An empty name defaults
+// to the directory name for a single directory, and to "attachments"
+// otherwise; the format extension is added when the name lacks it.
+func NewArchiveOpener(
+	format, name string,
+	args []string,
+	opts ExpandOptions,
+	maxSizeBytes int64,
+) (*ArchiveOpener, error) {
+	if format != ArchiveZip && format != ArchiveTarGz {
+		return nil, fmt.Errorf("unsupported archive format %q", format)
+	}
+
+	files, err := Expand(args, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = "attachments"
+		if info, statErr := os.Stat(args[0]); len(args) == 1 && statErr == nil && info.IsDir() {
+			name = filepath.Base(filepath.Clean(args[0]))
+		}
+	}
+	if !strings.HasSuffix(strings.ToLower(name), "."+format) {
+		name += "." + format
+	}
+
+	return &ArchiveOpener{
+		Format:       format,
+		Name:         name,
+		Files:        files,
+		BaseDir:      archiveBaseDir(args),
+		MaxSizeBytes: maxSizeBytes,
+	}, nil
+}
+
+// Open returns the reader of the archive, which is written by a goroutine as
+// it is read. Closing the reader early stops the goroutine.
+func (o *ArchiveOpener) Open(name string) (*OpenedFile, error) {
+	if name != o.Name {
+		return nil, fmt.Errorf("open %q: %w", name, fs.ErrNotExist)
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		output := &limitedWriter{writer: writer, limit: o.MaxSizeBytes}
+		var err error
+		if o.Format == ArchiveZip {
+			err = o.writeZip(output)
+		} else {
+			err = o.writeTarGz(output)
+		}
+		o.ArchiveBytes = output.written
+
+		switch {
+		case errors.Is(err, errArchiveTooLarge):
+			err = fmt.Errorf(
+				"archive %q exceeds the max allowed of %d MB after compression",
+				o.Name,
+				bytesToMegabytes(o.MaxSizeBytes),
+			)
+		case err != nil:
+			err = fmt.Errorf("create archive %q: %w", o.Name, err)
+		}
+		_ = writer.CloseWithError(err)
+	}()
+
+	return &OpenedFile{File: reader, SizeBytes: o.MaxSizeBytes}, nil
+}
+
+func (o *ArchiveOpener) writeZip(output io.Writer) error {
+	archive := zip.NewWriter(output)
+	err := o.eachFile(func(entryName string, info fs.FileInfo, file io.Reader) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = entryName
+		header.Method = zip.Deflate
+
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func (o *ArchiveOpener) writeTarGz(output io.Writer) error {
+	compressed := gzip.NewWriter(output)
+	archive := tar.NewWriter(compressed)
+	err := o.eachFile(func(entryName string, info fs.FileInfo, file io.Reader) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = entryName
+
+		if err = archive.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err = io.Copy(archive, file)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err = archive.Close(); err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
+
+// eachFile opens the files in order and passes each to write with its entry
+// name, closing it before the next one is opened.
+func (o *ArchiveOpener) eachFile(write func(entryName string, info fs.FileInfo, file io.Reader) error) error {
+	o.InputBytes = 0
+	for _, filePath := range o.Files {
+		if err := o.addFile(filePath, write); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (o *ArchiveOpener) addFile(
+	filePath string,
+	write func(entryName string, info fs.FileInfo, file io.Reader) error,
+) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %q: %w", filePath, err)
+	}
+	o.InputBytes += info.Size()
+
+	return write(archiveEntryName(o.BaseDir, filePath), info, file)
+}
+
+// archiveBaseDir returns the deepest directory containing every argument:
+// the parent of a file or directory, or of the fixed part of a pattern.
+func archiveBaseDir(args []string) string {
+	var base []string
+	for i, arg := range args {
+		dir := filepath.Dir(filepath.Clean(arg))
+		if hasMeta(arg) {
+			root, _ := splitPattern(arg)
+			dir = filepath.Clean(root)
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+
+		segments := strings.Split(filepath.ToSlash(dir), "/")
+		if i == 0 {
+			base = segments
+
+			continue
+		}
+		common := 0
+		for common < len(base) && common < len(segments) && base[common] == segments[common] {
+			common++
+		}
+		base = base[:common]
+	}
+
+	if len(base) == 1 && base[0] == "" {
+		return string(filepath.Separator)
+	}
+
+	return filepath.FromSlash(strings.Join(base, "/"))
+}
+
+// archiveEntryName stores filePath relative to baseDir, falling back to the
+// file name for a path outside it.
+func archiveEntryName(baseDir, filePath string) string {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return filepath.Base(filePath)
+	}
+	rel, err := filepath.Rel(baseDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.Base(filePath)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// limitedWriter counts the bytes written and refuses to write past limit.
+type limitedWriter struct {
+	writer  io.Writer
+	limit   int64
+	written int64
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.written+int64(len(p)) > w.limit {
+		return 0, errArchiveTooLarge
+	}
+
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+
+	return n, err
+}
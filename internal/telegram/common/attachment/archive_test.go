@@ -0,0 +1,131 @@
+package attachment_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+func TestArchiveOpener_Zip(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{
+		"build/artifacts/app.log":        strings.Repeat("log line\n", 100),
+		"build/artifacts/report/out.txt": "done",
+	})
+
+	opener, err := attachment.NewArchiveOpener(
+		attachment.ArchiveZip, "", []string{filepath.Join(dir, "build", "artifacts")},
+		attachment.ExpandOptions{Recursive: true}, attachment.BytesPerMegabyte,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "artifacts.zip", opener.Name)
+
+	opened, err := opener.Open("artifacts.zip")
+	require.NoError(t, err)
+	assert.Equal(t, int64(attachment.BytesPerMegabyte), opened.SizeBytes, "a stream reports the limit")
+	data, err := io.ReadAll(opened.File)
+	require.NoError(t, err)
+	require.NoError(t, opened.File.Close())
+	assert.Equal(t, int64(904), opener.InputBytes)
+	assert.Equal(t, int64(len(data)), opener.ArchiveBytes)
+	assert.Less(t, opener.ArchiveBytes, opener.InputBytes)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, file := range archive.File {
+		reader, openErr := file.Open()
+		require.NoError(t, openErr)
+		content, readErr := io.ReadAll(reader)
+		require.NoError(t, readErr)
+		contents[file.Name] = string(content)
+	}
+	assert.Equal(t, map[string]string{
+		"artifacts/app.log":        strings.Repeat("log line\n", 100),
+		"artifacts/report/out.txt": "done",
+	}, contents)
+}
+
+func TestArchiveOpener_TarGz(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"logs/a.log": "a", "shots/b.png": "b"})
+
+	opener, err := attachment.NewArchiveOpener(
+		attachment.ArchiveTarGz, "ci",
+		[]string{filepath.Join(dir, "logs", "*.log"), filepath.Join(dir, "shots", "b.png")},
+		attachment.ExpandOptions{}, attachment.BytesPerMegabyte,
+	)
+	require.NoError(t, err)
+
+	opened, err := opener.Open("ci.tar.gz")
+	require.NoError(t, err)
+	compressed, err := gzip.NewReader(opened.File)
+	require.NoError(t, err)
+	archive := tar.NewReader(compressed)
+
+	var names []string
+	for {
+		header, nextErr := archive.Next()
+		if errors.Is(nextErr, io.EOF) {
+			break
+		}
+		require.NoError(t, nextErr)
+		names = append(names, header.Name)
+	}
+	assert.Equal(t, []string{"logs/a.log", "shots/b.png"}, names)
+}
+
+func TestArchiveOpener_CloseStopsWriting(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"data.bin": strings.Repeat("x", 1<<20)})
+
+	opener, err := attachment.NewArchiveOpener(
+		attachment.ArchiveTarGz, "", []string{filepath.Join(dir, "data.bin")},
+		attachment.ExpandOptions{}, attachment.BytesPerMegabyte,
+	)
+	require.NoError(t, err)
+
+	opened, err := opener.Open("attachments.tar.gz")
+	require.NoError(t, err)
+	_, err = opened.File.Read(make([]byte, 16))
+	require.NoError(t, err)
+	require.NoError(t, opened.File.Close(), "the upload may stop before the end of the archive")
+}
+
+func TestArchiveOpener_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"data.bin": strings.Repeat("x", 4096)})
+
+	opener, err := attachment.NewArchiveOpener(
+		attachment.ArchiveZip, "", []string{filepath.Join(dir, "data.bin")}, attachment.ExpandOptions{}, 100,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "attachments.zip", opener.Name)
+
+	opened, err := opener.Open("attachments.zip")
+	require.NoError(t, err)
+	_, err = io.ReadAll(opened.File)
+	require.EqualError(t, err, `archive "attachments.zip" exceeds the max allowed of 0 MB after compression`)
+	require.NoError(t, opened.File.Close())
+
+	_, err = opener.Open("other.zip")
+	require.ErrorContains(t, err, `open "other.zip": file does not exist`)
+
+	_, err = attachment.NewArchiveOpener("rar", "", []string{dir}, attachment.ExpandOptions{}, 100)
+	require.EqualError(t, err, `unsupported archive format "rar"`)
+}
@@ -11,6 +11,8 @@ type Attachment struct {
 	FileName  string
 	SizeBytes int64
 	File      io.ReadCloser // Owned by Attachment until Close is called.
+	URL       string        // Sent for Telegram to fetch instead of File.
+	FileID    string        // A file Telegram already stores, sent instead of File or URL.
 }
 
 // Close releases the underlying file. Nil attachments are accepted so callers
@@ -0,0 +1,274 @@
+package attachment
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Sort orders for the files a pattern or directory expands to.
+const (
+	SortByName  = "name"
+	SortByMtime = "mtime"
+	SortBySize  = "size"
+)
+
+// ExpandOptions controls how Expand resolves attachment arguments.
+type ExpandOptions struct {
+	// Recursive includes files in subdirectories of directory arguments.
+	Recursive bool
+	// Include keeps only expanded files matching one of these patterns, and
+	// Exclude drops those matching any of them. A pattern without "/" matches
+	// the file name, otherwise the end of the path, so "nightly/*.log" matches
+	// whether the files were found below a relative or an absolute path.
+	Include []string
+	Exclude []string
+	// SortBy orders the files of each expanded argument: SortByName (the
+	// default), SortByMtime or SortBySize, oldest or smallest first.
+	SortBy string
+}
+
+type expandedFile struct {
+	path string
+	info fs.FileInfo
+}
+
+// Expand resolves glob patterns, including "**" for any number of
+// directories, and directories into the files they match, so Loader only sees
+// file paths. Literal paths and URLs are kept as given for Loader to open and
+// report. Arguments keep their order, a file matched twice is kept once, and a
+// pattern or directory that matches nothing is an error rather than an empty
+// send. Like shell globs, wildcards do not match hidden names starting with
+// ".".
+func Expand(args []string, opts ExpandOptions) ([]string, error) {
+	for _, pattern := range slices.Concat(opts.Include, opts.Exclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid filter pattern %q: %w", pattern, err)
+		}
+	}
+
+	paths := make([]string, 0, len(args))
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		files, err := expandArg(arg, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			if key := filepath.Clean(file); !seen[key] {
+				seen[key] = true
+				paths = append(paths, file)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+func expandArg(arg string, opts ExpandOptions) ([]string, error) {
+	var root string
+	var pattern []string
+	switch {
+	case IsURL(arg), arg == StdinPath:
+		return []string{arg}, nil
+	case hasMeta(arg):
+		root, pattern = splitPattern(arg)
+		for _, segment := range pattern {
+			if _, err := path.Match(segment, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+			}
+		}
+	default:
+		info, err := os.Stat(arg)
+		if err != nil || !info.IsDir() {
+			return []string{arg}, nil
+		}
+		root, pattern = arg, []string{"*"}
+		if opts.Recursive {
+			pattern = []string{"**", "*"}
+		}
+	}
+
+	found, err := walk(root, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("expand %q: %w", arg, err)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%q matches no files", arg)
+	}
+
+	files := slices.DeleteFunc(slices.Clone(found), func(file expandedFile) bool {
+		return !opts.keeps(file.path)
+	})
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%q matches %d file(s), but none pass the include and exclude filters", arg, len(found))
+	}
+
+	slices.SortFunc(files, func(a, b expandedFile) int {
+		byName := strings.Compare(a.path, b.path)
+		switch opts.SortBy {
+		case SortByMtime:
+			return cmp.Or(a.info.ModTime().Compare(b.info.ModTime()), byName)
+		case SortBySize:
+			return cmp.Or(cmp.Compare(a.info.Size(), b.info.Size()), byName)
+		default:
+			return byName
+		}
+	})
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+
+	return paths, nil
+}
+
+func (o ExpandOptions) keeps(filePath string) bool {
+	matches := func(patterns []string) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			if !strings.Contains(pattern, "/") {
+				ok, _ := path.Match(pattern, filepath.Base(filePath))
+
+				return ok
+			}
+
+			patternSegments := strings.Split(pattern, "/")
+			segments := strings.Split(filepath.ToSlash(filePath), "/")
+			for i := range segments {
+				if matchSegments(patternSegments, segments[i:]) {
+					return true
+				}
+			}
+
+			return false
+		})
+	}
+
+	return (len(o.Include) == 0 || matches(o.Include)) && !matches(o.Exclude)
+}
+
+// splitPattern separates the directory before the first wildcard, where the
+// walk starts, from the pattern segments matched below it.
+func splitPattern(pattern string) (string, []string) {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	i := 0
+	for i < len(segments)-1 && !hasMeta(segments[i]) {
+		i++
+	}
+
+	root := strings.Join(segments[:i], "/")
+	switch {
+	case root == "" && strings.HasPrefix(pattern, "/"):
+		root = "/"
+	case root == "":
+		root = "."
+	}
+
+	return filepath.FromSlash(root), segments[i:]
+}
+
+// walk returns the files below root whose relative path matches pattern,
+// skipping directories that cannot lead to a match.
+func walk(root string, pattern []string) ([]expandedFile, error) {
+	var files []expandedFile
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if filePath == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+
+			return err
+		}
+		if filePath == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if entry.IsDir() && !matchPrefix(pattern, segments) {
+				return fs.SkipDir
+			}
+
+			return nil
+		}
+		if info.Mode().IsRegular() && matchSegments(pattern, segments) {
+			files = append(files, expandedFile{path: filePath, info: info})
+		}
+
+		return nil
+	})
+
+	return files, err
+}
+
+// matchSegments matches a path against a pattern segment by segment, where
+// "**" stands for any number of directories.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+			if i < len(name) && isHidden(name[i]) {
+				return false
+			}
+		}
+
+		return false
+	}
+
+	return len(name) > 0 && matchSegment(pattern[0], name[0]) && matchSegments(pattern[1:], name[1:])
+}
+
+// matchPrefix reports whether files inside the directory dir could match,
+// which needs at least one pattern segment left after dir.
+func matchPrefix(pattern, dir []string) bool {
+	if len(pattern) == 0 {
+		return false
+	}
+	if len(dir) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		return matchPrefix(pattern[1:], dir) || !isHidden(dir[0]) && matchPrefix(pattern, dir[1:])
+	}
+
+	return matchSegment(pattern[0], dir[0]) && matchPrefix(pattern[1:], dir[1:])
+}
+
+func matchSegment(pattern, name string) bool {
+	if isHidden(name) && !strings.HasPrefix(pattern, ".") {
+		return false
+	}
+	ok, _ := path.Match(pattern, name)
+
+	return ok
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
+
+func hasMeta(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
@@ -0,0 +1,218 @@
+package attachment_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+// createTree writes the files, given as slash paths with their content, below
+// a new directory and returns it.
+func createTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o700))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+
+	return dir
+}
+
+func TestExpand(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{
+		"reports/b.png":          "bb",
+		"reports/a.png":          "aaa",
+		"reports/summary.pdf":    "s",
+		"reports/nightly/c.png":  "c",
+		"reports/nightly/d.log":  "d",
+		"reports/.cache/e.png":   "e",
+		"reports/.hidden.png":    "h",
+		"reports/deep/er/f.png":  "f",
+		"other/notes.txt":        "n",
+		"other/sub/ignored.txt":  "i",
+		"other/.config/skip.txt": "s",
+	})
+
+	tests := []struct {
+		name string
+		args []string
+		opts attachment.ExpandOptions
+		want []string
+	}{
+		{
+			name: "literal paths are kept as given",
+			args: []string{"missing.txt", "other/notes.txt"},
+			want: []string{"missing.txt", "other/notes.txt"},
+		},
+		{
+			name: "glob in one directory",
+			args: []string{"reports/*.png"},
+			want: []string{"reports/a.png", "reports/b.png"},
+		},
+		{
+			name: "double star crosses directories but not hidden ones",
+			args: []string{"reports/**/*.png"},
+			want: []string{
+				"reports/a.png", "reports/b.png", "reports/deep/er/f.png", "reports/nightly/c.png",
+			},
+		},
+		{
+			name: "directory",
+			args: []string{"other"},
+			want: []string{"other/notes.txt"},
+		},
+		{
+			name: "recursive directory",
+			args: []string{"other"},
+			opts: attachment.ExpandOptions{Recursive: true},
+			want: []string{"other/notes.txt", "other/sub/ignored.txt"},
+		},
+		{
+			name: "include and exclude",
+			args: []string{"reports"},
+			opts: attachment.ExpandOptions{
+				Recursive: true,
+				Include:   []string{"*.png", "*.pdf"},
+				Exclude:   []string{"b.*"},
+			},
+			want: []string{
+				"reports/a.png", "reports/deep/er/f.png", "reports/nightly/c.png", "reports/summary.pdf",
+			},
+		},
+		{
+			name: "filter on the path",
+			args: []string{"reports/**/*"},
+			opts: attachment.ExpandOptions{Exclude: []string{"reports/nightly/**"}},
+			want: []string{"reports/a.png", "reports/b.png", "reports/deep/er/f.png", "reports/summary.pdf"},
+		},
+		{
+			name: "sort by size",
+			args: []string{"reports/*"},
+			opts: attachment.ExpandOptions{SortBy: attachment.SortBySize},
+			want: []string{"reports/summary.pdf", "reports/b.png", "reports/a.png"},
+		},
+		{
+			name: "arguments keep their order and duplicates are dropped",
+			args: []string{"other/notes.txt", "reports/b.png", "reports/*.png", "other"},
+			want: []string{"other/notes.txt", "reports/b.png", "reports/a.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := make([]string, 0, len(tt.args))
+			for _, arg := range tt.args {
+				args = append(args, filepath.Join(dir, filepath.FromSlash(arg)))
+			}
+
+			got, err := attachment.Expand(args, tt.opts)
+			require.NoError(t, err)
+
+			relative := make([]string, 0, len(got))
+			for _, file := range got {
+				rel, relErr := filepath.Rel(dir, file)
+				require.NoError(t, relErr)
+				relative = append(relative, filepath.ToSlash(rel))
+			}
+			assert.Equal(t, tt.want, relative)
+		})
+	}
+}
+
+func TestExpand_SortByMtime(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"a.png": "", "b.png": "", "c.png": ""})
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"a.png": time.Minute, "b.png": time.Hour, "c.png": time.Second} {
+		mtime := now.Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, name), mtime, mtime))
+	}
+
+	got, err := attachment.Expand([]string{dir}, attachment.ExpandOptions{SortBy: attachment.SortByMtime})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(got))
+	for _, file := range got {
+		names = append(names, filepath.Base(file))
+	}
+	assert.Equal(t, []string{"b.png", "a.png", "c.png"}, names)
+}
+
+func TestExpand_KeepsURLsAndStdin(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"a.png": "a"})
+	args := []string{"https://example.com/*.png", attachment.StdinPath, filepath.Join(dir, "*.png")}
+
+	got, err := attachment.Expand(args, attachment.ExpandOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/*.png", "-", filepath.Join(dir, "a.png")}, got)
+}
+
+func TestExpand_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"logs/app.log": "x"})
+	emptyDir := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(emptyDir, 0o700))
+
+	tests := []struct {
+		name    string
+		arg     string
+		opts    attachment.ExpandOptions
+		wantErr string
+	}{
+		{
+			name:    "pattern without matches",
+			arg:     filepath.Join(dir, "*.png"),
+			wantErr: "*.png\" matches no files",
+		},
+		{
+			name:    "pattern below a missing directory",
+			arg:     filepath.Join(dir, "missing", "*.png"),
+			wantErr: "*.png\" matches no files",
+		},
+		{
+			name:    "empty directory",
+			arg:     emptyDir,
+			wantErr: "empty\" matches no files",
+		},
+		{
+			name:    "everything filtered out",
+			arg:     filepath.Join(dir, "logs"),
+			opts:    attachment.ExpandOptions{Exclude: []string{"*.log"}},
+			wantErr: "logs\" matches 1 file(s), but none pass the include and exclude filters",
+		},
+		{
+			name:    "invalid filter",
+			arg:     filepath.Join(dir, "logs"),
+			opts:    attachment.ExpandOptions{Include: []string{"[a"}},
+			wantErr: `invalid filter pattern "[a": syntax error in pattern`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := attachment.Expand([]string{tt.arg}, tt.opts)
+			require.Error(t, err)
+			assert.True(t, strings.HasSuffix(err.Error(), tt.wantErr), err.Error())
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package attachment
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -14,10 +16,22 @@ type FileOpener interface {
 }
 
 // OpenedFile keeps the stream and its stat result together so Loader does not
-// need to reopen or reread a file to enforce size limits.
+// need to reopen or reread a file to enforce size limits. ForceDocument marks
+// content that is not a complete media file, like a part of a split file, so
+// it is never sent as a photo, video or audio.
 type OpenedFile struct {
-	File      io.ReadCloser
-	SizeBytes int64
+	File          io.ReadCloser
+	SizeBytes     int64
+	ForceDocument bool
+	// Name replaces the base name of the path, for paths such as URLs that do
+	// not end in a usable file name.
+	Name string
+	// ContentType, when it names a media type, decides the attachment type
+	// instead of the file name.
+	ContentType string
+	// URL is sent for Telegram to fetch instead of uploading File, which is nil
+	// then and SizeBytes unknown.
+	URL string
 }
 
 // OSFileOpener reads attachments from the local filesystem.
@@ -48,3 +62,62 @@ func (o *OSFileOpener) Open(path string) (*OpenedFile, error) {
 
 	return &OpenedFile{File: file, SizeBytes: info.Size()}, nil
 }
+
+// MemoryFileOpener serves attachments from byte buffers, so generated content
+// can be uploaded without a temporary file. Files are looked up by name.
+type MemoryFileOpener struct {
+	Files map[string][]byte
+}
+
+// Open returns a reader over the buffer registered under name.
+func (o *MemoryFileOpener) Open(name string) (*OpenedFile, error) {
+	data, ok := o.Files[name]
+	if !ok {
+		return nil, fmt.Errorf("open %q: %w", name, fs.ErrNotExist)
+	}
+
+	return &OpenedFile{File: NewMemoryFile(data), SizeBytes: int64(len(data))}, nil
+}
+
+// MemoryFile is an in-memory attachment. It stays seekable, so its type can
+// be detected without buffering, its content hashed for the file_id cache,
+// and its upload retried. Every in-memory attachment should use it.
+type MemoryFile struct {
+	*bytes.Reader
+}
+
+// NewMemoryFile returns a MemoryFile reading data.
+func NewMemoryFile(data []byte) *MemoryFile {
+	return &MemoryFile{Reader: bytes.NewReader(data)}
+}
+
+// Close implements [io.Closer]; there is nothing to release.
+func (f *MemoryFile) Close() error {
+	return nil
+}
+
+// peek reads up to n leading bytes and leaves them readable for the upload: a
+// seekable file is rewound, and any other file is wrapped to replay them.
+func (f *OpenedFile) peek(n int) ([]byte, error) {
+	header := make([]byte, n)
+	read, err := io.ReadFull(f.File, header)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	header = header[:read]
+
+	if seeker, ok := f.File.(io.Seeker); ok {
+		if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		return header, nil
+	}
+
+	f.File = struct {
+		io.Reader
+		io.Closer
+	}{Reader: io.MultiReader(bytes.NewReader(header), f.File), Closer: f.File}
+
+	return header, nil
+}
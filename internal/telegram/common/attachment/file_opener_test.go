@@ -2,6 +2,7 @@ package attachment_test
 
 import (
 	"io"
+	"io/fs"
 	"os"
 	"testing"
 
@@ -64,3 +65,23 @@ func TestOSFileOpener_Open(t *testing.T) {
 		assert.Equal(t, "file path cannot be empty", err.Error())
 	})
 }
+
+func TestMemoryFileOpener_Open(t *testing.T) {
+	t.Parallel()
+
+	opener := &attachment.MemoryFileOpener{Files: map[string][]byte{"output.txt": []byte("test data")}}
+
+	openedFile, err := opener.Open("output.txt")
+	require.NoError(t, err)
+	assert.Equal(t, int64(9), openedFile.SizeBytes)
+
+	data, err := io.ReadAll(openedFile.File)
+	require.NoError(t, err)
+	assert.Equal(t, "test data", string(data))
+	assert.Implements(t, (*io.Seeker)(nil), openedFile.File, "in-memory files stay seekable")
+	require.NoError(t, openedFile.File.Close())
+
+	_, err = opener.Open("missing.txt")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Contains(t, err.Error(), "missing.txt")
+}
@@ -1,6 +1,9 @@
 package attachment
 
-import "math"
+import (
+	"math"
+	"slices"
+)
 
 // BytesPerMegabyte uses the binary unit expected by the configured attachment
 // limits and their user-facing MB messages.
@@ -23,3 +26,24 @@ func isOnlyPhotoOrVideo(types map[AType]bool) bool {
 
 	return true
 }
+
+// partitionByClass splits attachments into the groups Telegram accepts as one
+// album: photos and videos, then documents, then audio. Each keeps the order
+// the files were given in, and empty groups are left out.
+func partitionByClass(attachments Attachments) []Attachments {
+	classes := make([]Attachments, 3)
+	for _, attach := range attachments {
+		var class int
+		switch attach.AType {
+		case Photo, Video:
+			class = 0
+		case Document:
+			class = 1
+		case Audio:
+			class = 2
+		}
+		classes[class] = append(classes[class], attach)
+	}
+
+	return slices.DeleteFunc(classes, func(class Attachments) bool { return len(class) == 0 })
+}
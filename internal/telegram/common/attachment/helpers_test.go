@@ -60,3 +60,27 @@ func TestIsOnlyPhotoOrVideo(t *testing.T) {
 		})
 	}
 }
+
+func TestPartitionByClass(t *testing.T) {
+	t.Parallel()
+
+	attachments := Attachments{
+		{AType: Audio, FileName: "a.mp3"},
+		{AType: Photo, FileName: "b.png"},
+		{AType: Document, FileName: "c.pdf"},
+		{AType: Video, FileName: "d.mp4"},
+		{AType: Photo, FileName: "e.jpg"},
+	}
+
+	var names [][]string
+	for _, class := range partitionByClass(attachments) {
+		var classNames []string
+		for _, attach := range class {
+			classNames = append(classNames, attach.FileName)
+		}
+		names = append(names, classNames)
+	}
+
+	assert.Equal(t, [][]string{{"b.png", "d.mp4", "e.jpg"}, {"c.pdf"}, {"a.mp3"}}, names)
+	assert.Empty(t, partitionByClass(Attachments{}))
+}
@@ -1,65 +1,105 @@
 package attachment
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
+	"strings"
 )
 
 // Loader validates, classifies, and opens attachments according to configured
 // limits. The caller owns returned files only when loading succeeds.
+// MaxTotalAttachments and MaxTotalSizeBytes limit one group, which is what a
+// single sendMediaGroup request accepts. GroupByType sends mixed media classes
+// as separate groups instead of turning every file into a document. Detect is
+// the DetectExtension, DetectContent or DetectBoth policy, and defaults to
+// DetectExtension. A PhotoOptimizer, when set, shrinks photos that would
+// otherwise be sent as documents.
 type Loader struct {
 	FileOpener                  FileOpener
 	IsEverythingDocument        bool
+	GroupByType                 bool
+	Detect                      string
+	PhotoOptimizer              *PhotoOptimizer
 	MaxTotalAttachments         int
 	MaxPhotoAttachmentSizeBytes int64
 	MaxAttachmentSizeBytes      int64
 	MaxTotalSizeBytes           int64
 }
 
-// LoadMultipleAttachments opens every path and returns a Telegram-compatible
-// group. On failure it closes the current file and everything opened earlier,
-// joining any cleanup failures with the primary error.
-func (l *Loader) LoadMultipleAttachments(filePaths []string) (Attachments, error) {
-	if err := l.validateAttachments(filePaths); err != nil {
-		return nil, err
+// LoadGroups opens every path and splits the attachments, in order, into
+// groups that fit one sendMediaGroup request: at most MaxTotalAttachments files
+// and MaxTotalSizeBytes bytes each. On failure it closes the current file and
+// everything opened earlier, joining any cleanup failures with the primary
+// error.
+func (l *Loader) LoadGroups(filePaths []string) ([]Attachments, error) {
+	if len(filePaths) == 0 {
+		return nil, errors.New("no attachments provided")
 	}
 
 	attachments := make(Attachments, 0, len(filePaths))
-	typesFound := make(map[AType]bool)
-	var totalSizeBytes int64
-
 	for _, path := range filePaths {
 		attachment, err := l.loadAttachment(path)
 		if err != nil {
 			return nil, errors.Join(err, attachments.Close())
 		}
 
-		totalSizeBytes += attachment.SizeBytes
-		if totalSizeBytes > l.MaxTotalSizeBytes {
-			limitErr := fmt.Errorf(
-				"total attachments size exceeds the max allowed %d MB",
-				bytesToMegabytes(l.MaxTotalSizeBytes),
-			)
-			return nil, errors.Join(limitErr, attachment.Close(), attachments.Close())
-		}
-
 		attachments = append(attachments, attachment)
-		if !l.IsEverythingDocument {
-			typesFound[attachment.AType] = true
+	}
+
+	if l.IsEverythingDocument {
+		return l.group(attachments), nil
+	}
+
+	if l.GroupByType {
+		var groups []Attachments
+		for _, class := range partitionByClass(attachments) {
+			groups = append(groups, l.group(class)...)
 		}
+
+		return groups, nil
 	}
 
-	if !l.IsEverythingDocument && !isOnlyPhotoOrVideo(typesFound) {
+	typesFound := make(map[AType]bool)
+	for _, attach := range attachments {
+		typesFound[attach.AType] = true
+	}
+	if !isOnlyPhotoOrVideo(typesFound) {
 		// A photo/video album may mix those two types. If any document or audio
-		// is present, normalize the entire group to documents so Telegram accepts
-		// one compatible media class.
+		// is present, normalize every group to documents so Telegram accepts
+		// one compatible media class and all albums look alike.
 		for _, attach := range attachments {
 			attach.AType = Document
 		}
 	}
 
-	return attachments, nil
+	return l.group(attachments), nil
+}
+
+// group splits attachments in order. The count per group is balanced, so 11
+// files become albums of 6 and 5 rather than 10 and a lone file, and a group
+// ends early when the next file would exceed the size limit.
+func (l *Loader) group(attachments Attachments) []Attachments {
+	maxCount := max(l.MaxTotalAttachments, 1)
+	groupCount := (len(attachments) + maxCount - 1) / maxCount
+	perGroup := (len(attachments) + groupCount - 1) / groupCount
+
+	var groups []Attachments
+	var current Attachments
+	var currentSizeBytes int64
+	for _, attach := range attachments {
+		full := len(current) == perGroup || currentSizeBytes+attach.SizeBytes > l.MaxTotalSizeBytes
+		if len(current) > 0 && full {
+			groups = append(groups, current)
+			current, currentSizeBytes = nil, 0
+		}
+		current = append(current, attach)
+		currentSizeBytes += attach.SizeBytes
+	}
+
+	return append(groups, current)
 }
 
 func (l *Loader) loadAttachment(filePath string) (*Attachment, error) {
@@ -68,8 +108,6 @@ func (l *Loader) loadAttachment(filePath string) (*Attachment, error) {
 		return nil, fmt.Errorf("failed to read attachment: %w", err)
 	}
 
-	attachmentType := l.determineAttachmentType(filePath, openedFile.SizeBytes)
-
 	if openedFile.SizeBytes > l.MaxAttachmentSizeBytes {
 		sizeErr := fmt.Errorf(
 			"attachment %q: size %d MB exceeds the max allowed of %d MB",
@@ -80,37 +118,82 @@ func (l *Loader) loadAttachment(filePath string) (*Attachment, error) {
 		return nil, errors.Join(sizeErr, openedFile.File.Close())
 	}
 
+	attachmentType, err := l.determineAttachmentType(filePath, openedFile)
+	if err != nil {
+		return nil, errors.Join(err, openedFile.File.Close())
+	}
+
+	fileName := filepath.Base(filePath)
+	if openedFile.Name != "" {
+		fileName = openedFile.Name
+	}
+	if attachmentType == Photo && l.PhotoOptimizer != nil && openedFile.File != nil {
+		if attachmentType, fileName, err = l.optimizePhoto(openedFile, fileName); err != nil {
+			return nil, fmt.Errorf("attachment %q: %w", filePath, err)
+		}
+	}
+
+	if attachmentType == Photo && openedFile.SizeBytes > l.MaxPhotoAttachmentSizeBytes {
+		// Telegram accepts a larger file as a document even when the extension
+		// would normally classify it as a photo.
+		attachmentType = Document
+	}
+
 	return &Attachment{
 		AType:     attachmentType,
-		FileName:  filepath.Base(filePath),
+		FileName:  fileName,
 		SizeBytes: openedFile.SizeBytes,
 		File:      openedFile.File,
+		URL:       openedFile.URL,
 	}, nil
 }
 
-func (l *Loader) determineAttachmentType(filePath string, sizeBytes int64) AType {
-	if l.IsEverythingDocument {
-		return Document
+func (l *Loader) determineAttachmentType(filePath string, openedFile *OpenedFile) (AType, error) {
+	if l.IsEverythingDocument || openedFile.ForceDocument {
+		return Document, nil
 	}
 
-	attachmentType := DetectType(filePath)
-	if attachmentType == Photo && sizeBytes > l.MaxPhotoAttachmentSizeBytes {
-		// Telegram accepts a larger file as a document even when the extension
-		// would normally classify it as a photo.
-		return Document
+	if attachmentType, ok := DetectMIMEType(openedFile.ContentType); ok {
+		return attachmentType, nil
+	}
+
+	name := filePath
+	if openedFile.Name != "" {
+		name = openedFile.Name
+	}
+	attachmentType := DetectType(name)
+	if (l.Detect == DetectContent || l.Detect == DetectBoth) && openedFile.File != nil {
+		header, err := openedFile.peek(SignatureLength)
+		if err != nil {
+			return "", fmt.Errorf("attachment %q: read file signature: %w", filePath, err)
+		}
+		attachmentType = ReconcileType(l.Detect, name, header)
 	}
 
-	return attachmentType
+	return attachmentType, nil
 }
 
-// validateAttachments rejects collection-level errors before any file is
-// opened, keeping these failure paths free of resource cleanup.
-func (l *Loader) validateAttachments(filePaths []string) error {
-	if len(filePaths) == 0 {
-		return errors.New("no attachments provided")
+// optimizePhoto reads the photo into memory and replaces it with the
+// optimized JPEG, renamed to match. A photo the optimizer rejects is kept as
+// it is and sent as a document, as an oversized photo is without the
+// optimizer.
+func (l *Loader) optimizePhoto(openedFile *OpenedFile, fileName string) (AType, string, error) {
+	data, err := io.ReadAll(openedFile.File)
+	if closeErr := openedFile.File.Close(); err != nil || closeErr != nil {
+		return "", "", errors.Join(err, closeErr)
 	}
-	if len(filePaths) > l.MaxTotalAttachments {
-		return fmt.Errorf("too many attachments: max allowed is %d, but got %d", l.MaxTotalAttachments, len(filePaths))
+
+	attachmentType := Photo
+	optimized, err := l.PhotoOptimizer.Optimize(data)
+	switch {
+	case err != nil:
+		attachmentType, optimized = Document, data
+	case !bytes.Equal(optimized, data):
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName)) + ".jpg"
 	}
-	return nil
+
+	openedFile.File = NewMemoryFile(optimized)
+	openedFile.SizeBytes = int64(len(optimized))
+
+	return attachmentType, fileName, nil
 }
@@ -1,6 +1,12 @@
 package attachment_test
 
 import (
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,59 +15,87 @@ import (
 	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
 )
 
-func TestLoadMultipleAttachments_EmptyFiles(t *testing.T) {
+func TestLoadGroups_EmptyFiles(t *testing.T) {
 	t.Parallel()
 
 	loader := &attachment.Loader{}
-	_, err := loader.LoadMultipleAttachments(nil)
+	_, err := loader.LoadGroups(nil)
 	require.Error(t, err)
 	assert.Equal(t, "no attachments provided", err.Error())
 }
 
-func TestLoadMultipleAttachments_ExceedsMaxCount(t *testing.T) {
+func TestLoadGroups_SplitsIntoBalancedGroups(t *testing.T) {
 	t.Parallel()
 
-	loader := &attachment.Loader{
-		MaxTotalAttachments: 2,
-	}
-	filePaths := []string{"a", "b", "c"}
-	_, err := loader.LoadMultipleAttachments(filePaths)
-	require.Error(t, err)
-	assert.ErrorContains(t, err, "too many attachments: max allowed is 2, but got 3")
-}
-
-func TestLoadMultipleAttachments_ExceedsMaxSize(t *testing.T) {
-	t.Parallel()
-
-	file1 := "abc1/file1.jpg"
-	file2 := "abc2/file2.jpg"
-	filePaths := []string{file1, file2}
-
-	mockOpener := &mockFileOpener{
-		files: map[string]*attachment.OpenedFile{
-			file1: {File: newMockReadCloser("dummy content"), SizeBytes: 2 * attachment.BytesPerMegabyte},
-			file2: {File: newMockReadCloser("dummy content"), SizeBytes: 4 * attachment.BytesPerMegabyte},
+	tests := []struct {
+		name           string
+		sizes          []int64
+		maxTotalSize   int64
+		wantGroupSizes []int
+	}{
+		{
+			name:           "fits one group",
+			sizes:          []int64{1, 1, 1},
+			maxTotalSize:   100,
+			wantGroupSizes: []int{3},
+		},
+		{
+			name:           "count balanced across groups",
+			sizes:          []int64{1, 1, 1, 1, 1},
+			maxTotalSize:   100,
+			wantGroupSizes: []int{3, 2},
+		},
+		{
+			name:           "size closes a group early",
+			sizes:          []int64{40, 40, 40, 10},
+			maxTotalSize:   100,
+			wantGroupSizes: []int{2, 2},
+		},
+		{
+			name:           "size and count together",
+			sizes:          []int64{90, 5, 5, 5, 5, 5, 5},
+			maxTotalSize:   100,
+			wantGroupSizes: []int{3, 3, 1},
 		},
 	}
 
-	loader := &attachment.Loader{
-		FileOpener:                  mockOpener,
-		MaxTotalAttachments:         2,
-		MaxPhotoAttachmentSizeBytes: 3 * attachment.BytesPerMegabyte,
-		MaxAttachmentSizeBytes:      5 * attachment.BytesPerMegabyte,
-		MaxTotalSizeBytes:           5 * attachment.BytesPerMegabyte,
-	}
-	_, err := loader.LoadMultipleAttachments(filePaths)
-	require.Error(t, err)
-	require.ErrorContains(t, err, "total attachments size exceeds the max allowed")
-
-	for s, file := range mockOpener.files {
-		fileMock := file.File.(*mockReadCloser)
-		assert.Truef(t, fileMock.IsClosed(), "file %s should be closed", s)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockOpener := &mockFileOpener{files: make(map[string]*attachment.OpenedFile)}
+			filePaths := make([]string, 0, len(tt.sizes))
+			for i, size := range tt.sizes {
+				path := fmt.Sprintf("dir/file%d.jpg", i)
+				filePaths = append(filePaths, path)
+				mockOpener.files[path] = &attachment.OpenedFile{File: newMockReadCloser("x"), SizeBytes: size}
+			}
+
+			loader := &attachment.Loader{
+				FileOpener:                  mockOpener,
+				MaxTotalAttachments:         3,
+				MaxPhotoAttachmentSizeBytes: 100,
+				MaxAttachmentSizeBytes:      100,
+				MaxTotalSizeBytes:           tt.maxTotalSize,
+			}
+			groups, err := loader.LoadGroups(filePaths)
+			require.NoError(t, err)
+
+			var gotGroupSizes []int
+			var gotNames []string
+			for _, group := range groups {
+				gotGroupSizes = append(gotGroupSizes, len(group))
+				for _, attach := range group {
+					gotNames = append(gotNames, "dir/"+attach.FileName)
+				}
+			}
+			assert.Equal(t, tt.wantGroupSizes, gotGroupSizes)
+			assert.Equal(t, filePaths, gotNames, "files must keep their order")
+		})
 	}
 }
 
-func TestLoadMultipleAttachments_AttachmentExceedsMaxAllowedSize(t *testing.T) {
+func TestLoadGroups_AttachmentExceedsMaxAllowedSize(t *testing.T) {
 	t.Parallel()
 
 	file1 := "abc1/file1.jpg"
@@ -81,7 +115,7 @@ func TestLoadMultipleAttachments_AttachmentExceedsMaxAllowedSize(t *testing.T) {
 		MaxAttachmentSizeBytes:      2 * attachment.BytesPerMegabyte,
 		MaxTotalSizeBytes:           4 * attachment.BytesPerMegabyte,
 	}
-	_, err := loader.LoadMultipleAttachments(filePaths)
+	_, err := loader.LoadGroups(filePaths)
 	require.Error(t, err)
 	require.ErrorContains(t, err, "exceeds the max allowed of")
 
@@ -91,7 +125,7 @@ func TestLoadMultipleAttachments_AttachmentExceedsMaxAllowedSize(t *testing.T) {
 	}
 }
 
-func TestLoadMultipleAttachments_Success(t *testing.T) {
+func TestLoadGroups_Success(t *testing.T) {
 	t.Parallel()
 
 	filePath1 := "abc1/file1.jpg"
@@ -115,9 +149,9 @@ func TestLoadMultipleAttachments_Success(t *testing.T) {
 		MaxAttachmentSizeBytes:      4096,
 		MaxTotalSizeBytes:           8192,
 	}
-	attachments, err := loader.LoadMultipleAttachments(filePaths)
+	groups, err := loader.LoadGroups(filePaths)
 	require.NoError(t, err)
-	assert.Len(t, attachments, 2)
+	require.Len(t, groups, 1)
 
 	for s, file := range mockOpener.files {
 		fileMock := file.File.(*mockReadCloser)
@@ -138,10 +172,10 @@ func TestLoadMultipleAttachments_Success(t *testing.T) {
 			File:      file2,
 		},
 	}
-	assert.Exactly(t, expectedAttachments, attachments)
+	assert.Exactly(t, expectedAttachments, groups[0])
 }
 
-func TestLoadMultipleAttachments_PhotoSwitchedToDocument(t *testing.T) {
+func TestLoadGroups_PhotoSwitchedToDocument(t *testing.T) {
 	t.Parallel()
 
 	filePath1 := "abc1/file1.jpg"
@@ -161,21 +195,21 @@ func TestLoadMultipleAttachments_PhotoSwitchedToDocument(t *testing.T) {
 		MaxAttachmentSizeBytes:      4096,
 		MaxTotalSizeBytes:           8192,
 	}
-	attachments, err := loader.LoadMultipleAttachments(filePaths)
+	groups, err := loader.LoadGroups(filePaths)
 	require.NoError(t, err)
 
-	expectedAttachment := attachment.Attachments{
+	expectedAttachment := []attachment.Attachments{{
 		0: {
 			AType:     attachment.Document,
 			FileName:  "file1.jpg",
 			SizeBytes: 2024,
 			File:      file,
 		},
-	}
-	assert.Equal(t, expectedAttachment, attachments)
+	}}
+	assert.Equal(t, expectedAttachment, groups)
 }
 
-func TestLoadMultipleAttachments_MixedAttachmentTypes(t *testing.T) {
+func TestLoadGroups_MixedAttachmentTypes(t *testing.T) {
 	t.Parallel()
 
 	filePath1 := "abc1/file1.jpg"
@@ -197,14 +231,45 @@ func TestLoadMultipleAttachments_MixedAttachmentTypes(t *testing.T) {
 		IsEverythingDocument:        false,
 	}
 
-	attachments, err := loader.LoadMultipleAttachments(filePaths)
+	groups, err := loader.LoadGroups(filePaths)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Len(t, groups[0], 2)
+	assert.Equal(t, attachment.Document, groups[0][0].AType)
+	assert.Equal(t, attachment.Document, groups[0][1].AType)
+}
+
+func TestLoadGroups_GroupByType(t *testing.T) {
+	t.Parallel()
+
+	filePaths := []string{"dir/report.pdf", "dir/shot1.png", "dir/notes.txt", "dir/shot2.png"}
+	mockOpener := &mockFileOpener{files: make(map[string]*attachment.OpenedFile)}
+	for _, path := range filePaths {
+		mockOpener.files[path] = &attachment.OpenedFile{File: newMockReadCloser("x"), SizeBytes: 1}
+	}
+
+	loader := &attachment.Loader{
+		FileOpener:                  mockOpener,
+		GroupByType:                 true,
+		MaxTotalAttachments:         10,
+		MaxPhotoAttachmentSizeBytes: 100,
+		MaxAttachmentSizeBytes:      100,
+		MaxTotalSizeBytes:           100,
+	}
+	groups, err := loader.LoadGroups(filePaths)
 	require.NoError(t, err)
-	assert.Len(t, attachments, 2)
-	assert.Equal(t, attachment.Document, attachments[0].AType)
-	assert.Equal(t, attachment.Document, attachments[1].AType)
+
+	require.Len(t, groups, 2)
+	require.Len(t, groups[0], 2)
+	assert.Equal(t, "shot1.png", groups[0][0].FileName)
+	assert.Equal(t, attachment.Photo, groups[0][0].AType)
+	assert.Equal(t, attachment.Photo, groups[0][1].AType)
+	require.Len(t, groups[1], 2)
+	assert.Equal(t, "report.pdf", groups[1][0].FileName)
+	assert.Equal(t, attachment.Document, groups[1][1].AType)
 }
 
-func TestLoadMultipleAttachments_AllAttachmentsAsDocuments(t *testing.T) {
+func TestLoadGroups_AllAttachmentsAsDocuments(t *testing.T) {
 	t.Parallel()
 
 	filePath1 := "abc1/file1.jpg"
@@ -227,9 +292,101 @@ func TestLoadMultipleAttachments_AllAttachmentsAsDocuments(t *testing.T) {
 		IsEverythingDocument:        true,
 	}
 
-	attachments, err := loader.LoadMultipleAttachments(filePaths)
+	groups, err := loader.LoadGroups(filePaths)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Len(t, groups[0], 2)
+	assert.Equal(t, attachment.Document, groups[0][0].AType)
+	assert.Equal(t, attachment.Document, groups[0][1].AType)
+}
+
+func TestLoadGroups_DetectsContent(t *testing.T) {
+	t.Parallel()
+
+	const png = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR and more pixels"
+
+	// A file on disk is seekable and gets rewound, the mock files are replayed.
+	dir := createTree(t, map[string]string{"screenshot": png})
+	diskFile, err := os.Open(filepath.Join(dir, "screenshot"))
+	require.NoError(t, err)
+
+	mockOpener := &mockFileOpener{
+		files: map[string]*attachment.OpenedFile{
+			"screenshot":  {File: diskFile, SizeBytes: int64(len(png))},
+			"capture.bin": {File: newMockReadCloser(png), SizeBytes: int64(len(png))},
+			"notes.jpg":   {File: newMockReadCloser("just text"), SizeBytes: 9},
+		},
+	}
+
+	loader := &attachment.Loader{
+		FileOpener:                  mockOpener,
+		GroupByType:                 true,
+		Detect:                      attachment.DetectBoth,
+		MaxTotalAttachments:         10,
+		MaxPhotoAttachmentSizeBytes: 1024,
+		MaxAttachmentSizeBytes:      1024,
+		MaxTotalSizeBytes:           4096,
+	}
+
+	groups, err := loader.LoadGroups([]string{"screenshot", "capture.bin", "notes.jpg"})
+	require.NoError(t, err)
+	attachments := attachment.Attachments(slices.Concat(groups...))
+	t.Cleanup(func() {
+		assert.NoError(t, attachments.Close())
+	})
+	require.Len(t, groups, 2)
+
+	wantTypes := []attachment.AType{attachment.Photo, attachment.Photo, attachment.Document}
+	wantContents := []string{png, png, "just text"}
+	require.Len(t, attachments, len(wantTypes))
+	for i, attach := range attachments {
+		assert.Equal(t, wantTypes[i], attach.AType, attach.FileName)
+		content, readErr := io.ReadAll(attach.File)
+		require.NoError(t, readErr)
+		assert.Equal(t, wantContents[i], string(content), attach.FileName)
+	}
+}
+
+func TestLoadGroups_OptimizesPhotos(t *testing.T) {
+	t.Parallel()
+
+	large := encodePNG(t, halves(400, 200))
+	panorama := encodePNG(t, halves(420, 20))
+	mockOpener := &mockFileOpener{
+		files: map[string]*attachment.OpenedFile{
+			"large.png":    {File: newMockReadCloser(string(large)), SizeBytes: int64(len(large))},
+			"panorama.png": {File: newMockReadCloser(string(panorama)), SizeBytes: int64(len(panorama))},
+		},
+	}
+
+	loader := &attachment.Loader{
+		FileOpener:                  mockOpener,
+		GroupByType:                 true,
+		PhotoOptimizer:              &attachment.PhotoOptimizer{MaxSizeBytes: 1024 * 1024, MaxDimension: 100},
+		MaxTotalAttachments:         10,
+		MaxPhotoAttachmentSizeBytes: 1024 * 1024,
+		MaxAttachmentSizeBytes:      1024 * 1024,
+		MaxTotalSizeBytes:           4 * 1024 * 1024,
+	}
+
+	groups, err := loader.LoadGroups([]string{"large.png", "panorama.png"})
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Len(t, groups[0], 1)
+	require.Len(t, groups[1], 1)
+
+	photo := groups[0][0]
+	assert.Equal(t, attachment.Photo, photo.AType)
+	assert.Equal(t, "large.jpg", photo.FileName)
+	content, err := io.ReadAll(photo.File)
 	require.NoError(t, err)
-	assert.Len(t, attachments, 2)
-	assert.Equal(t, attachment.Document, attachments[0].AType)
-	assert.Equal(t, attachment.Document, attachments[1].AType)
+	assert.Equal(t, int64(len(content)), photo.SizeBytes)
+	format, size := decodeSize(t, content)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Pt(100, 50), size)
+
+	// No resize fixes an aspect ratio over 20:1, so it stays a document.
+	assert.Equal(t, attachment.Document, groups[1][0].AType)
+	assert.Equal(t, "panorama.png", groups[1][0].FileName)
+	assert.Equal(t, int64(len(panorama)), groups[1][0].SizeBytes)
 }
@@ -0,0 +1,248 @@
+package attachment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png" // Registers PNG for image.Decode.
+)
+
+const (
+	// Telegram refuses photos whose width and height add up to more than
+	// maxPhotoDimensionSum, or whose sides differ by more than
+	// maxPhotoAspectRatio times.
+	maxPhotoDimensionSum = 10000
+	maxPhotoAspectRatio  = 20
+
+	photoQuality    = 90
+	minPhotoQuality = 40
+)
+
+// PhotoOptimizer downscales and recompresses JPEG and PNG photos that Telegram
+// would refuse as photos, so they keep their inline preview instead of being
+// sent as documents. It is pure Go, so static builds keep working.
+type PhotoOptimizer struct {
+	// MaxSizeBytes is the largest photo Telegram accepts.
+	MaxSizeBytes int64
+	// MaxDimension caps the longer side in pixels. Zero leaves only Telegram's
+	// own dimension limit.
+	MaxDimension int
+}
+
+// Optimize returns data unchanged when Telegram accepts the photo as it is,
+// and otherwise a JPEG that fits MaxDimension, Telegram's dimension limit and
+// MaxSizeBytes, rotated upright by its EXIF orientation because re-encoding
+// drops the EXIF data. Other formats are returned unchanged. It fails for a
+// broken photo, for an aspect ratio no resize can fix, and when even a small
+// low-quality JPEG is too large.
+func (o *PhotoOptimizer) Optimize(data []byte) ([]byte, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return data, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read photo: %w", err)
+	}
+
+	width, height := config.Width, config.Height
+	if width == 0 || height == 0 {
+		return nil, errors.New("photo has no pixels")
+	}
+	if max(width, height) > maxPhotoAspectRatio*min(width, height) {
+		return nil, fmt.Errorf("aspect ratio of %dx%d exceeds %d:1", width, height, maxPhotoAspectRatio)
+	}
+
+	scale := 1.0
+	if width+height > maxPhotoDimensionSum {
+		scale = float64(maxPhotoDimensionSum) / float64(width+height)
+	}
+	if o.MaxDimension > 0 && float64(max(width, height))*scale > float64(o.MaxDimension) {
+		scale = float64(o.MaxDimension) / float64(max(width, height))
+	}
+	if scale == 1 && int64(len(data)) <= o.MaxSizeBytes {
+		return data, nil
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode photo: %w", err)
+	}
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+	upright := orient(flatten(decoded), orientation)
+
+	// Lower the quality first, then the resolution, until the photo fits.
+	for range 8 {
+		resized := upright
+		if scale < 1 {
+			bounds := upright.Bounds()
+			resized = resize(
+				upright,
+				max(int(float64(bounds.Dx())*scale), 1),
+				max(int(float64(bounds.Dy())*scale), 1),
+			)
+		}
+
+		for quality := photoQuality; quality >= minPhotoQuality; quality -= 25 {
+			var encoded bytes.Buffer
+			if err = jpeg.Encode(&encoded, resized, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("encode photo: %w", err)
+			}
+			if int64(encoded.Len()) <= o.MaxSizeBytes {
+				return encoded.Bytes(), nil
+			}
+		}
+		scale *= 0.75
+	}
+
+	return nil, fmt.Errorf("photo does not fit %d MB", bytesToMegabytes(o.MaxSizeBytes))
+}
+
+// flatten draws img over white into an RGBA image, because JPEG has no
+// transparency.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, bounds.Min, draw.Over)
+
+	return flat
+}
+
+// orient turns img upright according to an EXIF orientation from 1 to 8.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	// Orientations 5 to 8 are stored rotated by 90 degrees.
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := range height {
+		for x := range width {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			default:
+				dx, dy = y, width-1-x
+			}
+			from, to := img.PixOffset(x, y), dst.PixOffset(dx, dy)
+			copy(dst.Pix[to:to+4], img.Pix[from:from+4])
+		}
+	}
+
+	return dst
+}
+
+// resize scales img down to width x height by averaging the source pixels
+// each destination pixel covers.
+func resize(img *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := img.Bounds().Dx(), img.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := range height {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := range width {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				offset := img.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					for c := range sum {
+						sum[c] += int(img.Pix[offset+c])
+					}
+					offset += 4
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			offset := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[offset+c] = uint8(sum[c] / count)
+			}
+		}
+	}
+
+	return dst
+}
+
+// exifOrientation reads the orientation tag from the EXIF segment of a JPEG,
+// and returns 1, upright, when there is none or it cannot be read.
+func exifOrientation(data []byte) int {
+	const (
+		markerSOS       = 0xDA
+		markerAPP1      = 0xE1
+		tagOrientation  = 0x0112
+		ifdEntryLength  = 12
+		exifHeader      = "Exif\x00\x00"
+		tiffHeaderBytes = 8
+	)
+
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		if marker == markerSOS || length < 2 || offset+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[offset+4 : offset+2+length]
+		offset += 2 + length
+		if marker != markerAPP1 || !bytes.HasPrefix(segment, []byte(exifHeader)) {
+			continue
+		}
+
+		tiff := segment[len(exifHeader):]
+		if len(tiff) < tiffHeaderBytes {
+			return 1
+		}
+		var order binary.ByteOrder = binary.BigEndian
+		if string(tiff[:2]) == "II" {
+			order = binary.LittleEndian
+		}
+
+		ifd := int(order.Uint32(tiff[4:]))
+		if ifd+2 > len(tiff) {
+			return 1
+		}
+		entries := int(order.Uint16(tiff[ifd:]))
+		for i := range entries {
+			entry := ifd + 2 + i*ifdEntryLength
+			if entry+ifdEntryLength > len(tiff) {
+				return 1
+			}
+			if order.Uint16(tiff[entry:]) == tagOrientation {
+				return int(order.Uint16(tiff[entry+8:]))
+			}
+		}
+
+		return 1
+	}
+
+	return 1
+}
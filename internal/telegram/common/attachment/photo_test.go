@@ -0,0 +1,152 @@
+package attachment_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+// halves returns a width x height image whose left half is red and right half
+// is blue.
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+// encodeJPEGWithOrientation encodes img as a JPEG carrying a big-endian EXIF
+// orientation tag.
+func encodeJPEGWithOrientation(t *testing.T, img image.Image, orientation byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+
+	exif := []byte("Exif\x00\x00MM\x00\x2A\x00\x00\x00\x08\x00\x01" +
+		"\x01\x12\x00\x03\x00\x00\x00\x01\x00" + string(orientation) + "\x00\x00" + "\x00\x00\x00\x00")
+	segment := append([]byte{0xFF, 0xE1, 0x00, byte(len(exif) + 2)}, exif...)
+
+	data := buf.Bytes()
+
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func decodeSize(t *testing.T, data []byte) (string, image.Point) {
+	t.Helper()
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	return format, image.Pt(config.Width, config.Height)
+}
+
+func TestPhotoOptimizer_KeepsAcceptedPhoto(t *testing.T) {
+	t.Parallel()
+
+	data := encodePNG(t, halves(40, 20))
+	optimizer := &attachment.PhotoOptimizer{MaxSizeBytes: attachment.BytesPerMegabyte, MaxDimension: 100}
+
+	got, err := optimizer.Optimize(data)
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// Formats the optimizer cannot read are left for Telegram to judge.
+	bmp := []byte("BM\x00\x00 not decoded")
+	got, err = optimizer.Optimize(bmp)
+	require.NoError(t, err)
+	assert.Equal(t, bmp, got)
+}
+
+func TestPhotoOptimizer_ResizesToMaxDimension(t *testing.T) {
+	t.Parallel()
+
+	optimizer := &attachment.PhotoOptimizer{MaxSizeBytes: attachment.BytesPerMegabyte, MaxDimension: 100}
+
+	got, err := optimizer.Optimize(encodePNG(t, halves(400, 200)))
+	require.NoError(t, err)
+
+	format, size := decodeSize(t, got)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Pt(100, 50), size)
+}
+
+func TestPhotoOptimizer_HonorsExifOrientation(t *testing.T) {
+	t.Parallel()
+
+	// Orientation 6 is stored rotated, and displayed turned 90 degrees
+	// clockwise, so the red left half ends up on top.
+	data := encodeJPEGWithOrientation(t, halves(40, 20), 6)
+	optimizer := &attachment.PhotoOptimizer{MaxSizeBytes: attachment.BytesPerMegabyte, MaxDimension: 30}
+
+	got, err := optimizer.Optimize(data)
+	require.NoError(t, err)
+
+	decoded, err := jpeg.Decode(bytes.NewReader(got))
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(15, 30), decoded.Bounds().Size())
+
+	top, _, _, _ := decoded.At(7, 3).RGBA()
+	_, _, bottom, _ := decoded.At(7, 26).RGBA()
+	assert.Greater(t, top, uint32(0xC000), "top should be red")
+	assert.Greater(t, bottom, uint32(0xC000), "bottom should be blue")
+}
+
+func TestPhotoOptimizer_RecompressesToMaxSize(t *testing.T) {
+	t.Parallel()
+
+	// Noise compresses badly, so the photo only fits at a lower quality or
+	// resolution.
+	random := rand.New(rand.NewPCG(1, 2))
+	noise := image.NewRGBA(image.Rect(0, 0, 300, 300))
+	for i := range noise.Pix {
+		noise.Pix[i] = uint8(random.UintN(256))
+	}
+	data := encodePNG(t, noise)
+	optimizer := &attachment.PhotoOptimizer{MaxSizeBytes: 30_000}
+
+	got, err := optimizer.Optimize(data)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(got), 30_000)
+
+	format, _ := decodeSize(t, got)
+	assert.Equal(t, "jpeg", format)
+}
+
+func TestPhotoOptimizer_Errors(t *testing.T) {
+	t.Parallel()
+
+	optimizer := &attachment.PhotoOptimizer{MaxSizeBytes: attachment.BytesPerMegabyte}
+
+	_, err := optimizer.Optimize(encodePNG(t, halves(210, 10)))
+	require.EqualError(t, err, "aspect ratio of 210x10 exceeds 20:1")
+
+	_, err = optimizer.Optimize([]byte("\x89PNG\r\n\x1a\n truncated"))
+	require.ErrorContains(t, err, "read photo: ")
+}
@@ -0,0 +1,183 @@
+package attachment
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// PartSuffix is the suffix of the numbered parts SplitOpener creates, formatted
+// with the part number starting at 1.
+const PartSuffix = ".part%03d"
+
+// manifestLine matches a checksum line in the format sha256sum prints.
+var manifestLine = regexp.MustCompile(`^([0-9a-f]{64}) [ *](\S.*)$`)
+
+// SplitFile is a file SplitOpener sends as parts, with the checksums the
+// manifest lists.
+type SplitFile struct {
+	Path      string
+	SizeBytes int64
+	// Parts are the paths the parts are opened under, in order.
+	Parts      []string
+	PartSHA256 []string
+	SHA256     string
+}
+
+// SplitOpener serves files larger than PartSizeBytes as numbered parts that
+// each fit the upload limit. A part is a section read straight from the
+// original file, so nothing is copied to disk, and other files are opened
+// like OSFileOpener does.
+type SplitOpener struct {
+	PartSizeBytes int64
+	Files         []*SplitFile
+
+	parts map[string]splitPart
+}
+
+type splitPart struct {
+	path       string
+	offset     int64
+	sizeBytes  int64
+	partNumber int
+}
+
+// NewSplitOpener checks the size of every path and returns the opener with
+// the paths to load, where each file over partSizeBytes is replaced by its
+// parts. The checksums are computed in one pass over each split file.
+func NewSplitOpener(paths []string, partSizeBytes int64) (*SplitOpener, []string, error) {
+	if partSizeBytes <= 0 {
+		return nil, nil, errors.New("part size must be positive")
+	}
+
+	opener := &SplitOpener{PartSizeBytes: partSizeBytes, parts: make(map[string]splitPart)}
+	loadPaths := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() <= partSizeBytes {
+			// Small files, and files that cannot be read, are left to Loader.
+			loadPaths = append(loadPaths, path)
+
+			continue
+		}
+
+		file := &SplitFile{Path: path, SizeBytes: info.Size()}
+		for offset := int64(0); offset < info.Size(); offset += partSizeBytes {
+			number := len(file.Parts) + 1
+			partPath := path + fmt.Sprintf(PartSuffix, number)
+			opener.parts[partPath] = splitPart{
+				path:       path,
+				offset:     offset,
+				sizeBytes:  min(partSizeBytes, info.Size()-offset),
+				partNumber: number,
+			}
+			file.Parts = append(file.Parts, partPath)
+		}
+		if err = file.checksum(partSizeBytes); err != nil {
+			return nil, nil, err
+		}
+
+		opener.Files = append(opener.Files, file)
+		loadPaths = append(loadPaths, file.Parts...)
+	}
+
+	return opener, loadPaths, nil
+}
+
+// Open returns a part as a seekable section of the original file, and any
+// other path as the file itself.
+func (o *SplitOpener) Open(name string) (*OpenedFile, error) {
+	part, ok := o.parts[name]
+	if !ok {
+		return (&OSFileOpener{}).Open(name)
+	}
+
+	file, err := os.Open(part.path)
+	if err != nil {
+		return nil, fmt.Errorf("open part %d of %q: %w", part.partNumber, part.path, err)
+	}
+
+	return &OpenedFile{
+		File: struct {
+			*io.SectionReader
+			io.Closer
+		}{SectionReader: io.NewSectionReader(file, part.offset, part.sizeBytes), Closer: file},
+		SizeBytes:     part.sizeBytes,
+		ForceDocument: true,
+	}, nil
+}
+
+// Manifest lists the SHA-256 of every part and of the whole file in the
+// format sha256sum prints, followed by the command that reassembles them.
+func (f *SplitFile) Manifest() string {
+	name := filepath.Base(f.Path)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s was split into %d parts, %d bytes in total.\n\n", name, len(f.Parts), f.SizeBytes)
+	b.WriteString("SHA-256:\n")
+	for i, part := range f.Parts {
+		fmt.Fprintf(&b, "%s  %s\n", f.PartSHA256[i], filepath.Base(part))
+	}
+	fmt.Fprintf(&b, "%s  %s\n\n", f.SHA256, name)
+	fmt.Fprintf(&b, "Save this message as %s.sha256 next to the parts and reassemble them with:\n", name)
+	fmt.Fprintf(&b, "telegram-owl join --manifest %s.sha256 %s.part*", name, name)
+
+	return b.String()
+}
+
+// checksum hashes the parts and the whole file in a single read.
+func (f *SplitFile) checksum(partSizeBytes int64) error {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	whole := sha256.New()
+	f.PartSHA256 = make([]string, 0, len(f.Parts))
+	for range f.Parts {
+		part := sha256.New()
+		_, err = io.CopyN(io.MultiWriter(whole, part), file, partSizeBytes)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("checksum %q: %w", f.Path, err)
+		}
+		f.PartSHA256 = append(f.PartSHA256, hexSum(part))
+	}
+	f.SHA256 = hexSum(whole)
+
+	return nil
+}
+
+// ParseManifest reads the checksum lines of a manifest written by Manifest,
+// or by sha256sum, into a map from file name to SHA-256. Other lines are
+// skipped, so the whole message can be saved as it is.
+func ParseManifest(r io.Reader) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		match := manifestLine.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match != nil {
+			sums[match[2]] = match[1]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(sums) == 0 {
+		return nil, errors.New("manifest has no SHA-256 lines")
+	}
+
+	return sums, nil
+}
+
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,88 @@
+package attachment_test
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+func TestSplitOpener(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{
+		"backup.tar": "0123456789abcdefghijKLMNO",
+		"notes.txt":  "small",
+	})
+	backup, notes := filepath.Join(dir, "backup.tar"), filepath.Join(dir, "notes.txt")
+
+	opener, paths, err := attachment.NewSplitOpener([]string{notes, backup}, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{notes, backup + ".part001", backup + ".part002", backup + ".part003"}, paths)
+
+	require.Len(t, opener.Files, 1)
+	split := opener.Files[0]
+	assert.Equal(t, int64(25), split.SizeBytes)
+	assert.Equal(t, "074af3ea8c41e380ac052d9170c0d2cc4f8e0db42c1bc47cec00813131268d90", split.SHA256)
+	require.Len(t, split.PartSHA256, 3)
+	assert.Equal(t, "72399361da6a7754fec986dca5b7cbaf1c810a28ded4abaf56b2106d06cb78b0", split.PartSHA256[1])
+
+	var joined strings.Builder
+	for i, want := range []string{"0123456789", "abcdefghij", "KLMNO"} {
+		opened, openErr := opener.Open(paths[i+1])
+		require.NoError(t, openErr)
+		assert.True(t, opened.ForceDocument)
+		assert.Equal(t, int64(len(want)), opened.SizeBytes)
+
+		// Parts stay seekable, so an upload can be retried.
+		_, readErr := io.ReadAll(opened.File)
+		require.NoError(t, readErr)
+		seeker, ok := opened.File.(io.Seeker)
+		require.True(t, ok)
+		_, seekErr := seeker.Seek(0, io.SeekStart)
+		require.NoError(t, seekErr)
+
+		content, readErr := io.ReadAll(opened.File)
+		require.NoError(t, readErr)
+		assert.Equal(t, want, string(content))
+		joined.Write(content)
+		require.NoError(t, opened.File.Close())
+	}
+	assert.Equal(t, "0123456789abcdefghijKLMNO", joined.String())
+
+	opened, err := opener.Open(notes)
+	require.NoError(t, err)
+	assert.False(t, opened.ForceDocument)
+	require.NoError(t, opened.File.Close())
+}
+
+func TestSplitFile_Manifest(t *testing.T) {
+	t.Parallel()
+
+	dir := createTree(t, map[string]string{"backup.tar": "0123456789abcdefghijKLMNO"})
+	opener, _, err := attachment.NewSplitOpener([]string{filepath.Join(dir, "backup.tar")}, 10)
+	require.NoError(t, err)
+	split := opener.Files[0]
+
+	manifest := split.Manifest()
+	assert.True(t, strings.HasPrefix(manifest, "backup.tar was split into 3 parts, 25 bytes in total.\n\nSHA-256:\n"))
+	assert.Contains(t, manifest, split.PartSHA256[1]+"  backup.tar.part002\n")
+	assert.True(t, strings.HasSuffix(manifest, "telegram-owl join --manifest backup.tar.sha256 backup.tar.part*"))
+
+	sums, err := attachment.ParseManifest(strings.NewReader(manifest))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"backup.tar.part001": split.PartSHA256[0],
+		"backup.tar.part002": split.PartSHA256[1],
+		"backup.tar.part003": split.PartSHA256[2],
+		"backup.tar":         split.SHA256,
+	}, sums)
+
+	_, err = attachment.ParseManifest(strings.NewReader("no checksums here"))
+	require.EqualError(t, err, "manifest has no SHA-256 lines")
+}
@@ -0,0 +1,90 @@
+package attachment
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// StdinPath is the attachment argument that reads the file from standard
+// input.
+const StdinPath = "-"
+
+// StdinFileOpener serves StdinPath from Reader under Name and leaves every
+// other path to Files. Input that ends within BufferBytes is read into memory,
+// so it has its real size and is grouped and classified like a file. Set
+// BufferBytes to the photo size limit, so a small picture is still sent as a
+// photo. Longer input is streamed: it has no size until it ends, so it reports
+// MaxSizeBytes, which keeps album totals within the limit, and it fails as
+// soon as it grows past MaxSizeBytes. Standard input can be opened only once.
+type StdinFileOpener struct {
+	Reader       io.Reader
+	Name         string
+	BufferBytes  int64
+	MaxSizeBytes int64
+	Files        FileOpener
+}
+
+// Open opens name as described on StdinFileOpener.
+func (o *StdinFileOpener) Open(name string) (*OpenedFile, error) {
+	if name != StdinPath {
+		return o.Files.Open(name)
+	}
+
+	head, err := io.ReadAll(io.LimitReader(o.Reader, o.BufferBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read standard input: %w", err)
+	}
+	if int64(len(head)) <= o.BufferBytes {
+		return &OpenedFile{File: NewMemoryFile(head), SizeBytes: int64(len(head)), Name: o.Name}, nil
+	}
+
+	return &OpenedFile{
+		File: &stdinFile{
+			reader:       io.MultiReader(bytes.NewReader(head), o.Reader),
+			maxSizeBytes: o.MaxSizeBytes,
+		},
+		SizeBytes: o.MaxSizeBytes,
+		Name:      o.Name,
+	}, nil
+}
+
+// stdinFile reads standard input up to maxSizeBytes. Closing it leaves
+// standard input open, since the process owns it.
+type stdinFile struct {
+	reader       io.Reader
+	maxSizeBytes int64
+	read         int64
+}
+
+func (f *stdinFile) Read(p []byte) (int, error) {
+	if f.read > f.maxSizeBytes {
+		return 0, f.tooLarge()
+	}
+
+	// Reading one byte past the limit tells a stream that ends exactly at the
+	// limit from one that is larger.
+	if remaining := f.maxSizeBytes + 1 - f.read; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := f.reader.Read(p)
+	f.read += int64(n)
+	if f.read > f.maxSizeBytes {
+		return n - 1, f.tooLarge()
+	}
+
+	return n, err
+}
+
+func (f *stdinFile) tooLarge() error {
+	return fmt.Errorf(
+		"standard input exceeds the max allowed attachment size of %d MB",
+		bytesToMegabytes(f.maxSizeBytes),
+	)
+}
+
+// Close implements [io.Closer]; standard input stays open.
+func (f *stdinFile) Close() error {
+	return nil
+}
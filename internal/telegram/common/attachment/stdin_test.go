@@ -0,0 +1,108 @@
+package attachment_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+func TestStdinFileOpener(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{name: "under the limit", input: "\x1f\x8b\x00binary"},
+		{name: "exactly the limit", input: strings.Repeat("x", 10)},
+		{
+			name:    "over the limit",
+			input:   strings.Repeat("x", 11),
+			wantErr: "standard input exceeds the max allowed attachment size of 0 MB",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opener := &attachment.StdinFileOpener{
+				Reader:       strings.NewReader(tt.input),
+				Name:         "dump.sql.gz",
+				MaxSizeBytes: 10,
+			}
+
+			opened, err := opener.Open(attachment.StdinPath)
+			require.NoError(t, err)
+			assert.Equal(t, "dump.sql.gz", opened.Name)
+			assert.Equal(t, int64(10), opened.SizeBytes, "the size is reserved up to the limit")
+
+			data, err := io.ReadAll(opened.File)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				assert.Len(t, data, 10, "no byte past the limit is passed on")
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.input, string(data))
+			}
+			require.NoError(t, opened.File.Close())
+		})
+	}
+}
+
+func TestStdinFileOpener_BuffersShortInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		input         string
+		wantSizeBytes int64
+		wantSeekable  bool
+	}{
+		{name: "within the buffer", input: "pic", wantSizeBytes: 3, wantSeekable: true},
+		{name: "exactly the buffer", input: "pict", wantSizeBytes: 4, wantSeekable: true},
+		{name: "over the buffer", input: "picture", wantSizeBytes: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opener := &attachment.StdinFileOpener{
+				Reader:       strings.NewReader(tt.input),
+				Name:         "x.jpg",
+				BufferBytes:  4,
+				MaxSizeBytes: 10,
+			}
+
+			opened, err := opener.Open(attachment.StdinPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSizeBytes, opened.SizeBytes)
+			_, seekable := opened.File.(io.Seeker)
+			assert.Equal(t, tt.wantSeekable, seekable)
+
+			data, err := io.ReadAll(opened.File)
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, string(data), "the buffered start is not lost")
+		})
+	}
+}
+
+func TestStdinFileOpener_DelegatesPaths(t *testing.T) {
+	t.Parallel()
+
+	file := &attachment.OpenedFile{File: newMockReadCloser("x"), SizeBytes: 1}
+	opener := &attachment.StdinFileOpener{
+		Files: &mockFileOpener{files: map[string]*attachment.OpenedFile{"local.txt": file}},
+	}
+
+	opened, err := opener.Open("local.txt")
+	require.NoError(t, err)
+	assert.Same(t, file, opened)
+}
@@ -1,10 +1,27 @@
 package attachment
 
 import (
+	"bytes"
+	"mime"
 	"path/filepath"
 	"strings"
 )
 
+// Detection policies for Loader.Detect.
+const (
+	// DetectExtension trusts the file extension alone.
+	DetectExtension = "extension"
+	// DetectContent trusts the file signature alone, so a file without a known
+	// signature is sent as a document whatever its name.
+	DetectContent = "content"
+	// DetectBoth prefers a known signature and keeps the extension otherwise,
+	// unless the extension names a format whose signature is missing.
+	DetectBoth = "both"
+)
+
+// SignatureLength is the number of leading bytes DetectContentType inspects.
+const SignatureLength = 16
+
 // DetectType maps a case-insensitive filename extension to a Telegram media
 // type. Empty names, missing extensions, and unknown extensions safely fall
 // back to Document.
@@ -13,8 +30,6 @@ func DetectType(fileName string) AType {
 		return Document
 	}
 
-	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
-
 	extensionToType := map[string]AType{
 		// Images
 		"jpg":  Photo,
@@ -37,9 +52,106 @@ func DetectType(fileName string) AType {
 		"gif": Video,
 	}
 
-	if attachmentType, ok := extensionToType[ext]; ok {
+	if attachmentType, ok := extensionToType[extension(fileName)]; ok {
 		return attachmentType
 	}
 
 	return Document
 }
+
+// DetectContentType recognizes JPEG, PNG, WebP, GIF, MP4, MOV, MKV, MP3, FLAC,
+// OGG and PDF files by their leading bytes. It reports false when header
+// matches none of them.
+func DetectContentType(header []byte) (AType, bool) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xFF, 0xD8, 0xFF}),
+		bytes.HasPrefix(header, []byte("\x89PNG\r\n\x1a\n")),
+		len(header) >= 12 && bytes.HasPrefix(header, []byte("RIFF")) && string(header[8:12]) == "WEBP":
+		return Photo, true
+	case bytes.HasPrefix(header, []byte("GIF87a")),
+		bytes.HasPrefix(header, []byte("GIF89a")),
+		bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// GIF follows DetectType, which sends animations as video.
+		return Video, true
+	case len(header) >= 12 && string(header[4:8]) == "ftyp":
+		return isoMediaType(string(header[8:12]))
+	case bytes.HasPrefix(header, []byte("ID3")),
+		len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0,
+		bytes.HasPrefix(header, []byte("fLaC")),
+		bytes.HasPrefix(header, []byte("OggS")):
+		return Audio, true
+	case bytes.HasPrefix(header, []byte("%PDF-")):
+		return Document, true
+	default:
+		return Document, false
+	}
+}
+
+// DetectMIMEType maps an HTTP Content-Type to a media type. It reports false
+// for an empty, invalid or generic binary type, which says nothing about the
+// content, and treats every other non-media type as a document.
+func DetectMIMEType(contentType string) (AType, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		return Document, false
+	}
+
+	switch {
+	case mediaType == "image/jpeg", mediaType == "image/png", mediaType == "image/webp", mediaType == "image/bmp":
+		return Photo, true
+	case mediaType == "image/gif", strings.HasPrefix(mediaType, "video/"):
+		return Video, true
+	case strings.HasPrefix(mediaType, "audio/"):
+		return Audio, true
+	default:
+		return Document, true
+	}
+}
+
+// ReconcileType picks the media type of fileName under a detection policy,
+// given the leading bytes of the file. An empty policy means DetectExtension.
+func ReconcileType(policy, fileName string, header []byte) AType {
+	contentType, recognized := DetectContentType(header)
+
+	switch policy {
+	case DetectContent:
+		return contentType
+	case DetectBoth:
+		if recognized {
+			return contentType
+		}
+		if signedExtensions[extension(fileName)] {
+			// The name promises a format we can recognize, but the bytes do not
+			// match it, so Telegram would reject the file as that media type.
+			return Document
+		}
+
+		return DetectType(fileName)
+	default:
+		return DetectType(fileName)
+	}
+}
+
+// signedExtensions are the media extensions DetectContentType has a
+// signature for.
+var signedExtensions = map[string]bool{
+	"jpg": true, "jpeg": true, "png": true, "webp": true, "gif": true,
+	"mp4": true, "mov": true, "mkv": true, "mp3": true, "flac": true,
+}
+
+// isoMediaType classifies an ISO base media file, the container of MP4, MOV
+// and M4A, by its major brand.
+func isoMediaType(brand string) (AType, bool) {
+	switch brand {
+	case "M4A ", "M4B ":
+		return Audio, true
+	case "isom", "iso2", "iso4", "iso5", "iso6", "mp41", "mp42", "avc1", "M4V ", "qt  ", "3gp4", "3gp5", "dash":
+		return Video, true
+	default:
+		return Document, false
+	}
+}
+
+func extension(fileName string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+}
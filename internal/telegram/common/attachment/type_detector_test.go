@@ -62,3 +62,102 @@ func TestDetectType(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		want   attachment.AType
+		wantOK bool
+	}{
+		{"jpeg", "\xFF\xD8\xFF\xE0\x00\x10JFIF", attachment.Photo, true},
+		{"png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR", attachment.Photo, true},
+		{"webp", "RIFF\x24\x00\x00\x00WEBPVP8 ", attachment.Photo, true},
+		{"gif", "GIF89a\x01\x00\x01\x00", attachment.Video, true},
+		{"mp4", "\x00\x00\x00\x20ftypisom\x00\x00\x02\x00", attachment.Video, true},
+		{"mov", "\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00", attachment.Video, true},
+		{"m4a", "\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00", attachment.Audio, true},
+		{"mkv", "\x1A\x45\xDF\xA3\x01\x00\x00\x00", attachment.Video, true},
+		{"mp3 with id3 tag", "ID3\x04\x00\x00\x00\x00\x00\x00", attachment.Audio, true},
+		{"mp3 frame", "\xFF\xFB\x90\x64\x00", attachment.Audio, true},
+		{"flac", "fLaC\x00\x00\x00\x22", attachment.Audio, true},
+		{"ogg", "OggS\x00\x02\x00\x00", attachment.Audio, true},
+		{"pdf", "%PDF-1.7\n", attachment.Document, true},
+		{"unknown iso brand", "\x00\x00\x00\x18ftypheic\x00\x00\x00\x00", attachment.Document, false},
+		{"text", "hello, world", attachment.Document, false},
+		{"empty", "", attachment.Document, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := attachment.DetectContentType([]byte(tt.header))
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
+
+func TestReconcileType(t *testing.T) {
+	t.Parallel()
+
+	const png = "\x89PNG\r\n\x1a\n"
+
+	tests := []struct {
+		name     string
+		policy   string
+		fileName string
+		header   string
+		want     attachment.AType
+	}{
+		{"extension ignores content", attachment.DetectExtension, "shot.bin", png, attachment.Document},
+		{"empty policy is extension", "", "notes.jpg", "text", attachment.Photo},
+		{"content without extension", attachment.DetectContent, "screenshot", png, attachment.Photo},
+		{"content ignores extension", attachment.DetectContent, "sound.wav", "RIFF....WAVE", attachment.Document},
+		{"both prefers content", attachment.DetectBoth, "shot.bin", png, attachment.Photo},
+		{"both demotes a renamed file", attachment.DetectBoth, "notes.jpg", "text", attachment.Document},
+		{"both keeps an unsigned extension", attachment.DetectBoth, "sound.wav", "RIFF....WAVE", attachment.Audio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, attachment.ReconcileType(tt.policy, tt.fileName, []byte(tt.header)))
+		})
+	}
+}
+
+func TestDetectMIMEType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		contentType string
+		want        attachment.AType
+		wantOK      bool
+	}{
+		{"image/jpeg", attachment.Photo, true},
+		{"image/png; charset=binary", attachment.Photo, true},
+		{"image/gif", attachment.Video, true},
+		{"video/mp4", attachment.Video, true},
+		{"audio/mpeg", attachment.Audio, true},
+		{"application/pdf", attachment.Document, true},
+		{"image/svg+xml", attachment.Document, true},
+		{"application/octet-stream", attachment.Document, false},
+		{"", attachment.Document, false},
+		{"not a type", attachment.Document, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := attachment.DetectMIMEType(tt.contentType)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOK, ok)
+		})
+	}
+}
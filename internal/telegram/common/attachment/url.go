@@ -0,0 +1,181 @@
+package attachment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// URL modes for URLFileOpener.
+const (
+	// URLModeTelegram passes the URL to Telegram, which downloads the file.
+	URLModeTelegram = "telegram"
+	// URLModeDownload downloads the file and uploads it like a local one.
+	URLModeDownload = "download"
+)
+
+// IsURL reports whether an attachment argument is an http or https URL rather
+// than a local path.
+func IsURL(arg string) bool {
+	lower := strings.ToLower(arg)
+
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// URLFileOpener opens http and https URLs and leaves every other path to
+// Files. With URLModeTelegram the URL is only passed on. With URLModeDownload a
+// HEAD request supplies the size for Loader's limits and the Content-Type for
+// the media type, and the body is downloaded only when the upload reads it.
+// When HEAD fails, a GET for the first byte supplies them instead.
+type URLFileOpener struct {
+	Mode  string
+	Files FileOpener
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+	// Context bounds every request, including the download during the
+	// upload. It defaults to context.Background().
+	Context context.Context
+}
+
+// Open opens name as described on URLFileOpener.
+func (o *URLFileOpener) Open(name string) (*OpenedFile, error) {
+	if !IsURL(name) {
+		return o.Files.Open(name)
+	}
+
+	parsed, err := url.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", name, err)
+	}
+	fileName := urlFileName(parsed, "")
+
+	if o.Mode == URLModeTelegram {
+		return &OpenedFile{URL: name, Name: fileName}, nil
+	}
+
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// Some servers reject HEAD or leave out its Content-Length. Asking for the
+	// first byte then gets the size from Content-Range, or from Content-Length
+	// when the server ignores the range, without downloading the file.
+	sizeBytes, header, err := probeURL(ctx, client, http.MethodHead, name)
+	if err != nil {
+		if sizeBytes, header, err = probeURL(ctx, client, http.MethodGet, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return &OpenedFile{
+		File:        &urlBody{ctx: ctx, client: client, url: name, sizeBytes: sizeBytes},
+		SizeBytes:   sizeBytes,
+		Name:        urlFileName(parsed, header.Get("Content-Disposition")),
+		ContentType: header.Get("Content-Type"),
+	}, nil
+}
+
+// probeURL requests rawURL with method, a GET only for its first byte, and
+// returns the size of the file and the response headers. The body is not read.
+func probeURL(ctx context.Context, client *http.Client, method, rawURL string) (int64, http.Header, error) {
+	request, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	if method == http.MethodGet {
+		request.Header.Set("Range", "bytes=0-0")
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	_ = response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusPartialContent:
+		// Content-Range is "bytes 0-0/<size>".
+		_, total, _ := strings.Cut(response.Header.Get("Content-Range"), "/")
+		if sizeBytes, parseErr := strconv.ParseInt(total, 10, 64); parseErr == nil && sizeBytes >= 0 {
+			return sizeBytes, response.Header, nil
+		}
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		return 0, nil, fmt.Errorf("%s %s: %s", method, rawURL, response.Status)
+	case response.ContentLength >= 0:
+		return response.ContentLength, response.Header, nil
+	}
+
+	return 0, nil, fmt.Errorf(
+		"%s %s: the server sent no Content-Length, so the size limits cannot be checked", method, rawURL,
+	)
+}
+
+// urlFileName prefers the file name from a Content-Disposition header, then
+// the last segment of the URL path.
+func urlFileName(parsed *url.URL, contentDisposition string) string {
+	if _, params, err := mime.ParseMediaType(contentDisposition); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+	if base := path.Base(parsed.Path); base != "/" && base != "." {
+		return base
+	}
+
+	return parsed.Hostname()
+}
+
+// urlBody downloads the file on the first Read, so files are not fetched
+// until their album is uploaded, and fails if the body is larger than the
+// size reported when it was opened.
+type urlBody struct {
+	ctx       context.Context
+	client    *http.Client
+	url       string
+	sizeBytes int64
+	read      int64
+	body      io.ReadCloser
+}
+
+func (b *urlBody) Read(p []byte) (int, error) {
+	if b.body == nil {
+		request, err := http.NewRequestWithContext(b.ctx, http.MethodGet, b.url, nil)
+		if err != nil {
+			return 0, fmt.Errorf("GET %s: %w", b.url, err)
+		}
+		response, err := b.client.Do(request)
+		if err != nil {
+			return 0, fmt.Errorf("GET %s: %w", b.url, err)
+		}
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return 0, errors.Join(fmt.Errorf("GET %s: %s", b.url, response.Status), response.Body.Close())
+		}
+		b.body = response.Body
+	}
+
+	n, err := b.body.Read(p)
+	b.read += int64(n)
+	if b.read > b.sizeBytes {
+		return n, fmt.Errorf("GET %s: body is larger than the %d bytes reported", b.url, b.sizeBytes)
+	}
+
+	return n, err
+}
+
+// Close closes the response body if the download was started.
+func (b *urlBody) Close() error {
+	if b.body == nil {
+		return nil
+	}
+
+	return b.body.Close()
+}
@@ -0,0 +1,197 @@
+package attachment_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+// newFileServer serves body at every path. headSize overrides the
+// Content-Length HEAD reports, and a negative value omits it.
+func newFileServer(t *testing.T, body string, headSize int, header http.Header) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.png" {
+			http.NotFound(w, r)
+
+			return
+		}
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if r.Method == http.MethodHead {
+			if headSize >= 0 {
+				w.Header().Set("Content-Length", strconv.Itoa(headSize))
+			}
+
+			return
+		}
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestIsURL(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, attachment.IsURL("https://example.com/a.png"))
+	assert.True(t, attachment.IsURL("HTTP://example.com/a.png"))
+	assert.False(t, attachment.IsURL("ftp://example.com/a.png"))
+	assert.False(t, attachment.IsURL("reports/https.png"))
+}
+
+func TestURLFileOpener_Telegram(t *testing.T) {
+	t.Parallel()
+
+	opener := &attachment.URLFileOpener{Mode: attachment.URLModeTelegram}
+
+	opened, err := opener.Open("https://example.com/charts/daily.png?v=2")
+	require.NoError(t, err)
+	assert.Nil(t, opened.File)
+	assert.Equal(t, "https://example.com/charts/daily.png?v=2", opened.URL)
+	assert.Equal(t, "daily.png", opened.Name)
+}
+
+func TestURLFileOpener_Download(t *testing.T) {
+	t.Parallel()
+
+	server := newFileServer(t, "report body", len("report body"), http.Header{
+		"Content-Type":        {"application/pdf"},
+		"Content-Disposition": {`attachment; filename="report.pdf"`},
+	})
+	opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client()}
+
+	opened, err := opener.Open(server.URL + "/download?id=7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len("report body")), opened.SizeBytes)
+	assert.Equal(t, "report.pdf", opened.Name)
+	assert.Equal(t, "application/pdf", opened.ContentType)
+	assert.Empty(t, opened.URL)
+
+	body, err := io.ReadAll(opened.File)
+	require.NoError(t, err)
+	assert.Equal(t, "report body", string(body))
+	require.NoError(t, opened.File.Close())
+}
+
+func TestURLFileOpener_HEADFallback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// ranges makes the server answer a ranged GET with its first byte.
+		ranges bool
+	}{
+		{name: "ranged GET", ranges: true},
+		{name: "server ignores the range", ranges: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodHead {
+					w.WriteHeader(http.StatusMethodNotAllowed)
+
+					return
+				}
+				w.Header().Set("Content-Type", "application/pdf")
+				if tt.ranges && r.Header.Get("Range") == "bytes=0-0" {
+					w.Header().Set("Content-Range", "bytes 0-0/11")
+					w.WriteHeader(http.StatusPartialContent)
+					_, _ = io.WriteString(w, "r")
+
+					return
+				}
+				_, _ = io.WriteString(w, "report body")
+			}))
+			t.Cleanup(server.Close)
+			opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client()}
+
+			opened, err := opener.Open(server.URL + "/report.pdf")
+			require.NoError(t, err)
+			assert.Equal(t, int64(len("report body")), opened.SizeBytes)
+			assert.Equal(t, "application/pdf", opened.ContentType)
+
+			body, err := io.ReadAll(opened.File)
+			require.NoError(t, err)
+			assert.Equal(t, "report body", string(body))
+			require.NoError(t, opened.File.Close())
+		})
+	}
+}
+
+func TestURLFileOpener_Context(t *testing.T) {
+	t.Parallel()
+
+	server := newFileServer(t, "report body", len("report body"), nil)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client(), Context: ctx}
+
+	_, err := opener.Open(server.URL + "/report.pdf")
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestURLFileOpener_DelegatesPaths(t *testing.T) {
+	t.Parallel()
+
+	file := &attachment.OpenedFile{File: newMockReadCloser("x"), SizeBytes: 1}
+	opener := &attachment.URLFileOpener{
+		Mode:  attachment.URLModeDownload,
+		Files: &mockFileOpener{files: map[string]*attachment.OpenedFile{"local.txt": file}},
+	}
+
+	opened, err := opener.Open("local.txt")
+	require.NoError(t, err)
+	assert.Same(t, file, opened)
+}
+
+func TestURLFileOpener_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFileServer(t, "", 0, nil)
+		opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client()}
+
+		_, err := opener.Open(server.URL + "/missing.png")
+		require.EqualError(t, err, "GET "+server.URL+"/missing.png: 404 Not Found")
+	})
+
+	t.Run("no content length", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFileServer(t, "", -1, http.Header{"Transfer-Encoding": {"chunked"}})
+		opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client()}
+
+		_, err := opener.Open(server.URL + "/stream.mp4")
+		require.ErrorContains(t, err, "the server sent no Content-Length")
+	})
+
+	t.Run("body larger than reported", func(t *testing.T) {
+		t.Parallel()
+
+		server := newFileServer(t, "more than four bytes", 4, nil)
+		opener := &attachment.URLFileOpener{Mode: attachment.URLModeDownload, Client: server.Client()}
+
+		opened, err := opener.Open(server.URL + "/a.txt")
+		require.NoError(t, err)
+		_, err = io.ReadAll(opened.File)
+		require.ErrorContains(t, err, "body is larger than the 4 bytes reported")
+		require.NoError(t, opened.File.Close())
+	})
+}
@@ -0,0 +1,148 @@
+// Package entity describes formatting as Telegram MessageEntity objects, an
+// alternative to a parse mode that needs no escaping.
+// See https://core.telegram.org/bots/api#messageentity.
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf16"
+)
+
+// MessageEntity marks a range of the text. Offset and Length are in UTF-16
+// code units.
+type MessageEntity struct {
+	Type          string          `json:"type"`
+	Offset        int             `json:"offset"`
+	Length        int             `json:"length"`
+	URL           string          `json:"url,omitempty"`
+	User          json.RawMessage `json:"user,omitempty"`
+	Language      string          `json:"language,omitempty"`
+	CustomEmojiID string          `json:"custom_emoji_id,omitempty"`
+}
+
+var types = []string{
+	"mention", "hashtag", "cashtag", "bot_command", "url", "email", "phone_number",
+	"bold", "italic", "underline", "strikethrough", "spoiler",
+	"blockquote", "expandable_blockquote", "code", "pre",
+	"text_link", "text_mention", "custom_emoji",
+}
+
+// styles can contain and be part of any entity except code and pre.
+var styles = []string{"bold", "italic", "underline", "strikethrough", "spoiler"}
+
+// Validate checks entities against text the way Telegram does: each entity
+// lies inside the text, and entities either do not overlap or nest as allowed.
+func Validate(text string, entities []MessageEntity) error {
+	var validationErrors []string
+
+	textLen := UTF16Len(text)
+	for i, e := range entities {
+		name := fmt.Sprintf("entity %d (%s)", i, e.Type)
+
+		if !slices.Contains(types, e.Type) {
+			validationErrors = append(validationErrors, fmt.Sprintf("entity %d has unknown type %q", i, e.Type))
+
+			continue
+		}
+		if e.Offset < 0 || e.Length <= 0 {
+			validationErrors = append(
+				validationErrors, name+": offset must not be negative and length must be positive",
+			)
+		} else if e.Offset+e.Length > textLen {
+			validationErrors = append(validationErrors, fmt.Sprintf(
+				"%s: offset %d and length %d end after the text, which is %d UTF-16 code units long",
+				name, e.Offset, e.Length, textLen,
+			))
+		}
+
+		switch {
+		case e.Type == "text_link" && e.URL == "":
+			validationErrors = append(validationErrors, name+": url is required")
+		case e.Type == "text_mention" && len(e.User) == 0:
+			validationErrors = append(validationErrors, name+": user is required")
+		case e.Type == "custom_emoji" && e.CustomEmojiID == "":
+			validationErrors = append(validationErrors, name+": custom_emoji_id is required")
+		}
+	}
+
+	for i := range entities {
+		for j := i + 1; j < len(entities); j++ {
+			if err := checkPair(entities[i], entities[j]); err != "" {
+				validationErrors = append(validationErrors, fmt.Sprintf(
+					"entity %d (%s) and entity %d (%s) %s",
+					i, entities[i].Type, j, entities[j].Type, err,
+				))
+			}
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
+
+// checkPair describes why two entities cannot be combined, or returns "".
+func checkPair(a, b MessageEntity) string {
+	aEnd, bEnd := a.Offset+a.Length, b.Offset+b.Length
+	if aEnd <= b.Offset || bEnd <= a.Offset {
+		return ""
+	}
+
+	nested := (a.Offset <= b.Offset && bEnd <= aEnd) || (b.Offset <= a.Offset && aEnd <= bEnd)
+	if !nested {
+		return "cross each other"
+	}
+
+	aQuote, bQuote := isBlockquote(a.Type), isBlockquote(b.Type)
+	aStyle, bStyle := slices.Contains(styles, a.Type), slices.Contains(styles, b.Type)
+
+	switch {
+	case aQuote && bQuote:
+		return "are nested blockquotes"
+	case aQuote || bQuote, aStyle && bStyle:
+		return ""
+	case aStyle && !isCode(b.Type), bStyle && !isCode(a.Type):
+		return ""
+	default:
+		return "cannot be nested"
+	}
+}
+
+func isBlockquote(t string) bool {
+	return t == "blockquote" || t == "expandable_blockquote"
+}
+
+func isCode(t string) bool {
+	return t == "code" || t == "pre"
+}
+
+// UTF16Len returns the length of text in UTF-16 code units, the unit Telegram
+// uses for entity offsets and lengths.
+func UTF16Len(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+
+	return n
+}
+
+// TruncateUTF16 returns the longest prefix of text that is at most limit
+// UTF-16 code units long, without splitting a character.
+func TruncateUTF16(text string, limit int) string {
+	n := 0
+	for i, r := range text {
+		n += utf16.RuneLen(r)
+		if n > limit {
+			return text[:i]
+		}
+	}
+
+	return text
+}
@@ -0,0 +1,161 @@
+package entity_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	// "🚀" is two UTF-16 code units, so the text is 14 units long.
+	const text = "🚀 Deploy done"
+
+	tests := []struct {
+		name     string
+		entities []entity.MessageEntity
+		wantErr  string
+	}{
+		{
+			name: "valid nested styles and link",
+			entities: []entity.MessageEntity{
+				{Type: "bold", Offset: 3, Length: 11},
+				{Type: "italic", Offset: 3, Length: 6},
+				{Type: "text_link", Offset: 10, Length: 4, URL: "https://example.com"},
+			},
+		},
+		{
+			name:     "entity ending at the text end",
+			entities: []entity.MessageEntity{{Type: "code", Offset: 0, Length: 14}},
+		},
+		{
+			name: "style inside a blockquote",
+			entities: []entity.MessageEntity{
+				{Type: "blockquote", Offset: 0, Length: 14},
+				{Type: "bold", Offset: 0, Length: 2},
+			},
+		},
+		{
+			name:     "entity past the text end",
+			entities: []entity.MessageEntity{{Type: "bold", Offset: 10, Length: 5}},
+			wantErr:  "entity 0 (bold): offset 10 and length 5 end after the text, which is 14 UTF-16 code units long",
+		},
+		{
+			name:     "negative offset",
+			entities: []entity.MessageEntity{{Type: "bold", Offset: -1, Length: 2}},
+			wantErr:  "entity 0 (bold): offset must not be negative and length must be positive",
+		},
+		{
+			name:     "unknown type",
+			entities: []entity.MessageEntity{{Type: "blink", Offset: 0, Length: 2}},
+			wantErr:  `entity 0 has unknown type "blink"`,
+		},
+		{
+			name:     "text link without url",
+			entities: []entity.MessageEntity{{Type: "text_link", Offset: 0, Length: 2}},
+			wantErr:  "entity 0 (text_link): url is required",
+		},
+		{
+			name: "crossing entities",
+			entities: []entity.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 8},
+				{Type: "italic", Offset: 5, Length: 9},
+			},
+			wantErr: "entity 0 (bold) and entity 1 (italic) cross each other",
+		},
+		{
+			name: "style inside code",
+			entities: []entity.MessageEntity{
+				{Type: "code", Offset: 3, Length: 6},
+				{Type: "bold", Offset: 3, Length: 2},
+			},
+			wantErr: "entity 0 (code) and entity 1 (bold) cannot be nested",
+		},
+		{
+			name: "link inside a link",
+			entities: []entity.MessageEntity{
+				{Type: "text_link", Offset: 3, Length: 6, URL: "https://example.com"},
+				{Type: "url", Offset: 3, Length: 2},
+			},
+			wantErr: "entity 0 (text_link) and entity 1 (url) cannot be nested",
+		},
+		{
+			name: "nested blockquotes",
+			entities: []entity.MessageEntity{
+				{Type: "blockquote", Offset: 0, Length: 14},
+				{Type: "expandable_blockquote", Offset: 3, Length: 6},
+			},
+			wantErr: "entity 0 (blockquote) and entity 1 (expandable_blockquote) are nested blockquotes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := entity.Validate(text, tt.entities)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+
+				return
+			}
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMessageEntity_JSON(t *testing.T) {
+	t.Parallel()
+
+	var entities []entity.MessageEntity
+	require.NoError(t, json.Unmarshal([]byte(`[
+		{"type":"text_mention","offset":0,"length":3,"user":{"id":42,"is_bot":false,"first_name":"Ann"}},
+		{"type":"pre","offset":4,"length":2,"language":"go"}
+	]`), &entities))
+
+	encoded, err := json.Marshal(entities)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"type":"text_mention","offset":0,"length":3,"user":{"id":42,"is_bot":false,"first_name":"Ann"}},
+		{"type":"pre","offset":4,"length":2,"language":"go"}
+	]`, string(encoded))
+}
+
+func TestUTF16Len(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, entity.UTF16Len(""))
+	assert.Equal(t, 5, entity.UTF16Len("héllo"))
+	assert.Equal(t, 3, entity.UTF16Len("🚀a"))
+}
+
+func TestTruncateUTF16(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "short", text: "abc", limit: 3, want: "abc"},
+		{name: "ascii", text: "abcd", limit: 3, want: "abc"},
+		{name: "surrogate pair not split", text: "ab🦉", limit: 3, want: "ab"},
+		{name: "surrogate pair fits", text: "a🦉b", limit: 3, want: "a🦉"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := entity.TruncateUTF16(tt.text, tt.limit)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, entity.UTF16Len(got), tt.limit)
+		})
+	}
+}
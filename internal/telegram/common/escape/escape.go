@@ -0,0 +1,101 @@
+// Package escape makes plain text safe to embed in formatted Telegram messages,
+// following https://core.telegram.org/bots/api#formatting-options.
+package escape
+
+import "strings"
+
+// markdownV2Reserved are the characters MarkdownV2 requires to be escaped
+// outside of entities. The backslash itself escapes, so it is escaped too.
+const markdownV2Reserved = "\\_*[]()~`>#+-=|{}.!"
+
+// commonMarkPunctuation is the ASCII punctuation CommonMark allows escaping
+// with a backslash.
+const commonMarkPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+var htmlReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// MarkdownV2 escapes text for MarkdownV2 outside of pre, code and link URLs.
+func MarkdownV2(text string) string {
+	return backslashEscape(text, markdownV2Reserved)
+}
+
+// MarkdownV2Code escapes text for the inside of a MarkdownV2 pre or code
+// entity, where only "`" and "\" are reserved.
+func MarkdownV2Code(text string) string {
+	return backslashEscape(text, "\\`")
+}
+
+// MarkdownV2URL escapes text for the (...) part of a MarkdownV2 inline link,
+// where only ")" and "\" are reserved.
+func MarkdownV2URL(text string) string {
+	return backslashEscape(text, "\\)")
+}
+
+// HTML escapes text for Telegram HTML, both in text and in attribute values.
+func HTML(text string) string {
+	return htmlReplacer.Replace(text)
+}
+
+// CommonMark escapes text for Rich Markdown, which follows CommonMark.
+func CommonMark(text string) string {
+	return backslashEscape(text, commonMarkPunctuation)
+}
+
+// Text escapes text for the given CLI format: markdown, html, rich-markdown,
+// rich-html or gfm. Plain text is returned unchanged.
+func Text(format, text string) string {
+	switch format {
+	case "markdown":
+		return MarkdownV2(text)
+	case "html", "rich-html":
+		return HTML(text)
+	case "rich-markdown", "gfm":
+		return CommonMark(text)
+	default:
+		return text
+	}
+}
+
+// Code escapes text for the inside of a code or pre entity in the given CLI
+// format. CommonMark code spans take text literally.
+func Code(format, text string) string {
+	switch format {
+	case "markdown":
+		return MarkdownV2Code(text)
+	case "html", "rich-html":
+		return HTML(text)
+	default:
+		return text
+	}
+}
+
+// URL escapes text for a link URL in the given CLI format.
+func URL(format, text string) string {
+	switch format {
+	case "markdown":
+		return MarkdownV2URL(text)
+	case "html", "rich-html":
+		return HTML(text)
+	case "rich-markdown", "gfm":
+		return backslashEscape(text, "\\()")
+	default:
+		return text
+	}
+}
+
+func backslashEscape(text, reserved string) string {
+	if !strings.ContainsAny(text, reserved) {
+		return text
+	}
+
+	var b strings.Builder
+	b.Grow(len(text) + len(text)/4)
+	for _, r := range text {
+		if r < 0x80 && strings.ContainsRune(reserved, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
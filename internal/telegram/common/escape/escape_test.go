@@ -0,0 +1,106 @@
+package escape_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/escape"
+)
+
+func TestMarkdownV2(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "nothing to escape", text: "Deploy 42 done", want: "Deploy 42 done"},
+		{name: "sentence", text: "v1.2.3 is out!", want: `v1\.2\.3 is out\!`},
+		{
+			name: "every reserved character",
+			text: "_*[]()~`>#+-=|{}.!",
+			want: `\_\*\[\]\(\)\~\` + "`" + `\>\#\+\-\=\|\{\}\.\!`,
+		},
+		{name: "backslash", text: `C:\logs`, want: `C:\\logs`},
+		{name: "not reserved", text: `a,b;c:d?e@f$g%h^i&j'k"l/m`, want: `a,b;c:d?e@f$g%h^i&j'k"l/m`},
+		{name: "unicode untouched", text: "привет — 🦉.", want: `привет — 🦉\.`},
+		{name: "empty", text: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, escape.MarkdownV2(tt.text))
+		})
+	}
+}
+
+func TestMarkdownV2CodeAndURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a.b \\` \\\\ (x)", escape.MarkdownV2Code("a.b ` \\ (x)"))
+	assert.Equal(t, `https://example.com/a_(b\)?q=1\\`, escape.MarkdownV2URL(`https://example.com/a_(b)?q=1\`))
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "nothing to escape", text: "v1.2.3 is out!", want: "v1.2.3 is out!"},
+		{name: "tags", text: "<b>bold</b>", want: "&lt;b&gt;bold&lt;/b&gt;"},
+		{name: "ampersand first", text: "&lt; & >", want: "&amp;lt; &amp; &gt;"},
+		{name: "attribute quote", text: `say "hi"`, want: "say &quot;hi&quot;"},
+		{name: "apostrophe untouched", text: "it's", want: "it's"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, escape.HTML(tt.text))
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: "", want: "1.5 <ms> *fast*"},
+		{format: "markdown", want: `1\.5 <ms\> \*fast\*`},
+		{format: "html", want: "1.5 &lt;ms&gt; *fast*"},
+		{format: "rich-html", want: "1.5 &lt;ms&gt; *fast*"},
+		{format: "rich-markdown", want: `1\.5 \<ms\> \*fast\*`},
+		{format: "gfm", want: `1\.5 \<ms\> \*fast\*`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, escape.Text(tt.format, "1.5 <ms> *fast*"))
+		})
+	}
+}
+
+func TestCodeAndURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "a\\`b.c", escape.Code("markdown", "a`b.c"))
+	assert.Equal(t, "a`b.c", escape.Code("rich-markdown", "a`b.c"))
+	assert.Equal(t, "a&lt;b", escape.Code("html", "a<b"))
+	assert.Equal(t, `x\)`, escape.URL("markdown", "x)"))
+	assert.Equal(t, `\(x\)`, escape.URL("rich-markdown", "(x)"))
+	assert.Equal(t, "?a=1&amp;b=2", escape.URL("html", "?a=1&b=2"))
+	assert.Equal(t, "x)", escape.URL("", "x)"))
+}
@@ -0,0 +1,419 @@
+// Package gfm converts GitHub Flavored Markdown, as written in release notes
+// and pull requests, to the HTML accepted by Telegram.
+//
+// Telegram HTML has no headings, lists, tables or images, so ToHTML maps them
+// to what it has: headings become bold, list items become bullet or numbered
+// lines, tables become monospaced pre blocks and images become links.
+// ToRichHTML keeps the document structure for rich messages, which support it,
+// and only turns images into links. Footnotes become plain text in both, as
+// a message cannot link within itself.
+package gfm
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/escape"
+)
+
+const thematicBreak = "──────────"
+
+var lineBreakTag = regexp.MustCompile(`(?i)^<br\s*/?>$`)
+
+// ToHTML converts markdown to Telegram HTML, for the "html" parse mode.
+func ToHTML(markdown string) string {
+	source := []byte(markdown)
+	doc := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithASTTransformers(util.Prioritized(plainFootnotes{}, 1000))),
+	).Parser().Parse(text.NewReader(source))
+
+	r := &htmlRenderer{source: source}
+
+	return strings.TrimSpace(r.blocks(doc, "\n\n"))
+}
+
+// ToRichHTML converts markdown to Telegram Rich HTML.
+func ToRichHTML(markdown string) (string, error) {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM, extension.Footnote),
+		goldmark.WithParserOptions(parser.WithASTTransformers(util.Prioritized(plainFootnotes{}, 1000))),
+		goldmark.WithRendererOptions(renderer.WithNodeRenderers(util.Prioritized(imageLinkRenderer{}, 100))),
+	)
+
+	var b bytes.Buffer
+	if err := md.Convert([]byte(markdown), &b); err != nil {
+		return "", fmt.Errorf("convert markdown: %w", err)
+	}
+
+	return strings.TrimSpace(b.String()), nil
+}
+
+// imageLinkRenderer renders images as links to the image, since a message
+// cannot embed them.
+type imageLinkRenderer struct{}
+
+func (r imageLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(ast.KindImage, r.renderImage)
+}
+
+func (imageLinkRenderer) renderImage(
+	w util.BufWriter, source []byte, node ast.Node, entering bool,
+) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	image, _ := node.(*ast.Image)
+	_, _ = w.WriteString(`<a href="` + escape.HTML(string(image.Destination)) + `">`)
+	_, _ = w.WriteString(escape.HTML(imageLabel(image, source)))
+	_, _ = w.WriteString("</a>")
+
+	return ast.WalkSkipChildren, nil
+}
+
+// plainFootnotes rewrites footnotes as text: a reference becomes [1] and each
+// note becomes paragraphs at the end of the document, the first one starting
+// with [1]. It runs after the footnote extension has collected the notes.
+type plainFootnotes struct{}
+
+func (plainFootnotes) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	var links, backlinks, lists []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *east.FootnoteLink:
+			links = append(links, n)
+		case *east.FootnoteBacklink:
+			backlinks = append(backlinks, n)
+		case *east.FootnoteList:
+			lists = append(lists, n)
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range links {
+		link, _ := n.(*east.FootnoteLink)
+		link.Parent().ReplaceChild(link.Parent(), link, footnoteMarker(link.Index))
+	}
+	for _, n := range backlinks {
+		n.Parent().RemoveChild(n.Parent(), n)
+	}
+	for _, list := range lists {
+		for note := list.FirstChild(); note != nil; note = note.NextSibling() {
+			footnote, _ := note.(*east.Footnote)
+			if first, ok := footnote.FirstChild().(*ast.Paragraph); ok {
+				first.InsertBefore(first, first.FirstChild(), ast.NewString([]byte(" ")))
+				first.InsertBefore(first, first.FirstChild(), footnoteMarker(footnote.Index))
+			} else {
+				marker := ast.NewParagraph()
+				marker.AppendChild(marker, footnoteMarker(footnote.Index))
+				footnote.InsertBefore(footnote, footnote.FirstChild(), marker)
+			}
+
+			for child := footnote.FirstChild(); child != nil; {
+				next := child.NextSibling()
+				doc.InsertBefore(doc, list, child)
+				child = next
+			}
+		}
+		doc.RemoveChild(doc, list)
+	}
+}
+
+func footnoteMarker(index int) *ast.String {
+	return ast.NewString(fmt.Appendf(nil, "[%d]", index))
+}
+
+func imageLabel(image *ast.Image, source []byte) string {
+	if alt := plainText(image, source); alt != "" {
+		return alt
+	}
+
+	return "image"
+}
+
+type htmlRenderer struct {
+	source []byte
+}
+
+// blocks renders the block children of node joined by sep.
+func (r *htmlRenderer) blocks(node ast.Node, sep string) string {
+	var parts []string
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if part := r.block(child, 0); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, sep)
+}
+
+func (r *htmlRenderer) block(node ast.Node, depth int) string {
+	switch n := node.(type) {
+	case *ast.Paragraph, *ast.TextBlock:
+		return r.inlines(n)
+	case *ast.Heading:
+		return "<b>" + r.inlines(n) + "</b>"
+	case *ast.ThematicBreak:
+		return thematicBreak
+	case *ast.FencedCodeBlock:
+		code := escape.HTML(strings.TrimSuffix(r.lines(n), "\n"))
+		if language := n.Language(r.source); len(language) > 0 {
+			return `<pre><code class="language-` + escape.HTML(string(language)) + `">` + code + "</code></pre>"
+		}
+
+		return "<pre>" + code + "</pre>"
+	case *ast.CodeBlock:
+		return "<pre>" + escape.HTML(strings.TrimSuffix(r.lines(n), "\n")) + "</pre>"
+	case *ast.Blockquote:
+		return "<blockquote>" + r.blocks(n, "\n\n") + "</blockquote>"
+	case *ast.List:
+		return r.list(n, depth)
+	case *east.Table:
+		return r.table(n)
+	case *ast.HTMLBlock:
+		// Telegram rejects tags it does not know, so raw HTML is shown as
+		// written.
+		return escape.HTML(strings.TrimSuffix(r.lines(n), "\n"))
+	default:
+		return r.blocks(n, "\n\n")
+	}
+}
+
+// list renders one line per item. Nested lists are indented by two spaces per
+// level.
+func (r *htmlRenderer) list(list *ast.List, depth int) string {
+	indent := strings.Repeat("  ", depth)
+	number := list.Start
+
+	var lines []string
+	for item := list.FirstChild(); item != nil; item = item.NextSibling() {
+		marker := "•"
+		if list.IsOrdered() {
+			marker = fmt.Sprintf("%d.", number)
+			number++
+		}
+
+		var parts []string
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			if nested, ok := child.(*ast.List); ok {
+				parts = append(parts, r.list(nested, depth+1))
+
+				continue
+			}
+			part := r.block(child, depth+1)
+			if len(parts) == 0 {
+				part = indent + marker + " " + part
+			}
+			parts = append(parts, part)
+		}
+		if len(parts) == 0 {
+			parts = append(parts, indent+marker)
+		}
+		lines = append(lines, strings.Join(parts, "\n"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// table renders a table as aligned columns of plain text in a pre block.
+func (r *htmlRenderer) table(table *east.Table) string {
+	var rows [][]string
+	for row := table.FirstChild(); row != nil; row = row.NextSibling() {
+		var cells []string
+		for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+			cells = append(cells, plainText(cell, r.source))
+		}
+		rows = append(rows, cells)
+	}
+
+	widths := make([]int, len(table.Alignments))
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		cells := make([]string, len(widths))
+		for j := range widths {
+			cell := ""
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = pad(cell, widths[j], table.Alignments[j])
+		}
+		lines = append(lines, strings.TrimRight(strings.Join(cells, " | "), " "))
+
+		if i == 0 {
+			rules := make([]string, len(widths))
+			for j, width := range widths {
+				rules[j] = strings.Repeat("-", width)
+			}
+			lines = append(lines, strings.Join(rules, "-|-"))
+		}
+	}
+
+	return "<pre>" + escape.HTML(strings.Join(lines, "\n")) + "</pre>"
+}
+
+func pad(cell string, width int, alignment east.Alignment) string {
+	gap := width - utf8.RuneCountInString(cell)
+	switch alignment {
+	case east.AlignRight:
+		return strings.Repeat(" ", gap) + cell
+	case east.AlignCenter:
+		return strings.Repeat(" ", gap/2) + cell + strings.Repeat(" ", gap-gap/2)
+	case east.AlignLeft, east.AlignNone:
+	}
+
+	return cell + strings.Repeat(" ", gap)
+}
+
+func (r *htmlRenderer) inlines(node ast.Node) string {
+	var b strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		b.WriteString(r.inline(child))
+	}
+
+	return b.String()
+}
+
+func (r *htmlRenderer) inline(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.Text:
+		s := escape.HTML(unescape(n.Value(r.source)))
+		switch {
+		case n.HardLineBreak():
+			s += "\n"
+		case n.SoftLineBreak():
+			s += " "
+		}
+
+		return s
+	case *ast.String:
+		return escape.HTML(string(n.Value))
+	case *ast.Emphasis:
+		tag := "i"
+		if n.Level >= 2 {
+			tag = "b"
+		}
+
+		return "<" + tag + ">" + r.inlines(n) + "</" + tag + ">"
+	case *east.Strikethrough:
+		return "<s>" + r.inlines(n) + "</s>"
+	case *ast.CodeSpan:
+		return "<code>" + escape.HTML(codeText(n, r.source)) + "</code>"
+	case *ast.Link:
+		return `<a href="` + escape.HTML(string(n.Destination)) + `">` + r.inlines(n) + "</a>"
+	case *ast.AutoLink:
+		url := string(n.URL(r.source))
+		if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(url, "mailto:") {
+			url = "mailto:" + url
+		}
+
+		return `<a href="` + escape.HTML(url) + `">` + escape.HTML(string(n.Label(r.source))) + "</a>"
+	case *ast.Image:
+		return `<a href="` + escape.HTML(string(n.Destination)) + `">` + escape.HTML(imageLabel(n, r.source)) + "</a>"
+	case *east.TaskCheckBox:
+		if n.IsChecked {
+			return "☑ "
+		}
+
+		return "☐ "
+	case *ast.RawHTML:
+		var raw strings.Builder
+		for i := range n.Segments.Len() {
+			segment := n.Segments.At(i)
+			raw.Write(segment.Value(r.source))
+		}
+		if lineBreakTag.MatchString(raw.String()) {
+			return "\n"
+		}
+
+		return escape.HTML(raw.String())
+	default:
+		return r.inlines(n)
+	}
+}
+
+func (r *htmlRenderer) lines(node ast.Node) string {
+	var b strings.Builder
+	lines := node.Lines()
+	for i := range lines.Len() {
+		line := lines.At(i)
+		b.Write(line.Value(r.source))
+	}
+
+	return b.String()
+}
+
+// plainText returns the text of node without any formatting.
+func plainText(node ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Text:
+			b.WriteString(unescape(n.Value(source)))
+			if n.SoftLineBreak() || n.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(n.Value)
+		case *ast.CodeSpan:
+			b.WriteString(codeText(n, source))
+
+			return ast.WalkSkipChildren, nil
+		case *ast.AutoLink:
+			b.Write(n.Label(source))
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	return b.String()
+}
+
+// codeText returns the content of a code span, where backslashes and entities
+// are literal.
+func codeText(span *ast.CodeSpan, source []byte) string {
+	var b strings.Builder
+	for child := span.FirstChild(); child != nil; child = child.NextSibling() {
+		switch n := child.(type) {
+		case *ast.Text:
+			b.Write(n.Value(source))
+		case *ast.String:
+			b.Write(n.Value)
+		}
+	}
+
+	return b.String()
+}
+
+// unescape resolves backslash escapes and character references, which the
+// parser leaves in text.
+func unescape(value []byte) string {
+	return string(util.ResolveEntityNames(util.ResolveNumericReferences(util.UnescapePunctuations(value))))
+}
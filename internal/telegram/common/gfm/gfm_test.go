@@ -0,0 +1,113 @@
+package gfm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/gfm"
+)
+
+func TestToHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{
+			name:     "heading and paragraphs",
+			markdown: "# Release <1.2>\n\nFirst line\nsame paragraph.\n\nSecond paragraph.",
+			want:     "<b>Release &lt;1.2&gt;</b>\n\nFirst line same paragraph.\n\nSecond paragraph.",
+		},
+		{
+			name:     "inline formatting",
+			markdown: "**bold**, _italic_, ~~gone~~ and `a<b`",
+			want:     "<b>bold</b>, <i>italic</i>, <s>gone</s> and <code>a&lt;b</code>",
+		},
+		{
+			name:     "links",
+			markdown: "[docs](https://example.com/?a=1&b=2), <https://example.com> and <dev@example.com>",
+			want: `<a href="https://example.com/?a=1&amp;b=2">docs</a>, ` +
+				`<a href="https://example.com">https://example.com</a> and ` +
+				`<a href="mailto:dev@example.com">dev@example.com</a>`,
+		},
+		{
+			name:     "images become links",
+			markdown: "![screenshot](https://example.com/a.png) ![](https://example.com/b.png)",
+			want: `<a href="https://example.com/a.png">screenshot</a> ` +
+				`<a href="https://example.com/b.png">image</a>`,
+		},
+		{
+			name:     "lists",
+			markdown: "- one\n- two\n  - nested\n- [x] done\n- [ ] todo\n\n3. three\n4. four",
+			want:     "• one\n• two\n  • nested\n• ☑ done\n• ☐ todo\n\n3. three\n4. four",
+		},
+		{
+			name:     "code blocks",
+			markdown: "```go\nfmt.Println(\"<hi>\")\n```\n\n    indented",
+			want: `<pre><code class="language-go">fmt.Println(&quot;&lt;hi&gt;&quot;)</code></pre>` +
+				"\n\n<pre>indented</pre>",
+		},
+		{
+			name:     "blockquote and thematic break",
+			markdown: "> quoted\n> text\n\n---\n\nafter",
+			want:     "<blockquote>quoted text</blockquote>\n\n──────────\n\nafter",
+		},
+		{
+			name: "table",
+			markdown: "| Name | Size | State |\n|:--|--:|:-:|\n" +
+				"| a.txt | 1 KB | ok |\n| longer-name | 100 KB | failed |",
+			want: "<pre>Name        |   Size | State\n" +
+				"------------|--------|-------\n" +
+				"a.txt       |   1 KB |   ok\n" +
+				"longer-name | 100 KB | failed</pre>",
+		},
+		{
+			name:     "backslash escapes and entities",
+			markdown: "\\*not bold\\* &amp; &copy; `\\*code\\* &amp;`",
+			want:     "*not bold* &amp; © <code>\\*code\\* &amp;amp;</code>",
+		},
+		{
+			name:     "raw html is escaped except line breaks",
+			markdown: "<details>x</details>\n\nend<br>line",
+			want:     "&lt;details&gt;x&lt;/details&gt;\n\nend\nline",
+		},
+		{
+			name:     "footnotes are plain text",
+			markdown: "Fixed a crash[^1].\n\n[^1]: note\n\nMore.",
+			want:     "Fixed a crash[1].\n\nMore.\n\n[1] note",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, gfm.ToHTML(tt.markdown))
+		})
+	}
+}
+
+func TestToRichHTML(t *testing.T) {
+	t.Parallel()
+
+	got, err := gfm.ToRichHTML("# Notes\n\n- **fixed** crash\n\n![chart](https://example.com/c.png)")
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"<h1>Notes</h1>\n<ul>\n<li><strong>fixed</strong> crash</li>\n</ul>\n"+
+			`<p><a href="https://example.com/c.png">chart</a></p>`,
+		got,
+	)
+}
+
+func TestToRichHTML_Footnotes(t *testing.T) {
+	t.Parallel()
+
+	got, err := gfm.ToRichHTML("Fixed a crash[^1].\n\n[^1]: See *the* issue.")
+	require.NoError(t, err)
+	assert.Equal(t, "<p>Fixed a crash[1].</p>\n<p>[1] See <em>the</em> issue.</p>", got)
+}
@@ -0,0 +1,322 @@
+package markup
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// htmlEntityTypes maps the supported tags to entity types. span, a, tg-emoji
+// and blockquote depend on their attributes and are resolved in startTag.
+var htmlEntityTypes = map[string]string{
+	"b":          "bold",
+	"strong":     "bold",
+	"i":          "italic",
+	"em":         "italic",
+	"u":          "underline",
+	"ins":        "underline",
+	"s":          "strikethrough",
+	"strike":     "strikethrough",
+	"del":        "strikethrough",
+	"tg-spoiler": "spoiler",
+	"span":       "spoiler",
+	"a":          "text_link",
+	"tg-emoji":   "custom_emoji",
+	"code":       "code",
+	"pre":        "pre",
+	"blockquote": "blockquote",
+}
+
+// htmlNamedEntities are the only named character references Telegram decodes.
+var htmlNamedEntities = map[string]rune{"lt": '<', "gt": '>', "amp": '&', "quot": '"'}
+
+// HTML parses Telegram's HTML subset.
+func HTML(source string) (*Result, error) {
+	b := &builder{source: source}
+
+	for i := 0; i < len(source); {
+		switch source[i] {
+		case '<':
+			next, err := b.tag(i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+		case '&':
+			r, size := decodeCharRef(source[i:])
+			if raw, ok := undecodedCharRef(source[i:]); ok && size == 1 {
+				b.writeChar(raw, raw)
+				i += len(raw)
+
+				break
+			}
+			b.writeChar(source[i:i+size], string(r))
+			i += size
+		default:
+			_, size := utf8.DecodeRuneInString(source[i:])
+			b.writeChar(source[i:i+size], source[i:i+size])
+			i += size
+		}
+	}
+
+	if e := b.top(); e != nil {
+		return nil, b.errorAt(e.pos, "tag <%s> is never closed", e.tag)
+	}
+
+	return b.result(), nil
+}
+
+// tag parses the start or end tag at pos and returns the offset after it.
+func (b *builder) tag(pos int) (int, error) {
+	source := b.source
+	i := pos + 1
+	isEnd := i < len(source) && source[i] == '/'
+	if isEnd {
+		i++
+	}
+
+	nameEnd := i
+	for nameEnd < len(source) && isTagNameByte(source[nameEnd]) {
+		nameEnd++
+	}
+	name := strings.ToLower(source[i:nameEnd])
+	if name == "" {
+		if isEnd {
+			return 0, b.errorAt(pos, `end tag has no name`)
+		}
+
+		return 0, b.errorAt(pos, `unescaped "<", write &lt; to show it`)
+	}
+
+	attrs, end, ok := parseAttributes(source, nameEnd)
+	if !ok {
+		return 0, b.errorAt(pos, `tag <%s%s> is not closed with ">"`, source[pos+1:i], name)
+	}
+
+	if isEnd {
+		if err := b.endTag(pos, name); err != nil {
+			return 0, err
+		}
+		b.token(TokenClose, source[pos:end], name)
+
+		return end, nil
+	}
+
+	if err := b.startTag(pos, name, attrs); err != nil {
+		return 0, err
+	}
+	b.openToken(source[pos:end], name, "</"+name+">")
+
+	return end, nil
+}
+
+func (b *builder) startTag(pos int, name string, attrs map[string]string) error {
+	entityType, ok := htmlEntityTypes[name]
+	if !ok {
+		return b.errorAt(pos, "unsupported tag <%s>", name)
+	}
+
+	switch name {
+	case "span":
+		if attrs["class"] != "tg-spoiler" {
+			return b.errorAt(pos, `tag <span> must have class="tg-spoiler"`)
+		}
+	case "a":
+		// Telegram ignores links without a target.
+		if attrs["href"] == "" {
+			entityType = ""
+		}
+	case "tg-emoji":
+		if attrs["emoji-id"] == "" {
+			return b.errorAt(pos, "tag <tg-emoji> must have an emoji-id attribute")
+		}
+	case "blockquote":
+		if _, ok = attrs["expandable"]; ok {
+			entityType = "expandable_blockquote"
+		}
+	}
+
+	e := b.push(entityType, name, pos)
+	e.URL = attrs["href"]
+	e.CustomEmojiID = attrs["emoji-id"]
+	if name == "code" {
+		e.Language = strings.TrimPrefix(attrs["class"], "language-")
+	}
+
+	return nil
+}
+
+func (b *builder) endTag(pos int, name string) error {
+	e := b.top()
+	if e == nil {
+		return b.errorAt(pos, "end tag </%s> has no matching start tag", name)
+	}
+	if e.tag != name {
+		return b.errorAt(pos, "end tag </%s> does not match <%s> opened at %s", name, e.tag, b.where(e.pos))
+	}
+
+	// <pre><code class="language-go"> is one pre entity with a language.
+	if parent := b.parent(); name == "code" && parent != nil && parent.tag == "pre" {
+		b.open = b.open[:len(b.open)-1]
+		if e.Language != "" {
+			parent.Language = e.Language
+		}
+
+		return nil
+	}
+	// Only pre entities carry a language.
+	if name == "code" {
+		e.Language = ""
+	}
+	b.pop()
+
+	return nil
+}
+
+func (b *builder) parent() *openEntity {
+	if len(b.open) < 2 {
+		return nil
+	}
+
+	return b.open[len(b.open)-2]
+}
+
+// parseAttributes reads attributes from pos up to the closing ">" and returns
+// the offset after it. Values may be quoted or not and may use character
+// references.
+func parseAttributes(source string, pos int) (map[string]string, int, bool) {
+	attrs := make(map[string]string)
+	i := pos
+	for {
+		for i < len(source) && isSpace(source[i]) {
+			i++
+		}
+		if i >= len(source) {
+			return nil, 0, false
+		}
+		if source[i] == '>' {
+			return attrs, i + 1, true
+		}
+
+		nameEnd := i
+		for nameEnd < len(source) && isTagNameByte(source[nameEnd]) {
+			nameEnd++
+		}
+		if nameEnd == i {
+			return nil, 0, false
+		}
+		name := strings.ToLower(source[i:nameEnd])
+		i = nameEnd
+
+		for i < len(source) && isSpace(source[i]) {
+			i++
+		}
+		if i >= len(source) || source[i] != '=' {
+			attrs[name] = ""
+
+			continue
+		}
+		i++
+		for i < len(source) && isSpace(source[i]) {
+			i++
+		}
+		if i >= len(source) {
+			return nil, 0, false
+		}
+
+		var raw string
+		if quote := source[i]; quote == '"' || quote == '\'' {
+			valueEnd := strings.IndexByte(source[i+1:], quote)
+			if valueEnd < 0 {
+				return nil, 0, false
+			}
+			raw = source[i+1 : i+1+valueEnd]
+			i += valueEnd + 2
+		} else {
+			valueEnd := i
+			for valueEnd < len(source) && !isSpace(source[valueEnd]) && source[valueEnd] != '>' {
+				valueEnd++
+			}
+			raw = source[i:valueEnd]
+			i = valueEnd
+		}
+		attrs[name] = decodeCharRefs(raw)
+	}
+}
+
+// decodeCharRef decodes the character reference at the start of s, such as
+// &lt; or &#128512;, and returns the character and its length in s. Anything
+// else is a literal "&".
+func decodeCharRef(s string) (rune, int) {
+	end := strings.IndexByte(s, ';')
+	if end < 2 || end > 10 {
+		return '&', 1
+	}
+
+	ref := s[1:end]
+	if r, ok := htmlNamedEntities[ref]; ok {
+		return r, end + 1
+	}
+	if ref[0] != '#' {
+		return '&', 1
+	}
+
+	digits, base := ref[1:], 10
+	if strings.HasPrefix(digits, "x") || strings.HasPrefix(digits, "X") {
+		digits, base = digits[1:], 16
+	}
+	code, err := strconv.ParseUint(digits, base, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) || code == 0 {
+		return '&', 1
+	}
+
+	return rune(code), end + 1
+}
+
+// undecodedCharRef returns the character reference at the start of s when it
+// has the form of one, "&" and letters, digits or "#" up to ";". Telegram
+// shows references it does not decode, such as &nbsp;, as written.
+func undecodedCharRef(s string) (string, bool) {
+	end := strings.IndexByte(s, ';')
+	if end < 2 || end > 10 {
+		return "", false
+	}
+
+	for _, c := range []byte(s[1:end]) {
+		isAlnum := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+		if !isAlnum && c != '#' {
+			return "", false
+		}
+	}
+
+	return s[:end+1], true
+}
+
+func decodeCharRefs(s string) string {
+	if !strings.Contains(s, "&") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '&' {
+			b.WriteByte(s[i])
+			i++
+
+			continue
+		}
+		r, size := decodeCharRef(s[i:])
+		b.WriteRune(r)
+		i += size
+	}
+
+	return b.String()
+}
+
+func isTagNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,310 @@
+package markup
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// markdownV2Reserved must be escaped with "\" outside of code and pre.
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!"
+
+const customEmojiURLPrefix = "tg://emoji?id="
+
+type markdownParser struct {
+	builder
+	// quote is the open blockquote. It ends before the newline of the last
+	// line starting with ">", recorded in quoteEnd.
+	quote    *openEntity
+	quoteEnd int
+	// quoteToken reports whether a token opened the quote on this line. The
+	// tokens close the quote at every line end, so a part split between
+	// quoted lines starts with the ">" of its first line.
+	quoteToken bool
+}
+
+// MarkdownV2 parses Telegram's MarkdownV2.
+func MarkdownV2(source string) (*Result, error) {
+	p := &markdownParser{builder: builder{source: source}}
+
+	for i := 0; i < len(source); {
+		if i == 0 || source[i-1] == '\n' {
+			next, err := p.lineStart(i)
+			if err != nil {
+				return nil, err
+			}
+			if next != i {
+				i = next
+
+				continue
+			}
+		}
+
+		c := source[i]
+		var err error
+		switch {
+		case c == '\\' && i+1 < len(source) && isEscapable(source[i+1]):
+			p.write(source[i:i+2], source[i+1:i+2])
+			i += 2
+		case c == '`':
+			i, err = p.code(i)
+		case strings.IndexByte(markdownV2Reserved, c) >= 0:
+			i, err = p.marker(i)
+		default:
+			if c == '\n' && p.quoteToken {
+				p.token(TokenClose, "", "blockquote")
+				p.quoteToken = false
+			}
+			_, size := utf8.DecodeRuneInString(source[i:])
+			p.write(source[i:i+size], source[i:i+size])
+			i += size
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.quote != nil {
+		if err := p.closeQuote(p.units); err != nil {
+			return nil, err
+		}
+	}
+	if e := p.top(); e != nil {
+		if e.tag == "[" || e.tag == "![" {
+			return nil, p.errorAt(e.pos, `link text is never closed with "]"`)
+		}
+
+		return nil, p.errorAt(e.pos, "%s entity is never closed, end it with %q", e.Type, e.tag)
+	}
+
+	return p.result(), nil
+}
+
+// write shows text, written as raw in the source.
+func (p *markdownParser) write(raw, text string) {
+	if text == "\n" && p.quote != nil {
+		p.quoteEnd = p.units
+	}
+	p.writeChar(raw, text)
+}
+
+// lineStart opens or continues a blockquote for lines starting with ">" or,
+// for an expandable one, "**>". It returns the offset after the marker.
+func (p *markdownParser) lineStart(pos int) (int, error) {
+	line := p.source[pos:]
+	quoted := strings.HasPrefix(line, ">")
+	expandable := strings.HasPrefix(line, "**>")
+
+	if p.quote != nil && !quoted {
+		if err := p.closeQuote(p.quoteEnd); err != nil {
+			return 0, err
+		}
+	}
+
+	switch {
+	case expandable:
+		p.quote = p.push("expandable_blockquote", "**>", pos)
+	case quoted && p.quote == nil:
+		p.quote = p.push("blockquote", ">", pos)
+	case !quoted:
+		return pos, nil
+	}
+
+	marker := ">"
+	if expandable {
+		marker = "**>"
+	}
+	// A continued expandable quote is reopened with its own marker, so the
+	// "||" that ends it still parses.
+	i := p.openToken(marker, "blockquote", "")
+	p.tokens[i].Reopen = p.quote.tag
+	p.quoteToken = true
+
+	return pos + len(marker), nil
+}
+
+func (p *markdownParser) closeQuote(end int) error {
+	if e := p.top(); e != p.quote {
+		return p.errorAt(e.pos, "%s entity must be closed before the blockquote opened at %s ends",
+			e.Type, p.where(p.quote.pos))
+	}
+
+	p.open = p.open[:len(p.open)-1]
+	p.closeAt(p.quote, end)
+	p.quote = nil
+
+	return nil
+}
+
+// marker handles a reserved character outside of code and returns the offset
+// after it.
+func (p *markdownParser) marker(pos int) (int, error) {
+	source := p.source
+	next := byte(0)
+	if pos+1 < len(source) {
+		next = source[pos+1]
+	}
+
+	switch c := source[pos]; {
+	case c == '*':
+		return pos + 1, p.toggle("bold", "*", pos)
+	case c == '_' && next == '_':
+		return pos + 2, p.toggle("underline", "__", pos)
+	case c == '_':
+		return pos + 1, p.toggle("italic", "_", pos)
+	case c == '~':
+		return pos + 1, p.toggle("strikethrough", "~", pos)
+	case c == '|' && next == '|':
+		// "||" at the end of a line closes an expandable blockquote.
+		atLineEnd := pos+2 == len(source) || source[pos+2] == '\n'
+		if p.quote != nil && p.quote.tag == "**>" && atLineEnd {
+			if err := p.closeQuote(p.units); err != nil {
+				return 0, err
+			}
+			p.token(TokenClose, "||", "blockquote")
+			p.quoteToken = false
+
+			return pos + 2, nil
+		}
+
+		return pos + 2, p.toggle("spoiler", "||", pos)
+	case c == '[':
+		// The link is closed by "](url)", known once the URL is read.
+		p.push("text_link", "[", pos).token = p.openToken("[", "[", "")
+
+		return pos + 1, nil
+	case c == '!' && next == '[':
+		p.push("custom_emoji", "![", pos).token = p.openToken("![", "![", "")
+
+		return pos + 2, nil
+	case c == ']':
+		if e := p.top(); e != nil && (e.tag == "[" || e.tag == "![") {
+			return p.closeLink(pos)
+		}
+	}
+
+	return 0, p.errorAt(pos, `character "%c" is reserved and must be escaped with a preceding "\"`, source[pos])
+}
+
+// toggle closes the innermost entity if it was opened by tag and opens a new
+// one otherwise. Entities cannot cross, so the same tag further out is an
+// error.
+func (p *markdownParser) toggle(entityType, tag string, pos int) error {
+	if e := p.top(); e != nil && e.tag == tag {
+		p.pop()
+		p.token(TokenClose, tag, tag)
+
+		return nil
+	}
+
+	for _, e := range p.open {
+		if e.tag == tag {
+			inner := p.top()
+
+			return p.errorAt(pos, "%q closes the %s entity opened at %s, but the %s entity opened at %s is still open",
+				tag, e.Type, p.where(e.pos), inner.Type, p.where(inner.pos))
+		}
+	}
+
+	p.push(entityType, tag, pos)
+	p.openToken(tag, tag, tag)
+
+	return nil
+}
+
+// closeLink ends the link text at pos and reads the URL in "(...)" after it.
+func (p *markdownParser) closeLink(pos int) (int, error) {
+	e := p.top()
+	next := pos + 1
+	url := ""
+	if next < len(p.source) && p.source[next] == '(' {
+		var err error
+		if url, next, err = p.linkURL(next); err != nil {
+			return 0, err
+		}
+	}
+
+	if e.tag == "![" {
+		id, ok := strings.CutPrefix(url, customEmojiURLPrefix)
+		if !ok || id == "" {
+			return 0, p.errorAt(e.pos, "custom emoji must link to %s<custom emoji ID>", customEmojiURLPrefix)
+		}
+		e.CustomEmojiID = id
+	} else {
+		e.URL = url
+		// Telegram shows link text without a URL as plain text.
+		if url == "" {
+			e.Type = ""
+		}
+	}
+	p.pop()
+	p.token(TokenClose, p.source[pos:next], e.tag)
+	p.tokens[e.token].Close = p.source[pos:next]
+
+	return next, nil
+}
+
+// linkURL reads the "(...)" at pos, where only ")" and "\" are escaped.
+func (p *markdownParser) linkURL(pos int) (string, int, error) {
+	var url strings.Builder
+	for i := pos + 1; i < len(p.source); {
+		c := p.source[i]
+		switch {
+		case c == '\\' && i+1 < len(p.source) && isEscapable(p.source[i+1]):
+			url.WriteByte(p.source[i+1])
+			i += 2
+		case c == ')':
+			return url.String(), i + 1, nil
+		default:
+			url.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, p.errorAt(pos, `link URL is never closed with ")"`)
+}
+
+// code reads inline code or a pre block starting at pos. Inside them, only "`"
+// and "\" are escaped. A pre block may name its language on the first line.
+func (p *markdownParser) code(pos int) (int, error) {
+	tag := "`"
+	entityType := "code"
+	start := pos + 1
+	language := ""
+	if strings.HasPrefix(p.source[pos:], "```") {
+		tag, entityType, start = "```", "pre", pos+3
+		if firstLine, _, ok := strings.Cut(p.source[start:], "\n"); ok && !strings.Contains(firstLine, "`") {
+			language = firstLine
+			start += len(firstLine) + 1
+		}
+	}
+
+	e := p.push(entityType, tag, pos)
+	e.Language = language
+	p.openToken(p.source[pos:start], tag, tag)
+
+	for i := start; i < len(p.source); {
+		c := p.source[i]
+		switch {
+		case c == '\\' && i+1 < len(p.source) && isEscapable(p.source[i+1]):
+			p.write(p.source[i:i+2], p.source[i+1:i+2])
+			i += 2
+		case strings.HasPrefix(p.source[i:], tag):
+			p.pop()
+			p.token(TokenClose, tag, tag)
+
+			return i + len(tag), nil
+		default:
+			_, size := utf8.DecodeRuneInString(p.source[i:])
+			p.write(p.source[i:i+size], p.source[i:i+size])
+			i += size
+		}
+	}
+
+	return 0, p.errorAt(pos, "%s entity is never closed, end it with %q", entityType, tag)
+}
+
+// isEscapable reports whether "\" before c escapes it. Any ASCII character
+// can be escaped.
+func isEscapable(c byte) bool {
+	return c > 0 && c <= 126
+}
@@ -0,0 +1,148 @@
+// Package markup parses Telegram's HTML and MarkdownV2 the way Telegram does,
+// so formatted text can be measured, checked and split before it is sent.
+// See https://core.telegram.org/bots/api#formatting-options.
+package markup
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+)
+
+// Result is formatted text as Telegram shows it: the text without markup and
+// the entities that format it.
+type Result struct {
+	Text     string
+	Entities []entity.MessageEntity
+	// Tokens is the source split into shown characters and markup.
+	Tokens []Token
+}
+
+// Length returns the length of the shown text in UTF-16 code units, the unit
+// Telegram's message and caption limits apply to.
+func (r *Result) Length() int {
+	return entity.UTF16Len(r.Text)
+}
+
+// SyntaxError points at the character Telegram would reject. Line and Column
+// count from 1, and Column counts characters rather than bytes.
+type SyntaxError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Parse parses text in the given parse mode: "markdown" or "MarkdownV2", or
+// "html". Any other mode, including none, means plain text.
+func Parse(text, parseMode string) (*Result, error) {
+	switch strings.ToLower(parseMode) {
+	case "markdown", "markdownv2":
+		return MarkdownV2(text)
+	case "html":
+		return HTML(text)
+	default:
+		b := &builder{source: text}
+		for _, r := range text {
+			b.writeChar(string(r), string(r))
+		}
+
+		return b.result(), nil
+	}
+}
+
+// openEntity is an entity whose end has not been found yet.
+type openEntity struct {
+	entity.MessageEntity
+	// tag is the HTML tag or MarkdownV2 marker that opened the entity.
+	tag string
+	// pos is the byte offset of tag in the source, for error positions.
+	pos int
+	// token is the index of the TokenOpen that opened the entity.
+	token int
+}
+
+// builder collects the shown text and measures it in UTF-16 code units, the
+// unit of entity offsets.
+type builder struct {
+	source   string
+	text     strings.Builder
+	units    int
+	open     []*openEntity
+	entities []entity.MessageEntity
+	tokens   []Token
+}
+
+// writeChar shows text, written as raw in the source.
+func (b *builder) writeChar(raw, text string) {
+	width := entity.UTF16Len(text)
+	b.text.WriteString(text)
+	b.units += width
+	b.tokens = append(b.tokens, Token{Kind: TokenChar, Raw: raw, Text: text, Width: width})
+}
+
+func (b *builder) push(entityType, tag string, pos int) *openEntity {
+	e := &openEntity{MessageEntity: entity.MessageEntity{Type: entityType, Offset: b.units}, tag: tag, pos: pos}
+	b.open = append(b.open, e)
+
+	return e
+}
+
+func (b *builder) top() *openEntity {
+	if len(b.open) == 0 {
+		return nil
+	}
+
+	return b.open[len(b.open)-1]
+}
+
+// pop closes the innermost entity at the current position. Telegram drops
+// entities that cover no text.
+func (b *builder) pop() *openEntity {
+	e := b.top()
+	b.open = b.open[:len(b.open)-1]
+	b.closeAt(e, b.units)
+
+	return e
+}
+
+func (b *builder) closeAt(e *openEntity, end int) {
+	e.Length = end - e.Offset
+	if e.Length > 0 && e.Type != "" {
+		b.entities = append(b.entities, e.MessageEntity)
+	}
+}
+
+func (b *builder) result() *Result {
+	slices.SortStableFunc(b.entities, func(x, y entity.MessageEntity) int {
+		return cmp.Or(cmp.Compare(x.Offset, y.Offset), cmp.Compare(y.Length, x.Length))
+	})
+
+	return &Result{Text: b.text.String(), Entities: b.entities, Tokens: b.tokens}
+}
+
+// errorAt returns a SyntaxError for the byte offset pos of the source.
+func (b *builder) errorAt(pos int, format string, args ...any) *SyntaxError {
+	before := b.source[:pos]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+
+	return &SyntaxError{
+		Line:    strings.Count(before, "\n") + 1,
+		Column:  utf8.RuneCountInString(before[lineStart:]) + 1,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// where describes the position of pos for messages about another position.
+func (b *builder) where(pos int) string {
+	err := b.errorAt(pos, "")
+
+	return fmt.Sprintf("line %d, column %d", err.Line, err.Column)
+}
@@ -0,0 +1,342 @@
+package markup_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+)
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		source       string
+		wantText     string
+		wantEntities []entity.MessageEntity
+	}{
+		{
+			name:     "nested styles",
+			source:   "<b>Deploy <i>done</i></b>",
+			wantText: "Deploy done",
+			wantEntities: []entity.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 11},
+				{Type: "italic", Offset: 7, Length: 4},
+			},
+		},
+		{
+			name:         "offsets in UTF-16 code units",
+			source:       "🚀 <u>ok</u>",
+			wantText:     "🚀 ok",
+			wantEntities: []entity.MessageEntity{{Type: "underline", Offset: 3, Length: 2}},
+		},
+		{
+			name:     "character references",
+			source:   "a &lt; b &amp;&amp; c &#62; d &#x1F680; &nbsp;",
+			wantText: "a < b && c > d 🚀 &nbsp;",
+		},
+		{
+			name: "link, spoiler and custom emoji",
+			source: `<a href="https://example.com/?a=1&amp;b=2">site</a> <span class="tg-spoiler">x</span> ` +
+				`<tg-emoji emoji-id="5">👍</tg-emoji>`,
+			wantText: "site x 👍",
+			wantEntities: []entity.MessageEntity{
+				{Type: "text_link", Offset: 0, Length: 4, URL: "https://example.com/?a=1&b=2"},
+				{Type: "spoiler", Offset: 5, Length: 1},
+				{Type: "custom_emoji", Offset: 7, Length: 2, CustomEmojiID: "5"},
+			},
+		},
+		{
+			name:         "pre with a language",
+			source:       "<pre><code class=\"language-go\">fmt.Println()</code></pre>",
+			wantText:     "fmt.Println()",
+			wantEntities: []entity.MessageEntity{{Type: "pre", Offset: 0, Length: 13, Language: "go"}},
+		},
+		{
+			name:         "expandable blockquote",
+			source:       "<blockquote expandable>log</blockquote>",
+			wantText:     "log",
+			wantEntities: []entity.MessageEntity{{Type: "expandable_blockquote", Offset: 0, Length: 3}},
+		},
+		{
+			name:     "empty entities and links without href are dropped",
+			source:   "<b></b><a>text</a>",
+			wantText: "text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := markup.Parse(tt.source, "HTML")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, result.Text)
+			assert.Equal(t, tt.wantEntities, result.Entities)
+		})
+	}
+}
+
+func TestHTML_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name:    "unescaped less-than",
+			source:  "ok\nif a < b",
+			wantErr: `line 2, column 6: unescaped "<", write &lt; to show it`,
+		},
+		{
+			name:    "unsupported tag",
+			source:  "<div>x</div>",
+			wantErr: "line 1, column 1: unsupported tag <div>",
+		},
+		{
+			name:    "mismatched end tag",
+			source:  "<b>x\n<i>y</b></i>",
+			wantErr: "line 2, column 5: end tag </b> does not match <i> opened at line 2, column 1",
+		},
+		{
+			name:    "unmatched end tag",
+			source:  "x</b>",
+			wantErr: "line 1, column 2: end tag </b> has no matching start tag",
+		},
+		{
+			name:    "unclosed tag",
+			source:  "é <b>x",
+			wantErr: "line 1, column 3: tag <b> is never closed",
+		},
+		{
+			name:    "span without spoiler class",
+			source:  "<span>x</span>",
+			wantErr: `line 1, column 1: tag <span> must have class="tg-spoiler"`,
+		},
+		{
+			name:    "tag without closing bracket",
+			source:  `<a href="x"`,
+			wantErr: `line 1, column 1: tag <a> is not closed with ">"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := markup.Parse(tt.source, "html")
+			require.EqualError(t, err, tt.wantErr)
+
+			var syntaxErr *markup.SyntaxError
+			assert.True(t, errors.As(err, &syntaxErr))
+		})
+	}
+}
+
+func TestMarkdownV2(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		source       string
+		wantText     string
+		wantEntities []entity.MessageEntity
+	}{
+		{
+			name:     "styles",
+			source:   "*bold _italic_* __under__ ~strike~ ||spoiler||",
+			wantText: "bold italic under strike spoiler",
+			wantEntities: []entity.MessageEntity{
+				{Type: "bold", Offset: 0, Length: 11},
+				{Type: "italic", Offset: 5, Length: 6},
+				{Type: "underline", Offset: 12, Length: 5},
+				{Type: "strikethrough", Offset: 18, Length: 6},
+				{Type: "spoiler", Offset: 25, Length: 7},
+			},
+		},
+		{
+			name:     "escapes",
+			source:   `Done\. 1\+1\=2 \\`,
+			wantText: `Done. 1+1=2 \`,
+		},
+		{
+			name:     "link and custom emoji",
+			source:   "[docs](https://example.com/a_\\(b\\)) ![👍](tg://emoji?id=5)",
+			wantText: "docs 👍",
+			wantEntities: []entity.MessageEntity{
+				{Type: "text_link", Offset: 0, Length: 4, URL: "https://example.com/a_(b)"},
+				{Type: "custom_emoji", Offset: 5, Length: 2, CustomEmojiID: "5"},
+			},
+		},
+		{
+			name:     "code keeps reserved characters",
+			source:   "`a.b()` ```go\nx := 1\n```",
+			wantText: "a.b() x := 1\n",
+			wantEntities: []entity.MessageEntity{
+				{Type: "code", Offset: 0, Length: 5},
+				{Type: "pre", Offset: 6, Length: 7, Language: "go"},
+			},
+		},
+		{
+			name:     "blockquotes",
+			source:   ">one\n>two\nafter\n**>hidden\n>more||",
+			wantText: "one\ntwo\nafter\nhidden\nmore",
+			wantEntities: []entity.MessageEntity{
+				{Type: "blockquote", Offset: 0, Length: 7},
+				{Type: "expandable_blockquote", Offset: 14, Length: 11},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := markup.Parse(tt.source, "MarkdownV2")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantText, result.Text)
+			assert.Equal(t, tt.wantEntities, result.Entities)
+		})
+	}
+}
+
+func TestMarkdownV2_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{
+			name:    "reserved character",
+			source:  "Build ok\nVersion 1.2",
+			wantErr: `line 2, column 10: character "." is reserved and must be escaped with a preceding "\"`,
+		},
+		{
+			name:   "crossing entities",
+			source: "*a _b* c_",
+			wantErr: `line 1, column 6: "*" closes the bold entity opened at line 1, column 1, ` +
+				`but the italic entity opened at line 1, column 4 is still open`,
+		},
+		{
+			name:    "unclosed entity",
+			source:  "ok ~gone",
+			wantErr: `line 1, column 4: strikethrough entity is never closed, end it with "~"`,
+		},
+		{
+			name:    "unclosed code",
+			source:  "```\ncode",
+			wantErr: "line 1, column 1: pre entity is never closed, end it with \"```\"",
+		},
+		{
+			name:    "unclosed link text",
+			source:  "see [docs",
+			wantErr: `line 1, column 5: link text is never closed with "]"`,
+		},
+		{
+			name:    "unclosed link URL",
+			source:  "[docs](https://example.com",
+			wantErr: `line 1, column 7: link URL is never closed with ")"`,
+		},
+		{
+			name:    "custom emoji without an ID",
+			source:  "![👍](https://example.com)",
+			wantErr: "line 1, column 1: custom emoji must link to tg://emoji?id=<custom emoji ID>",
+		},
+		{
+			name:   "entity open when the blockquote ends",
+			source: ">*quoted\nplain*",
+			wantErr: "line 1, column 2: bold entity must be closed before the blockquote " +
+				"opened at line 1, column 1 ends",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := markup.Parse(tt.source, "MarkdownV2")
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestParse_PlainText(t *testing.T) {
+	t.Parallel()
+
+	result, err := markup.Parse("<b>1.5 🚀</b>", "")
+	require.NoError(t, err)
+	assert.Equal(t, "<b>1.5 🚀</b>", result.Text)
+	assert.Empty(t, result.Entities)
+	assert.Equal(t, 13, result.Length(), "the emoji takes two UTF-16 code units")
+}
+
+func TestResult_Length(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source    string
+		parseMode string
+		want      int
+	}{
+		{source: "😀😀", parseMode: "", want: 4},
+		{source: "![👍](tg://emoji?id=5)", parseMode: "MarkdownV2", want: 2},
+		{source: `<tg-emoji emoji-id="5">👍</tg-emoji>`, parseMode: "html", want: 2},
+		{source: "&#x1F989;&lt;", parseMode: "html", want: 3},
+		// Telegram shows named entities other than &lt;, &gt;, &amp; and
+		// &quot; as written.
+		{source: "&nbsp;", parseMode: "html", want: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := markup.Parse(tt.source, tt.parseMode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result.Length())
+		})
+	}
+}
+
+func TestResult_Tokens(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source    string
+		parseMode string
+	}{
+		{source: `<b>a &amp; <a href="https://example.com">b</a></b>&nbsp;`, parseMode: "html"},
+		{source: "*a\\.* [b](https://example.com) ```go\nx\n```", parseMode: "MarkdownV2"},
+		{source: ">one\n>two\n**>three\n>four||", parseMode: "MarkdownV2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := markup.Parse(tt.source, tt.parseMode)
+			require.NoError(t, err)
+
+			var raw, text strings.Builder
+			width := 0
+			for _, token := range result.Tokens {
+				raw.WriteString(token.Raw)
+				text.WriteString(token.Text)
+				width += token.Width
+			}
+			assert.Equal(t, tt.source, raw.String(), "the tokens cover the source")
+			assert.Equal(t, result.Text, text.String())
+			assert.Equal(t, result.Length(), width)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package markup
+
+// TokenKind tells what a Token is.
+type TokenKind int
+
+const (
+	// TokenChar is one shown character, with the escape or character
+	// reference that wrote it. An HTML entity Telegram does not decode, such
+	// as &nbsp;, is shown as written and stays one token.
+	TokenChar TokenKind = iota
+	// TokenOpen starts an entity. Reopen is written at the start of the next
+	// part when text is split inside the entity, Close at the end of the
+	// current part.
+	TokenOpen
+	// TokenClose ends the innermost open entity with the same Name.
+	TokenClose
+)
+
+// Token is a piece of the source: a shown character or the markup around
+// characters. Joining the Raw text of all tokens gives the source back, which
+// lets text be split or shortened without breaking its entities.
+type Token struct {
+	Kind TokenKind
+	Raw  string
+	// Text is the shown text of a TokenChar.
+	Text string
+	// Width is the length of Text in UTF-16 code units.
+	Width int
+	// Name is the tag or marker that opened the entity.
+	Name   string
+	Reopen string
+	Close  string
+}
+
+// token appends a TokenOpen or TokenClose for the entity opened by name and
+// returns its index.
+func (b *builder) token(kind TokenKind, raw, name string) int {
+	b.tokens = append(b.tokens, Token{Kind: kind, Raw: raw, Name: name})
+
+	return len(b.tokens) - 1
+}
+
+// openToken appends a TokenOpen that is reopened with raw and closed with
+// closeRaw.
+func (b *builder) openToken(raw, name, closeRaw string) int {
+	i := b.token(TokenOpen, raw, name)
+	b.tokens[i].Reopen = raw
+	b.tokens[i].Close = closeRaw
+
+	return i
+}
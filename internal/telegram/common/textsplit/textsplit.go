@@ -0,0 +1,257 @@
+// Package textsplit breaks long message text into parts that Telegram accepts
+// one by one, or shortens it to fit a single message. Lengths are measured like
+// Telegram measures them: in UTF-16 code units of the text left after parsing
+// the markup with package markup.
+package textsplit
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/parsemode"
+)
+
+// counterReserve is kept free in every part for a "\n\n(999/999)" counter.
+const counterReserve = 11
+
+// Options controls how text is split.
+type Options struct {
+	// ParseMode is the CLI format: "", "html" or "markdown".
+	ParseMode string
+	// Limit is the longest part, in parsed UTF-16 code units.
+	Limit int
+	// Counters appends "(n/total)" to every part when there is more than one.
+	Counters bool
+}
+
+// Split breaks text at paragraph, line or word boundaries, in that order of
+// preference, and cuts words only when nothing else fits. Entities open at a
+// split point are closed at the end of the part and reopened at the start of
+// the next one, so every part parses on its own. Text that fits is returned
+// unchanged as the only part.
+func Split(text string, opts Options) []string {
+	tokens := tokenize(text, opts.ParseMode)
+
+	if width(tokens) <= opts.Limit {
+		return []string{text}
+	}
+
+	limit := opts.Limit
+	if opts.Counters {
+		limit -= counterReserve
+	}
+	parts := splitTokens(tokens, max(limit, 1))
+
+	if opts.Counters && len(parts) > 1 {
+		for i := range parts {
+			parts[i] += counter(i+1, len(parts), opts.ParseMode)
+		}
+	}
+
+	return parts
+}
+
+// Length returns the length of text after Telegram parses its markup.
+func Length(text, parseMode string) int {
+	return width(tokenize(text, parseMode))
+}
+
+// tokenize parses text like Telegram does. Text Telegram would reject is
+// handled as plain text, and Telegram reports the error when it is sent.
+func tokenize(text, parseMode string) []markup.Token {
+	parsed, err := markup.Parse(text, parseMode)
+	if err != nil {
+		parsed, _ = markup.Parse(text, "")
+	}
+
+	return parsed.Tokens
+}
+
+func width(tokens []markup.Token) int {
+	total := 0
+	for _, t := range tokens {
+		total += t.Width
+	}
+
+	return total
+}
+
+func counter(n, total int, parseMode string) string {
+	if parsemode.Normalize(parseMode) == "MarkdownV2" {
+		return fmt.Sprintf("\n\n\\(%d/%d\\)", n, total)
+	}
+
+	return fmt.Sprintf("\n\n(%d/%d)", n, total)
+}
+
+type breakKind int
+
+const (
+	breakWord breakKind = iota
+	breakLine
+	breakParagraph
+)
+
+// breakPoint is a whitespace character where the text may be split. The
+// character itself is dropped.
+type breakPoint struct {
+	kind  breakKind
+	at    int
+	next  int
+	width int
+	open  []markup.Token
+}
+
+func splitTokens(tokens []markup.Token, limit int) []string {
+	var parts []string
+
+	var open []markup.Token
+	for start := 0; start < len(tokens); {
+		stack := slices.Clone(open)
+		used := 0
+		end := start
+		var breaks []breakPoint
+
+		for ; end < len(tokens); end++ {
+			t := tokens[end]
+			if t.Kind == markup.TokenChar {
+				// Whitespace is dropped at a break, so it need not fit.
+				if kind, next, ok := breakAt(tokens, end); ok && used > 0 {
+					breaks = append(breaks, breakPoint{
+						kind:  kind,
+						at:    end,
+						next:  next,
+						width: used,
+						open:  slices.Clone(stack),
+					})
+				}
+				if used+t.Width > limit {
+					break
+				}
+				used += t.Width
+			}
+			stack = apply(stack, t)
+		}
+
+		if end == len(tokens) {
+			parts = appendPart(parts, open, tokens[start:end], stack)
+
+			break
+		}
+
+		if bp, ok := chooseBreak(breaks, limit); ok {
+			parts = appendPart(parts, open, tokens[start:bp.at], bp.open)
+			start = bp.next
+			open = bp.open
+
+			continue
+		}
+
+		// A single word longer than the limit is cut where the limit is
+		// reached. end is past start because every character fits in a
+		// part on its own.
+		end = max(end, start+1)
+		stack = slices.Clone(open)
+		for _, t := range tokens[start:end] {
+			stack = apply(stack, t)
+		}
+		parts = appendPart(parts, open, tokens[start:end], stack)
+		start = end
+		open = stack
+	}
+
+	return parts
+}
+
+func breakAt(tokens []markup.Token, i int) (breakKind, int, bool) {
+	switch tokens[i].Text {
+	case "\n":
+		if i+1 < len(tokens) && tokens[i+1].Kind == markup.TokenChar && tokens[i+1].Text == "\n" {
+			return breakParagraph, i + 2, true
+		}
+
+		return breakLine, i + 1, true
+	case " ", "\t":
+		return breakWord, i + 1, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// chooseBreak prefers the last paragraph or line break in the second half of
+// the part, then the last word break anywhere. Breaking earlier would leave
+// very short parts.
+func chooseBreak(breaks []breakPoint, limit int) (breakPoint, bool) {
+	for _, kind := range []breakKind{breakParagraph, breakLine} {
+		for i := len(breaks) - 1; i >= 0; i-- {
+			if breaks[i].kind == kind && breaks[i].width >= limit/2 {
+				return breaks[i], true
+			}
+		}
+	}
+
+	if len(breaks) > 0 {
+		return breaks[len(breaks)-1], true
+	}
+
+	return breakPoint{}, false
+}
+
+// apply returns the entities open after t.
+func apply(stack []markup.Token, t markup.Token) []markup.Token {
+	switch t.Kind {
+	case markup.TokenOpen:
+		return append(stack, t)
+	case markup.TokenClose:
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].Name == t.Name {
+				return append(stack[:i:i], stack[i+1:]...)
+			}
+		}
+	case markup.TokenChar:
+	}
+
+	return stack
+}
+
+func render(open, tokens, stillOpen []markup.Token) string {
+	var b strings.Builder
+	for _, t := range open {
+		b.WriteString(t.Reopen)
+	}
+	for _, t := range tokens {
+		b.WriteString(t.Raw)
+	}
+	for i := len(stillOpen) - 1; i >= 0; i-- {
+		b.WriteString(stillOpen[i].Close)
+	}
+
+	return b.String()
+}
+
+// appendPart renders a part unless it has no visible text, which Telegram
+// rejects. Entities that would be empty at either edge of the part, because
+// they open right before the split or close right after it, are left to the
+// neighbouring part.
+func appendPart(parts []string, open, tokens, stillOpen []markup.Token) []string {
+	for len(tokens) > 0 && len(open) > 0 &&
+		tokens[0].Kind == markup.TokenClose && tokens[0].Name == open[len(open)-1].Name {
+		tokens = tokens[1:]
+		open = open[:len(open)-1]
+	}
+	for len(tokens) > 0 && len(stillOpen) > 0 &&
+		tokens[len(tokens)-1].Kind == markup.TokenOpen && tokens[len(tokens)-1].Raw == stillOpen[len(stillOpen)-1].Raw {
+		tokens = tokens[:len(tokens)-1]
+		stillOpen = stillOpen[:len(stillOpen)-1]
+	}
+
+	for _, t := range tokens {
+		if t.Kind == markup.TokenChar && strings.TrimSpace(t.Text) != "" {
+			return append(parts, render(open, tokens, stillOpen))
+		}
+	}
+
+	return parts
+}
@@ -0,0 +1,262 @@
+package textsplit_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/textsplit"
+)
+
+func TestSplit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		opts textsplit.Options
+		want []string
+	}{
+		{
+			name: "fits",
+			text: "short *text*",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 10},
+			want: []string{"short *text*"},
+		},
+		{
+			name: "paragraph preferred over line and word",
+			text: "one two\n\nthree\nfour five",
+			opts: textsplit.Options{Limit: 18},
+			want: []string{"one two\n\nthree", "four five"},
+		},
+		{
+			name: "line preferred over word",
+			text: "one two\nthree four",
+			opts: textsplit.Options{Limit: 12},
+			want: []string{"one two", "three four"},
+		},
+		{
+			name: "early line break ignored for a later word break",
+			text: "a\nbbbb cccc dddd",
+			opts: textsplit.Options{Limit: 12},
+			want: []string{"a\nbbbb cccc", "dddd"},
+		},
+		{
+			name: "word break",
+			text: "quoted line here",
+			opts: textsplit.Options{Limit: 6},
+			want: []string{"quoted", "line", "here"},
+		},
+		{
+			name: "long word is cut",
+			text: "abcdefghij",
+			opts: textsplit.Options{Limit: 4},
+			want: []string{"abcd", "efgh", "ij"},
+		},
+		{
+			name: "surrogate pairs count twice",
+			text: "🦉🦉🦉",
+			opts: textsplit.Options{Limit: 4},
+			want: []string{"🦉🦉", "🦉"},
+		},
+		{
+			name: "html tags reopened",
+			text: "<b>one <i>two three</i></b>",
+			opts: textsplit.Options{ParseMode: "html", Limit: 8},
+			want: []string{"<b>one <i>two</i></b>", "<b><i>three</i></b>"},
+		},
+		{
+			name: "html link keeps its attributes",
+			text: `<a href="https://example.com">click here now</a>`,
+			opts: textsplit.Options{ParseMode: "html", Limit: 10},
+			want: []string{`<a href="https://example.com">click here</a>`, `<a href="https://example.com">now</a>`},
+		},
+		{
+			name: "html entities are one character",
+			text: "&lt;&lt;&lt;&lt;",
+			opts: textsplit.Options{ParseMode: "html", Limit: 2},
+			want: []string{"&lt;&lt;", "&lt;&lt;"},
+		},
+		{
+			name: "html entities telegram keeps as text are not cut",
+			text: "&nbsp;&nbsp;",
+			opts: textsplit.Options{ParseMode: "html", Limit: 8},
+			want: []string{"&nbsp;", "&nbsp;"},
+		},
+		{
+			name: "html tag at the split moves to the next part",
+			text: "one <b>two</b>",
+			opts: textsplit.Options{ParseMode: "html", Limit: 4},
+			want: []string{"one", "<b>two</b>"},
+		},
+		{
+			name: "markdown entities reopened",
+			text: "*bold text here*",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 9},
+			want: []string{"*bold text*", "*here*"},
+		},
+		{
+			name: "markdown escapes are one character",
+			text: `a\.b\.c d\.e`,
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 5},
+			want: []string{`a\.b\.c`, `d\.e`},
+		},
+		{
+			name: "markdown link",
+			text: "[one two](https://example.com/a_b)",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 4},
+			want: []string{"[one](https://example.com/a_b)", "[two](https://example.com/a_b)"},
+		},
+		{
+			name: "markdown pre block keeps its language",
+			text: "```go\nline1\nline2\n```",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 6},
+			want: []string{"```go\nline1```", "```go\nline2\n```"},
+		},
+		{
+			name: "markdown markers inside code are text",
+			text: "`a*b c*d`",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 4},
+			want: []string{"`a*b`", "`c*d`"},
+		},
+		{
+			name: "markdown quote continues",
+			text: ">quoted line\nplain",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 10},
+			want: []string{">quoted", ">line\nplain"},
+		},
+		{
+			name: "counters",
+			text: "aaaa bbbb cccc dddd",
+			opts: textsplit.Options{Limit: 15, Counters: true},
+			want: []string{"aaaa\n\n(1/4)", "bbbb\n\n(2/4)", "cccc\n\n(3/4)", "dddd\n\n(4/4)"},
+		},
+		{
+			name: "markdown counters are escaped",
+			text: "aaaa bbbb cccc dddd",
+			opts: textsplit.Options{ParseMode: "markdown", Limit: 15, Counters: true},
+			want: []string{"aaaa\n\n\\(1/4\\)", "bbbb\n\n\\(2/4\\)", "cccc\n\n\\(3/4\\)", "dddd\n\n\\(4/4\\)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := textsplit.Split(tt.text, tt.opts)
+			assert.Equal(t, tt.want, got)
+			for _, part := range got {
+				assert.LessOrEqual(t, textsplit.Length(part, tt.opts.ParseMode), tt.opts.Limit, part)
+			}
+		})
+	}
+}
+
+func TestSplit_LongText(t *testing.T) {
+	t.Parallel()
+
+	paragraph := "<b>Build</b> " + strings.Repeat("step &amp; result ", 50)
+	text := strings.Repeat(paragraph+"\n\n", 20)
+
+	parts := textsplit.Split(text, textsplit.Options{ParseMode: "html", Limit: 4096, Counters: true})
+	assert.Greater(t, len(parts), 1)
+	for _, part := range parts {
+		assert.LessOrEqual(t, textsplit.Length(part, "html"), 4096)
+		assert.Equal(t, strings.Count(part, "<b>"), strings.Count(part, "</b>"))
+	}
+}
+
+func TestLength(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 2, textsplit.Length("<b>a</b>&amp;", "html"))
+	assert.Equal(t, 6, textsplit.Length("&nbsp;", "html"), "Telegram keeps &nbsp; as text")
+	assert.Equal(t, 4, textsplit.Length("&#x1F989;&#65;&quot;", "html"))
+	assert.Equal(t, 3, textsplit.Length(`*a\*b*`, "markdown"))
+	assert.Equal(t, 2, textsplit.Length("🦉", ""))
+	assert.Equal(t, 2, textsplit.Length("![👍](tg://emoji?id=5)", "markdown"))
+	assert.Equal(t, 4, textsplit.Length("<b>a", "html"), "text Telegram rejects is measured as written")
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 0, 10)
+	for i := 1; i <= 10; i++ {
+		lines = append(lines, fmt.Sprintf("line%02d", i))
+	}
+	text := strings.Join(lines, "\n")
+
+	tests := []struct {
+		name  string
+		text  string
+		mode  string
+		keep  textsplit.Keep
+		limit int
+		want  string
+	}{
+		{
+			name:  "fits",
+			text:  "short",
+			limit: 50,
+			keep:  textsplit.KeepBoth,
+			want:  "short",
+		},
+		{
+			name:  "both ends",
+			text:  text,
+			limit: 50,
+			keep:  textsplit.KeepBoth,
+			want:  "line01\nline02\n… 6 lines omitted …\nline09\nline10",
+		},
+		{
+			name:  "head",
+			text:  text,
+			limit: 50,
+			keep:  textsplit.KeepHead,
+			want:  "line01\nline02\nline03\nline04\n… 6 lines omitted …",
+		},
+		{
+			name:  "tail",
+			text:  text,
+			limit: 50,
+			keep:  textsplit.KeepTail,
+			want:  "… 6 lines omitted …\nline07\nline08\nline09\nline10",
+		},
+		{
+			name:  "html tags reopened",
+			text:  "<b>" + text + "</b>",
+			mode:  "html",
+			limit: 50,
+			keep:  textsplit.KeepBoth,
+			want:  "<b>line01\nline02</b>\n… 6 lines omitted …\n<b>line09\nline10</b>",
+		},
+		{
+			name:  "markdown entities reopened",
+			text:  "*" + text + "*",
+			mode:  "markdown",
+			limit: 50,
+			keep:  textsplit.KeepBoth,
+			want:  "*line01\nline02*\n… 6 lines omitted …\n*line09\nline10*",
+		},
+		{
+			name:  "characters when no line fits",
+			text:  strings.Repeat("abcdefghij", 10),
+			limit: 40,
+			keep:  textsplit.KeepBoth,
+			want:  "abcdef\n… 88 characters omitted …\nefghij",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := textsplit.Truncate(tt.text, textsplit.Options{ParseMode: tt.mode, Limit: tt.limit}, tt.keep)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, textsplit.Length(got, tt.mode), tt.limit)
+		})
+	}
+}
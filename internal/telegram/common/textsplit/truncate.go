@@ -0,0 +1,194 @@
+package textsplit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+)
+
+// Keep selects the end of the text that Truncate keeps.
+type Keep string
+
+const (
+	KeepHead Keep = "head"
+	KeepTail Keep = "tail"
+	KeepBoth Keep = "both"
+)
+
+// Truncate shortens text to opts.Limit by dropping whole lines, replacing them
+// with a "… N lines omitted …" marker. When not even one line fits, characters
+// are dropped instead. Like Split, it closes entities before the marker and
+// reopens them after it. Text that fits is returned unchanged. Counters are
+// ignored.
+func Truncate(text string, opts Options, keep Keep) string {
+	tokens := tokenize(text, opts.ParseMode)
+	if width(tokens) <= opts.Limit {
+		return text
+	}
+
+	lines := lineSpans(tokens)
+	// Two line endings join the marker to the kept text.
+	budget := opts.Limit - markerWidth(len(lines), "lines") - 2
+	if head, tail := pickLines(lines, budget, keep); head+tail > 0 {
+		headEnd, tailStart := 0, len(tokens)
+		if head > 0 {
+			headEnd = lines[head-1].end
+		}
+		if tail > 0 {
+			tailStart = lines[len(lines)-tail].start
+		}
+
+		return joinAround(tokens, headEnd, tailStart, omitted(len(lines)-head-tail, "lines"))
+	}
+
+	var chars int
+	for _, t := range tokens {
+		if t.Kind == markup.TokenChar {
+			chars++
+		}
+	}
+	budget = opts.Limit - markerWidth(chars, "characters") - 2
+	headEnd, tailStart, dropped := pickChars(tokens, max(budget, 0), keep)
+
+	return joinAround(tokens, headEnd, tailStart, omitted(dropped, "characters"))
+}
+
+// lineSpan is a line of tokens without its line ending.
+type lineSpan struct {
+	start int
+	end   int
+	width int
+}
+
+func lineSpans(tokens []markup.Token) []lineSpan {
+	var lines []lineSpan
+
+	current := lineSpan{}
+	for i, t := range tokens {
+		if t.Kind == markup.TokenChar && t.Text == "\n" {
+			current.end = i
+			lines = append(lines, current)
+			current = lineSpan{start: i + 1}
+
+			continue
+		}
+		current.width += t.Width
+	}
+	current.end = len(tokens)
+
+	return append(lines, current)
+}
+
+// pickLines returns how many lines to keep from each end. Lines are taken
+// alternately from the head and the tail, so both ends get a fair share.
+func pickLines(lines []lineSpan, budget int, keep Keep) (int, int) {
+	head, tail, used := 0, 0, 0
+	for head+tail < len(lines) {
+		taken := false
+		if keep != KeepTail {
+			if cost := lines[head].width + 1; used+cost <= budget {
+				used += cost
+				head++
+				taken = true
+			}
+		}
+		if keep != KeepHead && head+tail < len(lines) {
+			if cost := lines[len(lines)-1-tail].width + 1; used+cost <= budget {
+				used += cost
+				tail++
+				taken = true
+			}
+		}
+		if !taken {
+			break
+		}
+	}
+
+	return head, tail
+}
+
+// pickChars returns where the kept head ends and the kept tail starts, and the
+// number of characters dropped in between.
+func pickChars(tokens []markup.Token, budget int, keep Keep) (int, int, int) {
+	headBudget, tailBudget := budget, 0
+	switch keep {
+	case KeepTail:
+		headBudget, tailBudget = 0, budget
+	case KeepBoth:
+		headBudget, tailBudget = budget-budget/2, budget/2
+	case KeepHead:
+	}
+
+	headEnd, used := 0, 0
+	for i, t := range tokens {
+		if t.Kind != markup.TokenChar {
+			continue
+		}
+		if used+t.Width > headBudget {
+			break
+		}
+		used += t.Width
+		headEnd = i + 1
+	}
+
+	tailStart := len(tokens)
+	used = 0
+	for i := len(tokens) - 1; i >= headEnd; i-- {
+		t := tokens[i]
+		if t.Kind != markup.TokenChar {
+			continue
+		}
+		if used+t.Width > tailBudget {
+			break
+		}
+		used += t.Width
+		tailStart = i
+	}
+
+	dropped := 0
+	for _, t := range tokens[headEnd:tailStart] {
+		if t.Kind == markup.TokenChar {
+			dropped++
+		}
+	}
+
+	return headEnd, tailStart, dropped
+}
+
+// joinAround renders tokens before headEnd and from tailStart on with marker in
+// between. Entities open at headEnd are closed before the marker, and those
+// open at tailStart are reopened after it.
+func joinAround(tokens []markup.Token, headEnd, tailStart int, marker string) string {
+	var stack []markup.Token
+	for _, t := range tokens[:headEnd] {
+		stack = apply(stack, t)
+	}
+
+	var parts []string
+	if headEnd > 0 {
+		parts = append(parts, render(nil, tokens[:headEnd], stack))
+	}
+	parts = append(parts, marker)
+
+	if tailStart < len(tokens) {
+		for _, t := range tokens[headEnd:tailStart] {
+			stack = apply(stack, t)
+		}
+		parts = append(parts, render(stack, tokens[tailStart:], nil))
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// omitted builds the marker. It has no characters that MarkdownV2 or HTML
+// reserve, so it needs no escaping.
+func omitted(n int, unit string) string {
+	return fmt.Sprintf("… %d %s omitted …", n, unit)
+}
+
+// markerWidth is the widest marker for up to n omitted units.
+func markerWidth(n int, unit string) int {
+	return entity.UTF16Len(omitted(n, unit))
+}
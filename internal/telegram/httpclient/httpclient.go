@@ -2,6 +2,7 @@ package httpclient
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -18,7 +19,8 @@ type httpClient struct {
 }
 
 type successResponse struct {
-	OK bool `json:"ok"`
+	OK     bool            `json:"ok"`
+	Result json.RawMessage `json:"result,omitempty"`
 }
 
 type errorResponse struct {
@@ -68,6 +70,7 @@ func (c httpClient) SubmitMultipart(
 	endpoint string,
 	fields map[string]string,
 	files []MultipartFile,
+	result any,
 ) error {
 	request := c.restyClient.R()
 	request.SetMultipartFormData(fields)
@@ -76,7 +79,7 @@ func (c httpClient) SubmitMultipart(
 		request.SetFileReader(mFile.FieldName, mFile.FileName, hideCloser(mFile.FileReader))
 	}
 
-	return c.executeRequest(ctx, method, endpoint, request)
+	return c.executeRequest(ctx, method, endpoint, request, result)
 }
 
 // hideCloser prevents Resty v3 from taking ownership of caller-managed files.
@@ -91,17 +94,22 @@ func hideCloser(reader io.Reader) io.Reader {
 	return struct{ io.Reader }{Reader: reader}
 }
 
-func (c httpClient) SubmitJSON(ctx context.Context, method, endpoint string, body any) error {
+func (c httpClient) SubmitJSON(ctx context.Context, method, endpoint string, body, result any) error {
 	request := c.restyClient.R()
 	request.SetBody(body)
 
-	return c.executeRequest(ctx, method, endpoint, request)
+	return c.executeRequest(ctx, method, endpoint, request, result)
 }
 
 // executeRequest accepts a response only when both the HTTP status and
 // Telegram's JSON "ok" field indicate success. Telegram error payloads take
 // precedence over the raw-body fallback.
-func (c httpClient) executeRequest(ctx context.Context, method, endpoint string, request *resty.Request) error {
+func (c httpClient) executeRequest(
+	ctx context.Context,
+	method, endpoint string,
+	request *resty.Request,
+	result any,
+) error {
 	if ctx == nil {
 		return errors.New("context is nil")
 	}
@@ -125,7 +133,7 @@ func (c httpClient) executeRequest(ctx context.Context, method, endpoint string,
 	}
 
 	if resp.IsStatusSuccess() && successPayload.OK {
-		return nil
+		return decodeResult(endpoint, successPayload.Result, result)
 	}
 
 	if errorPayload.Description != "" {
@@ -140,3 +148,21 @@ func (c httpClient) executeRequest(ctx context.Context, method, endpoint string,
 
 	return fmt.Errorf("unexpected error (status=%d): %s", resp.StatusCode(), body)
 }
+
+// decodeResult fills result from a successful response. A missing "result"
+// field is an error only when the caller asked for one.
+func decodeResult(endpoint string, raw json.RawMessage, result any) error {
+	if result == nil {
+		return nil
+	}
+
+	if len(raw) == 0 {
+		return fmt.Errorf("telegram api response [%s] has no result", endpoint)
+	}
+
+	if err := json.Unmarshal(raw, result); err != nil {
+		return fmt.Errorf("decode telegram api result [%s]: %w", endpoint, err)
+	}
+
+	return nil
+}
@@ -93,7 +93,7 @@ func TestSubmitJSON_Success(t *testing.T) {
 
 	err = client.SubmitJSON(t.Context(), http.MethodPost, "method-a", map[string]string{
 		"foo": "bar",
-	})
+	}, nil)
 	require.NoError(t, err, "SubmitJSON should succeed when the server returns ok=true")
 
 	assert.JSONEq(t, `{"foo":"bar"}`, captured.body)
@@ -150,6 +150,7 @@ func TestSubmitMultipart_Success(t *testing.T) {
 		"method-b",
 		fields,
 		[]httpclient.MultipartFile{multipartFile},
+		nil,
 	)
 	require.NoError(t, err)
 }
@@ -166,7 +167,7 @@ func TestErrorHandling_NetworkTransportError(t *testing.T) {
 	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	err = client.SubmitJSON(t.Context(), http.MethodPost, "method-a", nil)
+	err = client.SubmitJSON(t.Context(), http.MethodPost, "method-a", nil, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "telegram api request failed:")
 	assert.NotContains(t, err.Error(), "to:ken")
@@ -189,7 +190,7 @@ func TestErrorHandling_APIError(t *testing.T) {
 
 	err = client.SubmitJSON(t.Context(), http.MethodPost, "/method-a", map[string]string{
 		"foo": "bar",
-	})
+	}, nil)
 	require.Error(t, err)
 
 	// Verify the error message is what we expect
@@ -213,7 +214,7 @@ func TestErrorHandling_EmptyResponse(t *testing.T) {
 	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", nil)
+	err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", nil, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected error (status=403): <empty response body>")
 }
@@ -234,7 +235,7 @@ func TestExecuteRequest_UnexpectedError(t *testing.T) {
 	require.NotNil(t, client)
 	require.NoError(t, err)
 
-	err = client.SubmitJSON(t.Context(), http.MethodGet, "/test-json", nil)
+	err = client.SubmitJSON(t.Context(), http.MethodGet, "/test-json", nil, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "unexpected error (status=403): some weird error")
 }
@@ -256,7 +257,7 @@ func TestSubmitJSON_ContextCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(t.Context())
 	cancel()
 
-	err = client.SubmitJSON(ctx, http.MethodPost, "/test-json", nil)
+	err = client.SubmitJSON(ctx, http.MethodPost, "/test-json", nil, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "context canceled")
 }
@@ -268,7 +269,7 @@ func TestSubmitJSON_NilContext(t *testing.T) {
 	require.NoError(t, err)
 
 	var ctx context.Context
-	err = client.SubmitJSON(ctx, http.MethodPost, "/test-json", nil)
+	err = client.SubmitJSON(ctx, http.MethodPost, "/test-json", nil, nil)
 	assert.EqualError(t, err, "context is nil")
 }
 
@@ -285,8 +286,68 @@ func TestSubmitJSON_PreservesSerializationError(t *testing.T) {
 	require.NoError(t, err)
 
 	cause := errors.New("serialize request body")
-	err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", failingJSONBody{err: cause})
+	err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", failingJSONBody{err: cause}, nil)
 	require.Error(t, err)
 	require.ErrorIs(t, err, cause)
 	assert.ErrorContains(t, err, "telegram api request failed")
 }
+
+func TestSubmitJSON_DecodesResult(t *testing.T) {
+	t.Parallel()
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true, "result": {"message_id": 42}}`))
+	}))
+	t.Cleanup(mockServer.Close)
+
+	client, err := httpclient.New(mockServer.URL, "token", "")
+	require.NoError(t, err)
+
+	var result struct {
+		MessageID int `json:"message_id"`
+	}
+	err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", nil, &result)
+	require.NoError(t, err)
+	assert.Equal(t, 42, result.MessageID)
+}
+
+func TestSubmitJSON_ResultErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		responseBody string
+		wantErr      string
+	}{
+		{
+			name:         "missing result",
+			responseBody: `{"ok": true}`,
+			wantErr:      "telegram api response [/test-json] has no result",
+		},
+		{
+			name:         "result of an unexpected shape",
+			responseBody: `{"ok": true, "result": true}`,
+			wantErr:      "decode telegram api result [/test-json]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			t.Cleanup(mockServer.Close)
+
+			client, err := httpclient.New(mockServer.URL, "token", "")
+			require.NoError(t, err)
+
+			var result []int
+			err = client.SubmitJSON(t.Context(), http.MethodPost, "/test-json", nil, &result)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
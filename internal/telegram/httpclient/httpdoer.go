@@ -13,10 +13,18 @@ type MultipartFile struct {
 	FileReader io.Reader
 }
 
-// HTTPDoer is the transport boundary used by Telegram method packages.
+// HTTPDoer is the transport boundary used by Telegram method packages. When
+// result is non-nil, the "result" field of a successful response is decoded
+// into it; methods that only need success or failure pass nil.
 type HTTPDoer interface {
 	// SubmitMultipart borrows files until the call returns and never closes them.
-	SubmitMultipart(ctx context.Context, method, endpoint string, fields map[string]string, files []MultipartFile) error
+	SubmitMultipart(
+		ctx context.Context,
+		method, endpoint string,
+		fields map[string]string,
+		files []MultipartFile,
+		result any,
+	) error
 	// SubmitJSON encodes body as JSON and submits it to endpoint.
-	SubmitJSON(ctx context.Context, method, endpoint string, body any) error
+	SubmitJSON(ctx context.Context, method, endpoint string, body, result any) error
 }
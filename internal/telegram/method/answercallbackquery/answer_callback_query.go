@@ -0,0 +1,43 @@
+// Package answercallbackquery validates and sends Telegram answerCallbackQuery
+// requests.
+package answercallbackquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "answerCallbackQuery"
+
+// Answerer acknowledges a button press. Until it is answered, the user's app
+// keeps showing a progress indicator on the button.
+type Answerer interface {
+	Answer(ctx context.Context, opts *Options) error
+}
+
+type callbackAnswerer struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a callback query answerer backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Answerer {
+	return callbackAnswerer{httpClient: httpClient}
+}
+
+// Answer validates opts and submits one answerCallbackQuery request.
+// See https://core.telegram.org/bots/api#answercallbackquery.
+func (a callbackAnswerer) Answer(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("answer callback query: %w", err)
+	}
+
+	if err = a.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("answer callback query: %w", err)
+	}
+
+	return nil
+}
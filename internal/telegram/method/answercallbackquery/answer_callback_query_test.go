@@ -0,0 +1,52 @@
+package answercallbackquery_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/answercallbackquery"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestAnswer_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	err := answercallbackquery.New(mockHTTPClient).Answer(t.Context(), &answercallbackquery.Options{
+		Text: strings.Repeat("a", answercallbackquery.MaxTextLength+1),
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "callback query ID is required")
+	assert.ErrorContains(t, err, "text is too long: must be <= 200 characters, got 201")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestAnswer_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	err := answercallbackquery.New(mockHTTPClient).Answer(t.Context(), &answercallbackquery.Options{
+		CallbackQueryID: "4382bfdwdsb323b2d9",
+		Text:            "You are not allowed to answer",
+		ShowAlert:       true,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "answerCallbackQuery", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"callback_query_id":"4382bfdwdsb323b2d9",
+		"text":"You are not allowed to answer",
+		"show_alert":true
+	}`, string(requestJSON))
+}
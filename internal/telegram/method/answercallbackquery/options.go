@@ -0,0 +1,57 @@
+package answercallbackquery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxTextLength is the longest notification text Telegram shows.
+const MaxTextLength = 200
+
+// Options contains the answerCallbackQuery parameters supported by the CLI.
+// Text is shown to the user as a notification, or as an alert with ShowAlert.
+type Options struct {
+	CallbackQueryID string
+	Text            string
+	ShowAlert       bool
+}
+
+type payload struct {
+	CallbackQueryID string `json:"callback_query_id"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{
+		CallbackQueryID: o.CallbackQueryID,
+		Text:            o.Text,
+		ShowAlert:       o.ShowAlert,
+	}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.CallbackQueryID == "" {
+		validationErrors = append(validationErrors, "callback query ID is required")
+	}
+	if textLen := utf8.RuneCountInString(o.Text); textLen > MaxTextLength {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("text is too long: must be <= %d characters, got %d", MaxTextLength, textLen),
+		)
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
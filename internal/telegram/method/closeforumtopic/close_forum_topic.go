@@ -0,0 +1,42 @@
+// Package closeforumtopic validates and sends Telegram closeForumTopic
+// requests.
+package closeforumtopic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "closeForumTopic"
+
+// Closer stops new messages in a topic until it is reopened.
+type Closer interface {
+	Close(ctx context.Context, opts *Options) error
+}
+
+type topicCloser struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a forum topic closer backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Closer {
+	return topicCloser{httpClient: httpClient}
+}
+
+// Close validates opts and submits one closeForumTopic request.
+// See https://core.telegram.org/bots/api#closeforumtopic.
+func (c topicCloser) Close(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("close forum topic: %w", err)
+	}
+
+	if err = c.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("close forum topic: %w", err)
+	}
+
+	return nil
+}
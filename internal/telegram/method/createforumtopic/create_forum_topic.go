@@ -0,0 +1,53 @@
+// Package createforumtopic validates and sends Telegram createForumTopic
+// requests.
+package createforumtopic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "createForumTopic"
+
+// ForumTopic is Telegram's description of a created topic.
+// See https://core.telegram.org/bots/api#forumtopic.
+type ForumTopic struct {
+	MessageThreadID   int    `json:"message_thread_id"`
+	Name              string `json:"name"`
+	IconColor         int    `json:"icon_color"`
+	IconCustomEmojiID string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// Creator adds a topic to a forum supergroup.
+type Creator interface {
+	Create(ctx context.Context, opts *Options) (*ForumTopic, error)
+}
+
+type topicCreator struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a forum topic creator backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Creator {
+	return topicCreator{httpClient: httpClient}
+}
+
+// Create validates opts and submits one createForumTopic request. The bot
+// needs the can_manage_topics administrator right.
+// See https://core.telegram.org/bots/api#createforumtopic.
+func (c topicCreator) Create(ctx context.Context, opts *Options) (*ForumTopic, error) {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return nil, fmt.Errorf("create forum topic: %w", err)
+	}
+
+	topic := &ForumTopic{}
+	if err = c.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, topic); err != nil {
+		return nil, fmt.Errorf("create forum topic: %w", err)
+	}
+
+	return topic, nil
+}
@@ -0,0 +1,131 @@
+package createforumtopic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/createforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestCreate_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        createforumtopic.Options
+		expectedErrors []string
+	}{
+		{
+			name:    "required fields",
+			options: createforumtopic.Options{},
+			expectedErrors: []string{
+				"chat ID is required",
+				"topic name is required",
+			},
+		},
+		{
+			name: "name too long and unsupported color",
+			options: createforumtopic.Options{
+				ChatID:    "-1002",
+				Name:      strings.Repeat("a", createforumtopic.MaxNameLength+1),
+				IconColor: "orange",
+			},
+			expectedErrors: []string{
+				"topic name is too long: must be <= 128 characters, got 129",
+				`icon color "orange" is not supported, possible values: blue, yellow, violet, green, rose, red`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			creator := createforumtopic.New(mockHTTPClient)
+			_, err := creator.Create(t.Context(), &tt.options)
+			require.Error(t, err)
+			for _, expectedError := range tt.expectedErrors {
+				assert.ErrorContains(t, err, expectedError)
+			}
+			assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+		})
+	}
+}
+
+func TestCreate_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"createForumTopic": `{"message_thread_id":11,"name":"Deployments","icon_color":9367192}`,
+	}
+	creator := createforumtopic.New(mockHTTPClient)
+
+	topic, err := creator.Create(t.Context(), &createforumtopic.Options{
+		ChatID:            "-1002",
+		Name:              "Deployments",
+		IconColor:         "green",
+		IconCustomEmojiID: "5368324170671202286",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &createforumtopic.ForumTopic{
+		MessageThreadID: 11,
+		Name:            "Deployments",
+		IconColor:       0x8EEE98,
+	}, topic)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "createForumTopic", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"chat_id":"-1002",
+		"name":"Deployments",
+		"icon_color":9367192,
+		"icon_custom_emoji_id":"5368324170671202286"
+	}`, string(requestJSON))
+}
+
+func TestParseIconColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "blue", want: 0x6FB9F0},
+		{value: " Red ", want: 0xFB6F5F},
+		{value: "16766590", want: 0xFFD67E},
+		{value: "0xCB86DB", want: 0xCB86DB},
+		{value: "#ff93b2", want: 0xFF93B2},
+		{value: "orange", wantErr: true},
+		{value: "123", wantErr: true},
+		{value: "#zzzzzz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := createforumtopic.ParseIconColor(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package createforumtopic
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest topic name Telegram accepts.
+const MaxNameLength = 128
+
+// Options contains the createForumTopic parameters supported by the CLI.
+// IconColor is a color name or RGB value accepted by ParseIconColor.
+type Options struct {
+	ChatID            string
+	Name              string
+	IconColor         string
+	IconCustomEmojiID string
+}
+
+type payload struct {
+	ChatID            string `json:"chat_id"`
+	Name              string `json:"name"`
+	IconColor         int    `json:"icon_color,omitempty"`
+	IconCustomEmojiID string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+// iconColors lists the only colors Telegram accepts for topic icons, by the
+// names its apps use.
+func iconColors() map[string]int {
+	return map[string]int{
+		"blue":   0x6FB9F0,
+		"yellow": 0xFFD67E,
+		"violet": 0xCB86DB,
+		"green":  0x8EEE98,
+		"rose":   0xFF93B2,
+		"red":    0xFB6F5F,
+	}
+}
+
+// IconColorNames returns the accepted color names in Telegram's order.
+func IconColorNames() []string {
+	return []string{"blue", "yellow", "violet", "green", "rose", "red"}
+}
+
+// ParseIconColor accepts a color name, a decimal RGB value as used by the Bot
+// API, or a hex value such as 0x6FB9F0 or #6FB9F0. Only Telegram's six topic
+// colors are valid.
+func ParseIconColor(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	colors := iconColors()
+	if color, ok := colors[value]; ok {
+		return color, nil
+	}
+
+	var color int64
+	var err error
+	switch {
+	case strings.HasPrefix(value, "#"):
+		color, err = strconv.ParseInt(value[1:], 16, 32)
+	case strings.HasPrefix(value, "0x"):
+		color, err = strconv.ParseInt(value[2:], 16, 32)
+	default:
+		color, err = strconv.ParseInt(value, 10, 32)
+	}
+	if err == nil {
+		for _, allowed := range colors {
+			if int64(allowed) == color {
+				return allowed, nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf(
+		"icon color %q is not supported, possible values: %s",
+		value,
+		strings.Join(IconColorNames(), ", "),
+	)
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	payload := &payload{
+		ChatID:            o.ChatID,
+		Name:              o.Name,
+		IconCustomEmojiID: o.IconCustomEmojiID,
+	}
+	if o.IconColor != "" {
+		// validate has already rejected unsupported colors.
+		payload.IconColor, _ = ParseIconColor(o.IconColor)
+	}
+
+	return payload, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.Name == "" {
+		validationErrors = append(validationErrors, "topic name is required")
+	}
+	if nameLen := utf8.RuneCountInString(o.Name); nameLen > MaxNameLength {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("topic name is too long: must be <= %d characters, got %d", MaxNameLength, nameLen),
+		)
+	}
+	if o.IconColor != "" {
+		if _, err := ParseIconColor(o.IconColor); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Package deleteforumtopic validates and sends Telegram deleteForumTopic
+// requests.
+package deleteforumtopic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "deleteForumTopic"
+
+// Deleter removes a topic together with all of its messages.
+type Deleter interface {
+	Delete(ctx context.Context, opts *Options) error
+}
+
+type topicDeleter struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a forum topic deleter backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Deleter {
+	return topicDeleter{httpClient: httpClient}
+}
+
+// Delete validates opts and submits one deleteForumTopic request.
+// See https://core.telegram.org/bots/api#deleteforumtopic.
+func (d topicDeleter) Delete(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("delete forum topic: %w", err)
+	}
+
+	if err = d.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("delete forum topic: %w", err)
+	}
+
+	return nil
+}
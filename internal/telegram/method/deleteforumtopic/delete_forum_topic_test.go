@@ -0,0 +1,44 @@
+package deleteforumtopic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/deleteforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestDelete_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	err := deleteforumtopic.New(mockHTTPClient).Delete(t.Context(), &deleteforumtopic.Options{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "chat ID is required")
+	assert.ErrorContains(t, err, "message thread ID must be a positive number")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestDelete_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	err := deleteforumtopic.New(mockHTTPClient).Delete(t.Context(), &deleteforumtopic.Options{
+		ChatID:          "-1002",
+		MessageThreadID: 11,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "deleteForumTopic", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11}`, string(requestJSON))
+}
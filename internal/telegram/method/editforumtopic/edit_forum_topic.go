@@ -0,0 +1,41 @@
+// Package editforumtopic validates and sends Telegram editForumTopic requests.
+package editforumtopic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "editForumTopic"
+
+// Editor renames a topic or changes its icon.
+type Editor interface {
+	Edit(ctx context.Context, opts *Options) error
+}
+
+type topicEditor struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a forum topic editor backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Editor {
+	return topicEditor{httpClient: httpClient}
+}
+
+// Edit validates opts and submits one editForumTopic request.
+// See https://core.telegram.org/bots/api#editforumtopic.
+func (e topicEditor) Edit(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("edit forum topic: %w", err)
+	}
+
+	if err = e.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("edit forum topic: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package editforumtopic_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editforumtopic"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestEdit_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	editor := editforumtopic.New(mockHTTPClient)
+	err := editor.Edit(t.Context(), &editforumtopic.Options{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "chat ID is required")
+	assert.ErrorContains(t, err, "message thread ID must be a positive number")
+	assert.ErrorContains(t, err, "a new name or icon is required")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestEdit_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	editor := editforumtopic.New(mockHTTPClient)
+
+	err := editor.Edit(t.Context(), &editforumtopic.Options{
+		ChatID:          "-1002",
+		MessageThreadID: 11,
+		Name:            "Releases",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "editForumTopic", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11,"name":"Releases"}`, string(requestJSON))
+}
@@ -0,0 +1,66 @@
+package editforumtopic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the longest topic name Telegram accepts.
+const MaxNameLength = 128
+
+// Options contains the editForumTopic parameters supported by the CLI. Empty
+// fields keep their current values.
+type Options struct {
+	ChatID            string
+	MessageThreadID   int
+	Name              string
+	IconCustomEmojiID string
+}
+
+type payload struct {
+	ChatID            string `json:"chat_id"`
+	MessageThreadID   int    `json:"message_thread_id"`
+	Name              string `json:"name,omitempty"`
+	IconCustomEmojiID string `json:"icon_custom_emoji_id,omitempty"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{
+		ChatID:            o.ChatID,
+		MessageThreadID:   o.MessageThreadID,
+		Name:              o.Name,
+		IconCustomEmojiID: o.IconCustomEmojiID,
+	}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.MessageThreadID <= 0 {
+		validationErrors = append(validationErrors, "message thread ID must be a positive number")
+	}
+	if o.Name == "" && o.IconCustomEmojiID == "" {
+		validationErrors = append(validationErrors, "a new name or icon is required")
+	}
+	if nameLen := utf8.RuneCountInString(o.Name); nameLen > MaxNameLength {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("topic name is too long: must be <= %d characters, got %d", MaxNameLength, nameLen),
+		)
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package editmessagetext validates and sends Telegram editMessageText
+// requests.
+package editmessagetext
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "editMessageText"
+
+// Editor replaces the text of a message the bot sent earlier.
+type Editor interface {
+	Edit(ctx context.Context, opts *Options) error
+}
+
+type messageEditor struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a message text editor backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Editor {
+	return messageEditor{httpClient: httpClient}
+}
+
+// Edit validates opts and submits one editMessageText request. Telegram drops
+// the message's inline keyboard because the request carries no reply_markup.
+// See https://core.telegram.org/bots/api#editmessagetext.
+func (e messageEditor) Edit(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("edit message text: %w", err)
+	}
+
+	if err = e.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("edit message text: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package editmessagetext_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/editmessagetext"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestEdit_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        editmessagetext.Options
+		expectedErrors []string
+	}{
+		{
+			name:    "required fields",
+			options: editmessagetext.Options{},
+			expectedErrors: []string{
+				"chat ID is required",
+				"message ID must be a positive number",
+				"message is required",
+			},
+		},
+		{
+			name: "plain text is too long",
+			options: editmessagetext.Options{
+				ChatID:    "123",
+				MessageID: 1,
+				Text:      strings.Repeat("a", editmessagetext.MaxTextLength+1),
+			},
+			expectedErrors: []string{"message is too long: must be <= 4096 characters, got 4097"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			err := editmessagetext.New(mockHTTPClient).Edit(t.Context(), &tt.options)
+			require.Error(t, err)
+			for _, expectedError := range tt.expectedErrors {
+				assert.ErrorContains(t, err, expectedError)
+			}
+			assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+		})
+	}
+}
+
+func TestEdit_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	err := editmessagetext.New(mockHTTPClient).Edit(t.Context(), &editmessagetext.Options{
+		ChatID:             "123",
+		MessageID:          42,
+		Text:               "*Approved*",
+		ParseMode:          "markdown",
+		DisableLinkPreview: true,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "editMessageText", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"chat_id":"123",
+		"message_id":42,
+		"text":"*Approved*",
+		"parse_mode":"MarkdownV2",
+		"link_preview_options":{"is_disabled":true}
+	}`, string(requestJSON))
+}
@@ -0,0 +1,81 @@
+package editmessagetext
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/parsemode"
+)
+
+// MaxTextLength is the longest message text Telegram accepts.
+const MaxTextLength = 4096
+
+// Options contains the editMessageText parameters supported by the CLI.
+type Options struct {
+	ChatID             string
+	MessageID          int
+	Text               string
+	ParseMode          string
+	DisableLinkPreview bool
+}
+
+type payload struct {
+	ChatID             string              `json:"chat_id"`
+	MessageID          int                 `json:"message_id"`
+	Text               string              `json:"text"`
+	ParseMode          string              `json:"parse_mode,omitempty"`
+	LinkPreviewOptions *linkPreviewOptions `json:"link_preview_options,omitempty"`
+}
+
+type linkPreviewOptions struct {
+	IsDisabled bool `json:"is_disabled,omitempty"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	payload := &payload{
+		ChatID:    o.ChatID,
+		MessageID: o.MessageID,
+		Text:      o.Text,
+		ParseMode: parsemode.Normalize(o.ParseMode),
+	}
+	if o.DisableLinkPreview {
+		payload.LinkPreviewOptions = &linkPreviewOptions{IsDisabled: true}
+	}
+
+	return payload, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.MessageID <= 0 {
+		validationErrors = append(validationErrors, "message ID must be a positive number")
+	}
+	if o.Text == "" {
+		validationErrors = append(validationErrors, "message is required")
+	}
+	// As with sendMessage, formatted text is parsed locally to measure it.
+	if parsed, err := markup.Parse(o.Text, o.ParseMode); err != nil {
+		validationErrors = append(validationErrors, "cannot parse message: "+err.Error())
+	} else if textLen := parsed.Length(); textLen > MaxTextLength {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("message is too long: must be <= %d characters, got %d", MaxTextLength, textLen),
+		)
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
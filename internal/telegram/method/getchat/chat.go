@@ -0,0 +1,36 @@
+package getchat
+
+// Chat type values reported by Telegram.
+const (
+	TypePrivate    = "private"
+	TypeGroup      = "group"
+	TypeSupergroup = "supergroup"
+	TypeChannel    = "channel"
+)
+
+// Chat is the subset of Telegram's ChatFullInfo object used by the CLI.
+// See https://core.telegram.org/bots/api#chatfullinfo.
+type Chat struct {
+	ID          int64        `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	FirstName   string       `json:"first_name,omitempty"`
+	LastName    string       `json:"last_name,omitempty"`
+	IsForum     bool         `json:"is_forum,omitempty"`
+	Permissions *Permissions `json:"permissions,omitempty"`
+}
+
+// Permissions are the default member permissions of a group or supergroup.
+// Telegram omits them for private chats and channels.
+// See https://core.telegram.org/bots/api#chatpermissions.
+type Permissions struct {
+	CanSendMessages      bool `json:"can_send_messages,omitempty"`
+	CanSendAudios        bool `json:"can_send_audios,omitempty"`
+	CanSendDocuments     bool `json:"can_send_documents,omitempty"`
+	CanSendPhotos        bool `json:"can_send_photos,omitempty"`
+	CanSendVideos        bool `json:"can_send_videos,omitempty"`
+	CanSendOtherMessages bool `json:"can_send_other_messages,omitempty"`
+	CanPinMessages       bool `json:"can_pin_messages,omitempty"`
+	CanManageTopics      bool `json:"can_manage_topics,omitempty"`
+}
@@ -0,0 +1,44 @@
+// Package getchat validates and sends Telegram getChat requests.
+package getchat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "getChat"
+
+// Getter reads up-to-date information about a chat.
+type Getter interface {
+	Get(ctx context.Context, opts *Options) (*Chat, error)
+}
+
+type chatGetter struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a chat getter backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Getter {
+	return chatGetter{httpClient: httpClient}
+}
+
+// Get validates opts and submits one getChat request. Telegram answers only
+// for chats the bot can see, so an error usually means a wrong ID or a bot
+// that was never added to the chat.
+// See https://core.telegram.org/bots/api#getchat.
+func (g chatGetter) Get(ctx context.Context, opts *Options) (*Chat, error) {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return nil, fmt.Errorf("get chat: %w", err)
+	}
+
+	chat := &Chat{}
+	if err = g.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, chat); err != nil {
+		return nil, fmt.Errorf("get chat: %w", err)
+	}
+
+	return chat, nil
+}
@@ -0,0 +1,56 @@
+package getchat_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchat"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestGet_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	getter := getchat.New(mockHTTPClient)
+	_, err := getter.Get(t.Context(), &getchat.Options{})
+	require.ErrorContains(t, err, "chat ID is required")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestGet_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"getChat": `{"id":-1002,"type":"supergroup","title":"Dev team","is_forum":true,
+			"permissions":{"can_send_messages":true,"can_send_photos":true}}`,
+	}
+	getter := getchat.New(mockHTTPClient)
+
+	chat, err := getter.Get(t.Context(), &getchat.Options{ChatID: "@devteam"})
+	require.NoError(t, err)
+	assert.Equal(t, &getchat.Chat{
+		ID:      -1002,
+		Type:    getchat.TypeSupergroup,
+		Title:   "Dev team",
+		IsForum: true,
+		Permissions: &getchat.Permissions{
+			CanSendMessages: true,
+			CanSendPhotos:   true,
+		},
+	}, chat)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "getChat", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"@devteam"}`, string(requestJSON))
+}
@@ -0,0 +1,38 @@
+package getchat
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Options contains the getChat parameters supported by the CLI.
+type Options struct {
+	ChatID string
+}
+
+type payload struct {
+	ChatID string `json:"chat_id"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{ChatID: o.ChatID}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package getchatmember
+
+// Member status values reported by Telegram.
+const (
+	StatusCreator       = "creator"
+	StatusAdministrator = "administrator"
+	StatusMember        = "member"
+	StatusRestricted    = "restricted"
+	StatusLeft          = "left"
+	StatusKicked        = "kicked"
+)
+
+// ChatMember flattens Telegram's ChatMember variants. Which rights are
+// meaningful depends on Status: administrator rights for administrators and
+// send rights for restricted members. Telegram omits false values, so the
+// zero value of an unused field is safe to ignore.
+// See https://core.telegram.org/bots/api#chatmember.
+type ChatMember struct {
+	Status   string `json:"status"`
+	IsMember bool   `json:"is_member,omitempty"`
+
+	CanPostMessages bool `json:"can_post_messages,omitempty"`
+	CanEditMessages bool `json:"can_edit_messages,omitempty"`
+	CanPinMessages  bool `json:"can_pin_messages,omitempty"`
+	CanManageTopics bool `json:"can_manage_topics,omitempty"`
+
+	CanSendMessages      bool `json:"can_send_messages,omitempty"`
+	CanSendAudios        bool `json:"can_send_audios,omitempty"`
+	CanSendDocuments     bool `json:"can_send_documents,omitempty"`
+	CanSendPhotos        bool `json:"can_send_photos,omitempty"`
+	CanSendVideos        bool `json:"can_send_videos,omitempty"`
+	CanSendOtherMessages bool `json:"can_send_other_messages,omitempty"`
+}
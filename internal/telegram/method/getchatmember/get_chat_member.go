@@ -0,0 +1,42 @@
+// Package getchatmember validates and sends Telegram getChatMember requests.
+package getchatmember
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "getChatMember"
+
+// Getter reads the membership and rights of one user in a chat.
+type Getter interface {
+	Get(ctx context.Context, opts *Options) (*ChatMember, error)
+}
+
+type chatMemberGetter struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a chat member getter backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Getter {
+	return chatMemberGetter{httpClient: httpClient}
+}
+
+// Get validates opts and submits one getChatMember request.
+// See https://core.telegram.org/bots/api#getchatmember.
+func (g chatMemberGetter) Get(ctx context.Context, opts *Options) (*ChatMember, error) {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return nil, fmt.Errorf("get chat member: %w", err)
+	}
+
+	member := &ChatMember{}
+	if err = g.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, member); err != nil {
+		return nil, fmt.Errorf("get chat member: %w", err)
+	}
+
+	return member, nil
+}
@@ -0,0 +1,52 @@
+package getchatmember_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getchatmember"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestGet_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	getter := getchatmember.New(mockHTTPClient)
+	_, err := getter.Get(t.Context(), &getchatmember.Options{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "chat ID is required")
+	assert.ErrorContains(t, err, "user ID must be a positive number")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestGet_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"getChatMember": `{"status":"administrator","user":{"id":123},"can_post_messages":true,"can_pin_messages":true}`,
+	}
+	getter := getchatmember.New(mockHTTPClient)
+
+	member, err := getter.Get(t.Context(), &getchatmember.Options{ChatID: "-1001", UserID: 123})
+	require.NoError(t, err)
+	assert.Equal(t, &getchatmember.ChatMember{
+		Status:          getchatmember.StatusAdministrator,
+		CanPostMessages: true,
+		CanPinMessages:  true,
+	}, member)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "getChatMember", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"-1001","user_id":123}`, string(requestJSON))
+}
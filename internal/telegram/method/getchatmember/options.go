@@ -0,0 +1,43 @@
+package getchatmember
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Options contains the getChatMember parameters supported by the CLI.
+type Options struct {
+	ChatID string
+	UserID int64
+}
+
+type payload struct {
+	ChatID string `json:"chat_id"`
+	UserID int64  `json:"user_id"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{ChatID: o.ChatID, UserID: o.UserID}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.UserID <= 0 {
+		validationErrors = append(validationErrors, "user ID must be a positive number")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
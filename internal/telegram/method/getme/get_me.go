@@ -0,0 +1,49 @@
+// Package getme sends Telegram getMe requests, which identify the bot that
+// owns a token.
+package getme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "getMe"
+
+// User is the subset of Telegram's User object returned for the bot itself.
+// See https://core.telegram.org/bots/api#user.
+type User struct {
+	ID                      int64  `json:"id"`
+	IsBot                   bool   `json:"is_bot"`
+	FirstName               string `json:"first_name"`
+	Username                string `json:"username,omitempty"`
+	CanJoinGroups           bool   `json:"can_join_groups,omitempty"`
+	CanReadAllGroupMessages bool   `json:"can_read_all_group_messages,omitempty"`
+}
+
+// Getter identifies the bot.
+type Getter interface {
+	Get(ctx context.Context) (*User, error)
+}
+
+type meGetter struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a bot identity getter backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Getter {
+	return meGetter{httpClient: httpClient}
+}
+
+// Get submits one getMe request. It is the cheapest way to check that a token
+// is valid. See https://core.telegram.org/bots/api#getme.
+func (g meGetter) Get(ctx context.Context) (*User, error) {
+	user := &User{}
+	if err := g.httpClient.SubmitJSON(ctx, http.MethodGet, telegramAPIEndpoint, nil, user); err != nil {
+		return nil, fmt.Errorf("get me: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,38 @@
+package getme_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getme"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestGet_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"getMe": `{"id":123,"is_bot":true,"first_name":"Owl","username":"owl_bot","can_join_groups":true}`,
+	}
+	getter := getme.New(mockHTTPClient)
+
+	user, err := getter.Get(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, &getme.User{
+		ID:            123,
+		IsBot:         true,
+		FirstName:     "Owl",
+		Username:      "owl_bot",
+		CanJoinGroups: true,
+	}, user)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodGet, request.Method)
+	assert.Equal(t, "getMe", request.Endpoint)
+	assert.Nil(t, request.Body)
+}
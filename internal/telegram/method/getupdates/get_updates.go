@@ -0,0 +1,46 @@
+// Package getupdates validates and sends Telegram getUpdates requests.
+//
+// getUpdates confirms every update with an identifier lower than Offset. Leave
+// Offset at zero to read pending updates without consuming them.
+package getupdates
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "getUpdates"
+
+// Getter receives pending updates for the bot.
+type Getter interface {
+	Get(ctx context.Context, opts *Options) ([]Update, error)
+}
+
+type updatesGetter struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns an updates getter backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Getter {
+	return updatesGetter{httpClient: httpClient}
+}
+
+// Get validates opts and submits one getUpdates request. With a non-zero
+// Timeout Telegram holds the request open until an update arrives.
+// See https://core.telegram.org/bots/api#getupdates.
+func (g updatesGetter) Get(ctx context.Context, opts *Options) ([]Update, error) {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return nil, fmt.Errorf("get updates: %w", err)
+	}
+
+	var updates []Update
+	if err = g.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, &updates); err != nil {
+		return nil, fmt.Errorf("get updates: %w", err)
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,117 @@
+package getupdates_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/getupdates"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestGet_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	getter := getupdates.New(testutils.NewMockHTTPDoer())
+	_, err := getter.Get(t.Context(), &getupdates.Options{Limit: 101, TimeoutSeconds: 26})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "limit must be between 0 and 100")
+	assert.ErrorContains(t, err, "timeout must be between 0 and 25 seconds")
+}
+
+func TestGet_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"getUpdates": `[
+			{"update_id":10,"message":{"message_id":1,"chat":{"id":75757,"type":"private","first_name":"Ada"}}},
+			{"update_id":11,"my_chat_member":{"chat":{"id":-1001,"type":"channel","title":"Alerts"}}}
+		]`,
+	}
+	getter := getupdates.New(mockHTTPClient)
+
+	updates, err := getter.Get(t.Context(), &getupdates.Options{
+		Offset:         5,
+		TimeoutSeconds: 25,
+		AllowedUpdates: []string{"message"},
+	})
+	require.NoError(t, err)
+	require.Len(t, updates, 2)
+	assert.Equal(t, int64(75757), updates[0].Chat().ID)
+	assert.Equal(t, "Alerts", updates[1].Chat().Title)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "getUpdates", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"offset":5,"timeout":25,"allowed_updates":["message"]}`, string(requestJSON))
+}
+
+func TestGet_DefaultsDoNotConsumeUpdates(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{"getUpdates": `[]`}
+	getter := getupdates.New(mockHTTPClient)
+
+	updates, err := getter.Get(t.Context(), &getupdates.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, updates)
+
+	requestJSON, err := json.Marshal(mockHTTPClient.SubmitJSONResult[0].Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(requestJSON))
+}
+
+func TestMessage_TopicName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		message getupdates.Message
+		want    string
+	}{
+		{
+			name:    "plain message",
+			message: getupdates.Message{},
+		},
+		{
+			name: "topic creation",
+			message: getupdates.Message{
+				ForumTopicCreated: &getupdates.ForumTopicCreated{Name: "Deployments"},
+			},
+			want: "Deployments",
+		},
+		{
+			name: "topic rename",
+			message: getupdates.Message{
+				ForumTopicEdited: &getupdates.ForumTopicEdited{Name: "Releases"},
+			},
+			want: "Releases",
+		},
+		{
+			name: "reply to topic creation",
+			message: getupdates.Message{
+				ReplyToMessage: &getupdates.Message{
+					ForumTopicCreated: &getupdates.ForumTopicCreated{Name: "Deployments"},
+				},
+			},
+			want: "Deployments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.message.TopicName())
+		})
+	}
+}
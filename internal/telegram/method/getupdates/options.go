@@ -0,0 +1,63 @@
+package getupdates
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxLimit is the largest number of updates Telegram returns at once.
+	MaxLimit = 100
+	// MaxTimeoutSeconds keeps a long poll below the HTTP client's request
+	// timeout, so an idle poll ends with an empty result instead of an error.
+	MaxTimeoutSeconds = 25
+)
+
+// Options contains the getUpdates parameters supported by the CLI.
+type Options struct {
+	Offset         int
+	Limit          int
+	TimeoutSeconds int
+	AllowedUpdates []string
+}
+
+type payload struct {
+	Offset         int      `json:"offset,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	Timeout        int      `json:"timeout,omitempty"`
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{
+		Offset:         o.Offset,
+		Limit:          o.Limit,
+		Timeout:        o.TimeoutSeconds,
+		AllowedUpdates: o.AllowedUpdates,
+	}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.Limit < 0 || o.Limit > MaxLimit {
+		validationErrors = append(validationErrors, fmt.Sprintf("limit must be between 0 and %d", MaxLimit))
+	}
+	if o.TimeoutSeconds < 0 || o.TimeoutSeconds > MaxTimeoutSeconds {
+		validationErrors = append(
+			validationErrors,
+			fmt.Sprintf("timeout must be between 0 and %d seconds", MaxTimeoutSeconds),
+		)
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
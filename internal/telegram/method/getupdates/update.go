@@ -0,0 +1,119 @@
+package getupdates
+
+// Update is the subset of Telegram's Update object used by the CLI.
+// See https://core.telegram.org/bots/api#update.
+type Update struct {
+	UpdateID          int                `json:"update_id"`
+	Message           *Message           `json:"message,omitempty"`
+	EditedMessage     *Message           `json:"edited_message,omitempty"`
+	ChannelPost       *Message           `json:"channel_post,omitempty"`
+	EditedChannelPost *Message           `json:"edited_channel_post,omitempty"`
+	MyChatMember      *ChatMemberUpdated `json:"my_chat_member,omitempty"`
+	ChatMember        *ChatMemberUpdated `json:"chat_member,omitempty"`
+	CallbackQuery     *CallbackQuery     `json:"callback_query,omitempty"`
+}
+
+// UpdateTypeCallbackQuery selects button presses in Options.AllowedUpdates.
+const UpdateTypeCallbackQuery = "callback_query"
+
+// CallbackQuery is sent when a user presses an inline keyboard button. Message
+// is the bot's message carrying the button; Telegram omits it when the message
+// is too old.
+// See https://core.telegram.org/bots/api#callbackquery.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    User     `json:"from"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// User identifies the person behind an update.
+type User struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Message is the subset of Telegram's Message object needed to identify where
+// it was posted.
+type Message struct {
+	MessageID         int                `json:"message_id"`
+	MessageThreadID   int                `json:"message_thread_id,omitempty"`
+	IsTopicMessage    bool               `json:"is_topic_message,omitempty"`
+	Chat              Chat               `json:"chat"`
+	Text              string             `json:"text,omitempty"`
+	ReplyToMessage    *Message           `json:"reply_to_message,omitempty"`
+	ForumTopicCreated *ForumTopicCreated `json:"forum_topic_created,omitempty"`
+	ForumTopicEdited  *ForumTopicEdited  `json:"forum_topic_edited,omitempty"`
+}
+
+// Chat identifies a private chat, group, supergroup, or channel.
+type Chat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	IsForum   bool   `json:"is_forum,omitempty"`
+}
+
+// ChatMemberUpdated reports membership changes, including the bot being added
+// to a group or channel before anyone posts there.
+type ChatMemberUpdated struct {
+	Chat Chat `json:"chat"`
+}
+
+// ForumTopicCreated is the service message that starts a forum topic.
+type ForumTopicCreated struct {
+	Name string `json:"name"`
+}
+
+// ForumTopicEdited is the service message sent when a topic is renamed. Name
+// is empty when only the icon changed.
+type ForumTopicEdited struct {
+	Name string `json:"name,omitempty"`
+}
+
+// Messages returns every message carried by u, in field order.
+func (u Update) Messages() []*Message {
+	messages := make([]*Message, 0, 1)
+	for _, message := range []*Message{u.Message, u.EditedMessage, u.ChannelPost, u.EditedChannelPost} {
+		if message != nil {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages
+}
+
+// Chat returns the chat u belongs to, or nil for update kinds without one.
+func (u Update) Chat() *Chat {
+	if messages := u.Messages(); len(messages) > 0 {
+		return &messages[0].Chat
+	}
+
+	for _, memberUpdate := range []*ChatMemberUpdated{u.MyChatMember, u.ChatMember} {
+		if memberUpdate != nil {
+			return &memberUpdate.Chat
+		}
+	}
+
+	return nil
+}
+
+// TopicName returns the forum topic name m reveals, if any. Messages inside a
+// topic reply to the service message that created it.
+func (m *Message) TopicName() string {
+	switch {
+	case m.ForumTopicEdited != nil && m.ForumTopicEdited.Name != "":
+		return m.ForumTopicEdited.Name
+	case m.ForumTopicCreated != nil:
+		return m.ForumTopicCreated.Name
+	case m.ReplyToMessage != nil && m.ReplyToMessage.ForumTopicCreated != nil:
+		return m.ReplyToMessage.ForumTopicCreated.Name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,43 @@
+package reopenforumtopic
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Options contains the reopenForumTopic parameters supported by the CLI.
+type Options struct {
+	ChatID          string
+	MessageThreadID int
+}
+
+type payload struct {
+	ChatID          string `json:"chat_id"`
+	MessageThreadID int    `json:"message_thread_id"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{ChatID: o.ChatID, MessageThreadID: o.MessageThreadID}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.MessageThreadID <= 0 {
+		validationErrors = append(validationErrors, "message thread ID must be a positive number")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+// Package reopenforumtopic validates and sends Telegram reopenForumTopic
+// requests.
+package reopenforumtopic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "reopenForumTopic"
+
+// Reopener allows messages in a closed topic again.
+type Reopener interface {
+	Reopen(ctx context.Context, opts *Options) error
+}
+
+type topicReopener struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a forum topic reopener backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Reopener {
+	return topicReopener{httpClient: httpClient}
+}
+
+// Reopen validates opts and submits one reopenForumTopic request.
+// See https://core.telegram.org/bots/api#reopenforumtopic.
+func (r topicReopener) Reopen(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("reopen forum topic: %w", err)
+	}
+
+	if err = r.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("reopen forum topic: %w", err)
+	}
+
+	return nil
+}
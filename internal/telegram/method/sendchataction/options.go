@@ -0,0 +1,52 @@
+package sendchataction
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ActionTyping is the action shown while a text message is being prepared.
+const ActionTyping = "typing"
+
+// Options contains the sendChatAction parameters supported by the CLI.
+type Options struct {
+	ChatID          string
+	MessageThreadID string
+	Action          string
+}
+
+type payload struct {
+	ChatID          string `json:"chat_id"`
+	MessageThreadID string `json:"message_thread_id,omitempty"`
+	Action          string `json:"action"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return &payload{
+		ChatID:          o.ChatID,
+		MessageThreadID: o.MessageThreadID,
+		Action:          o.Action,
+	}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.Action == "" {
+		validationErrors = append(validationErrors, "action is required")
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+// Package sendchataction validates and sends Telegram sendChatAction requests.
+package sendchataction
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "sendChatAction"
+
+// Sender shows a short-lived status such as "typing" in a chat.
+type Sender interface {
+	Send(ctx context.Context, opts *Options) error
+}
+
+type chatActionSender struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a chat action sender backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Sender {
+	return chatActionSender{httpClient: httpClient}
+}
+
+// Send validates opts and submits one sendChatAction request. The status
+// clears after a few seconds and leaves no message behind, which also makes
+// the request a harmless probe for a forum topic ID.
+// See https://core.telegram.org/bots/api#sendchataction.
+func (s chatActionSender) Send(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("send: failed to send chat action: %w", err)
+	}
+
+	return nil
+}
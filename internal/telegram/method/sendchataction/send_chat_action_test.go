@@ -0,0 +1,48 @@
+package sendchataction_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/sendchataction"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestSend_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendchataction.New(mockHTTPClient)
+	err := sender.Send(t.Context(), &sendchataction.Options{})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "chat ID is required")
+	assert.ErrorContains(t, err, "action is required")
+	assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+}
+
+func TestSend_Success(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendchataction.New(mockHTTPClient)
+
+	err := sender.Send(t.Context(), &sendchataction.Options{
+		ChatID:          "-1002",
+		MessageThreadID: "11",
+		Action:          sendchataction.ActionTyping,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	request := mockHTTPClient.SubmitJSONResult[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "sendChatAction", request.Endpoint)
+
+	requestJSON, err := json.Marshal(request.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":"11","action":"typing"}`, string(requestJSON))
+}
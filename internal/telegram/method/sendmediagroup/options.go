@@ -1,13 +1,15 @@
 package sendmediagroup
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
-	"unicode/utf8"
 
 	attach "github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
 	"github.com/beeyev/telegram-owl/internal/telegram/common/parsemode"
 	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
 )
@@ -18,10 +20,12 @@ const MaxCaptionLength = 1024
 // Options contains the user-visible sendMediaGroup parameters supported by the
 // CLI. Attachments must remain open until Sender.Send returns.
 type Options struct {
-	ChatID              string
-	MessageThreadID     string
-	Caption             string
-	ParseMode           string
+	ChatID          string
+	MessageThreadID string
+	Caption         string
+	ParseMode       string
+	// CaptionEntities format Caption instead of a parse mode.
+	CaptionEntities     []entity.MessageEntity
 	HasSpoiler          bool
 	DisableNotification bool
 	ProtectContent      bool
@@ -38,11 +42,12 @@ type payload struct {
 
 // media is one InputMedia entry in Telegram's JSON-encoded media form field.
 type media struct {
-	Type       string `json:"type"`
-	Media      string `json:"media"`
-	Caption    string `json:"caption,omitempty"`
-	ParseMode  string `json:"parse_mode,omitempty"`
-	HasSpoiler bool   `json:"has_spoiler,omitempty"`
+	Type            string                 `json:"type"`
+	Media           string                 `json:"media"`
+	Caption         string                 `json:"caption,omitempty"`
+	ParseMode       string                 `json:"parse_mode,omitempty"`
+	CaptionEntities []entity.MessageEntity `json:"caption_entities,omitempty"`
+	HasSpoiler      bool                   `json:"has_spoiler,omitempty"`
 }
 
 func (o *Options) preparePayload() (*payload, []httpclient.MultipartFile, error) {
@@ -73,6 +78,18 @@ func (o *Options) prepareMedia() ([]media, []httpclient.MultipartFile) {
 	multipartFiles := make([]httpclient.MultipartFile, 0, len(o.Attachments))
 
 	for i, attachment := range o.Attachments {
+		if reference := cmp.Or(attachment.FileID, attachment.URL); reference != "" {
+			// Telegram already stores file IDs and downloads URLs itself, so
+			// there is nothing to upload.
+			medias = append(medias, media{
+				Type:       attachment.AType.String(),
+				Media:      reference,
+				HasSpoiler: o.HasSpoiler,
+			})
+
+			continue
+		}
+
 		formFieldName := fmt.Sprintf("file%d", i)
 
 		medias = append(medias, media{
@@ -94,6 +111,7 @@ func (o *Options) prepareMedia() ([]media, []httpclient.MultipartFile) {
 	lastMedia.Caption = o.Caption
 	if o.Caption != "" {
 		lastMedia.ParseMode = parsemode.Normalize(o.ParseMode)
+		lastMedia.CaptionEntities = o.CaptionEntities
 	}
 
 	return medias, multipartFiles
@@ -108,16 +126,27 @@ func (o *Options) validate() error {
 	if len(o.Attachments) == 0 {
 		validationErrors = append(validationErrors, "at least one attachment required")
 	}
-	// Telegram applies the limit after parsing entities. Without duplicating
-	// Telegram's parser, only plain-text length can be validated accurately.
-	captionLen := utf8.RuneCountInString(o.Caption)
-	if o.ParseMode == "" && captionLen > MaxCaptionLength {
+	// Telegram applies the limit after parsing entities, so formatted captions
+	// are parsed locally. Syntax errors then point at a line and column rather
+	// than at Telegram's byte offset.
+	if parsed, err := markup.Parse(o.Caption, o.ParseMode); err != nil {
+		validationErrors = append(validationErrors, "cannot parse caption: "+err.Error())
+	} else if captionLen := parsed.Length(); captionLen > MaxCaptionLength {
 		validationErrors = append(
 			validationErrors,
 			fmt.Sprintf("message is too long: must be <= %d characters, got %d", MaxCaptionLength, captionLen),
 		)
 	}
 
+	if len(o.CaptionEntities) > 0 {
+		if o.ParseMode != "" {
+			validationErrors = append(validationErrors, "caption entities cannot be combined with a parse mode")
+		}
+		if err := entity.Validate(o.Caption, o.CaptionEntities); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
 	if len(validationErrors) > 0 {
 		return errors.New(strings.Join(validationErrors, "; "))
 	}
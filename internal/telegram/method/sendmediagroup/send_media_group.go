@@ -12,9 +12,44 @@ import (
 
 const telegramAPIEndpoint = "sendMediaGroup"
 
+// Message is one sent album item, with the file Telegram stored for it.
+type Message struct {
+	MessageID int    `json:"message_id"`
+	Photo     []File `json:"photo,omitempty"`
+	Video     *File  `json:"video,omitempty"`
+	Animation *File  `json:"animation,omitempty"`
+	Audio     *File  `json:"audio,omitempty"`
+	Document  *File  `json:"document,omitempty"`
+}
+
+// File is the part of Telegram's PhotoSize, Video, Audio and Document objects
+// needed to send the file again.
+type File struct {
+	FileID string `json:"file_id"`
+}
+
+// FileID returns the file_id that sends the same media again, or "" when the
+// message carries none. Photos come in several sizes, the largest last.
+func (m Message) FileID() string {
+	switch {
+	case len(m.Photo) > 0:
+		return m.Photo[len(m.Photo)-1].FileID
+	case m.Video != nil:
+		return m.Video.FileID
+	case m.Animation != nil:
+		return m.Animation.FileID
+	case m.Audio != nil:
+		return m.Audio.FileID
+	case m.Document != nil:
+		return m.Document.FileID
+	default:
+		return ""
+	}
+}
+
 // Sender sends an attachment group to a Telegram chat.
 type Sender interface {
-	Send(ctx context.Context, opts *Options) error
+	Send(ctx context.Context, opts *Options) ([]Message, error)
 }
 
 type mediaSender struct {
@@ -26,28 +61,31 @@ func New(httpClient httpclient.HTTPDoer) Sender {
 	return mediaSender{httpClient: httpClient}
 }
 
-// Send validates opts and submits one sendMediaGroup multipart request.
+// Send validates opts, submits one sendMediaGroup multipart request and
+// returns the sent messages in the order of opts.Attachments.
 // See https://core.telegram.org/bots/api#sendmediagroup.
-func (s mediaSender) Send(ctx context.Context, opts *Options) error {
+func (s mediaSender) Send(ctx context.Context, opts *Options) ([]Message, error) {
 	payloadData, multipartFiles, err := opts.preparePayload()
 	if err != nil {
-		return fmt.Errorf("send media: %w", err)
+		return nil, fmt.Errorf("send media: %w", err)
 	}
 
 	formFields, err := util.StructToFormPayload(payloadData)
 	if err != nil {
-		return fmt.Errorf("unable to create form fields from the payload. Details: %w", err)
+		return nil, fmt.Errorf("unable to create form fields from the payload. Details: %w", err)
 	}
 
+	var messages []Message
 	if err = s.httpClient.SubmitMultipart(
 		ctx,
 		http.MethodPost,
 		telegramAPIEndpoint,
 		formFields,
 		multipartFiles,
+		&messages,
 	); err != nil {
-		return fmt.Errorf("failed to send media: %w", err)
+		return nil, fmt.Errorf("failed to send media: %w", err)
 	}
 
-	return nil
+	return messages, nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmediagroup"
 	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
 )
@@ -40,6 +41,19 @@ func TestSend_ValidationErrors(t *testing.T) {
 				"message is too long",
 			},
 		},
+		{
+			name: "caption entities with a parse mode or outside the caption",
+			options: sendmediagroup.Options{
+				ChatID:          "123",
+				Caption:         "hello",
+				ParseMode:       "markdown",
+				CaptionEntities: []entity.MessageEntity{{Type: "italic", Offset: 5, Length: 1}},
+			},
+			expectedErrors: []string{
+				"caption entities cannot be combined with a parse mode",
+				"entity 0 (italic): offset 5 and length 1 end after the text",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -47,7 +61,7 @@ func TestSend_ValidationErrors(t *testing.T) {
 			t.Parallel()
 
 			sender := sendmediagroup.New(testutils.NewMockHTTPDoer())
-			err := sender.Send(t.Context(), &tt.options)
+			_, err := sender.Send(t.Context(), &tt.options)
 			require.Error(t, err)
 			for _, expectedError := range tt.expectedErrors {
 				assert.Containsf(t, err.Error(), expectedError, "expected error not found")
@@ -82,7 +96,7 @@ func TestSend_Success(t *testing.T) {
 	mockHTTPClient := testutils.NewMockHTTPDoer()
 	sender := sendmediagroup.New(mockHTTPClient)
 
-	err := sender.Send(t.Context(), options)
+	_, err := sender.Send(t.Context(), options)
 	require.NoError(t, err)
 	require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
 
@@ -93,28 +107,61 @@ func TestSend_Success(t *testing.T) {
 	assert.Exactly(t, expected, mockHTTPClient.SubmitMultipartResult[0].Fields, "unexpected request payload")
 }
 
-func TestSend_DelegatesFormattedCaptionLengthValidation(t *testing.T) {
+func TestSend_FormattedCaptionLengthValidation(t *testing.T) {
 	t.Parallel()
 
-	options := &sendmediagroup.Options{
-		ChatID:    "123",
-		Caption:   strings.Repeat("a", sendmediagroup.MaxCaptionLength+1),
-		ParseMode: "html",
-		Attachments: attachment.Attachments{
-			{
-				AType:    attachment.Photo,
-				FileName: "file.jpg",
-				File:     &os.File{},
-			},
+	tests := []struct {
+		name    string
+		caption string
+		wantErr string
+	}{
+		{
+			name:    "markup does not count towards the limit",
+			caption: "*" + strings.Repeat("a", sendmediagroup.MaxCaptionLength) + "*",
+		},
+		{
+			name:    "parsed caption over the limit",
+			caption: "*" + strings.Repeat("a", sendmediagroup.MaxCaptionLength+1) + "*",
+			wantErr: "validation failed: message is too long: must be <= 1024 characters, got 1025",
+		},
+		{
+			name:    "syntax error",
+			caption: "Done.",
+			wantErr: `validation failed: cannot parse caption: line 1, column 5: character "." is reserved`,
 		},
 	}
 
-	mockHTTPClient := testutils.NewMockHTTPDoer()
-	sender := sendmediagroup.New(mockHTTPClient)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	err := sender.Send(t.Context(), options)
-	require.NoError(t, err)
-	require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
+			options := &sendmediagroup.Options{
+				ChatID:    "123",
+				Caption:   tt.caption,
+				ParseMode: "MarkdownV2",
+				Attachments: attachment.Attachments{
+					{
+						AType:    attachment.Photo,
+						FileName: "file.jpg",
+						File:     &os.File{},
+					},
+				},
+			}
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			sender := sendmediagroup.New(mockHTTPClient)
+
+			_, err := sender.Send(t.Context(), options)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
+
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, mockHTTPClient.SubmitMultipartResult)
+		})
+	}
 }
 
 func TestSend_Success2(t *testing.T) {
@@ -207,7 +254,7 @@ func TestSend_Success2(t *testing.T) {
 			mockHTTPClient := testutils.NewMockHTTPDoer()
 			sender := sendmediagroup.New(mockHTTPClient)
 
-			err := sender.Send(t.Context(), tt.options)
+			_, err := sender.Send(t.Context(), tt.options)
 			require.NoError(t, err)
 			require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
 
@@ -217,3 +264,92 @@ func TestSend_Success2(t *testing.T) {
 		})
 	}
 }
+
+func TestSend_CaptionEntities(t *testing.T) {
+	t.Parallel()
+
+	options := &sendmediagroup.Options{
+		ChatID:          "123",
+		Caption:         "report.pdf",
+		CaptionEntities: []entity.MessageEntity{{Type: "code", Offset: 0, Length: 10}},
+		Attachments: attachment.Attachments{
+			{AType: attachment.Document, FileName: "report.pdf", File: &os.File{}},
+		},
+	}
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendmediagroup.New(mockHTTPClient)
+
+	_, err := sender.Send(t.Context(), options)
+	require.NoError(t, err)
+	require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
+	assert.JSONEq(
+		t,
+		`[{"type":"document","media":"attach://file0","caption":"report.pdf",`+
+			`"caption_entities":[{"type":"code","offset":0,"length":10}]}]`,
+		mockHTTPClient.SubmitMultipartResult[0].Fields["media"],
+	)
+}
+
+func TestSend_URLAttachments(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendmediagroup.New(mockHTTPClient)
+
+	_, err := sender.Send(t.Context(), &sendmediagroup.Options{
+		ChatID: "123",
+		Attachments: attachment.Attachments{
+			{AType: attachment.Photo, FileName: "daily.png", URL: "https://example.com/daily.png"},
+			{AType: attachment.Photo, FileName: "local.png", SizeBytes: 1024, File: &os.File{}},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, mockHTTPClient.SubmitMultipartResult, 1)
+
+	result := mockHTTPClient.SubmitMultipartResult[0]
+	assert.JSONEq(
+		t,
+		`[{"type":"photo","media":"https://example.com/daily.png"},{"type":"photo","media":"attach://file1"}]`,
+		result.Fields["media"],
+	)
+	require.Len(t, result.Files, 1)
+	assert.Equal(t, "file1", result.Files[0].FieldName)
+}
+
+func TestSend_ReturnsFileIDs(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{
+		"sendMediaGroup": `[
+			{"message_id":1,"photo":[{"file_id":"small"},{"file_id":"large"}]},
+			{"message_id":2,"document":{"file_id":"doc"}},
+			{"message_id":3}
+		]`,
+	}
+	sender := sendmediagroup.New(mockHTTPClient)
+
+	messages, err := sender.Send(t.Context(), &sendmediagroup.Options{
+		ChatID: "123",
+		Attachments: attachment.Attachments{
+			{AType: attachment.Photo, FileName: "logo.png", FileID: "cached"},
+			{AType: attachment.Document, FileName: "report.pdf", SizeBytes: 1024, File: &os.File{}},
+		},
+	})
+	require.NoError(t, err)
+
+	fileIDs := make([]string, 0, len(messages))
+	for _, message := range messages {
+		fileIDs = append(fileIDs, message.FileID())
+	}
+	assert.Equal(t, []string{"large", "doc", ""}, fileIDs)
+
+	result := mockHTTPClient.SubmitMultipartResult[0]
+	assert.JSONEq(
+		t,
+		`[{"type":"photo","media":"cached"},{"type":"document","media":"attach://file1"}]`,
+		result.Fields["media"],
+	)
+	require.Len(t, result.Files, 1)
+}
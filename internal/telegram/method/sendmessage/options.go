@@ -4,8 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
-	"unicode/utf8"
 
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/markup"
 	"github.com/beeyev/telegram-owl/internal/telegram/common/parsemode"
 )
 
@@ -15,30 +16,59 @@ const MaxTextLength = 4096
 // Options contains the user-visible sendMessage parameters supported by the
 // CLI. preparePayload translates these fields to Telegram's wire schema.
 type Options struct {
-	ChatID              string
-	MessageThreadID     string
-	Text                string
-	ParseMode           string
+	ChatID          string
+	MessageThreadID string
+	Text            string
+	ParseMode       string
+	// Entities format Text instead of a parse mode.
+	Entities            []entity.MessageEntity
 	HasSpoiler          bool
 	DisableNotification bool
 	ProtectContent      bool
 	DisableLinkPreview  bool
+	ReplyMarkup         *InlineKeyboardMarkup
+	// ReplyToMessageID makes the message a reply to another message in the
+	// same chat. Zero sends a standalone message.
+	ReplyToMessageID int
 }
 
+// InlineKeyboardMarkup attaches buttons below the message.
+// See https://core.telegram.org/bots/api#inlinekeyboardmarkup.
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+// InlineKeyboardButton is a callback button. Telegram sends CallbackData back
+// in a callback_query update when the button is pressed.
+type InlineKeyboardButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// MaxCallbackDataBytes is the largest callback_data Telegram accepts.
+const MaxCallbackDataBytes = 64
+
 type payload struct {
-	ChatID              string              `json:"chat_id"`
-	MessageThreadID     string              `json:"message_thread_id,omitempty"`
-	Text                string              `json:"text"`
-	ParseMode           string              `json:"parse_mode,omitempty"`
-	DisableNotification bool                `json:"disable_notification,omitempty"`
-	ProtectContent      bool                `json:"protect_content,omitempty"`
-	LinkPreviewOptions  *linkPreviewOptions `json:"link_preview_options,omitempty"`
+	ChatID              string                 `json:"chat_id"`
+	MessageThreadID     string                 `json:"message_thread_id,omitempty"`
+	Text                string                 `json:"text"`
+	ParseMode           string                 `json:"parse_mode,omitempty"`
+	Entities            []entity.MessageEntity `json:"entities,omitempty"`
+	DisableNotification bool                   `json:"disable_notification,omitempty"`
+	ProtectContent      bool                   `json:"protect_content,omitempty"`
+	LinkPreviewOptions  *linkPreviewOptions    `json:"link_preview_options,omitempty"`
+	ReplyMarkup         *InlineKeyboardMarkup  `json:"reply_markup,omitempty"`
+	ReplyParameters     *replyParameters       `json:"reply_parameters,omitempty"`
 }
 
 type linkPreviewOptions struct {
 	IsDisabled bool `json:"is_disabled,omitempty"`
 }
 
+type replyParameters struct {
+	MessageID int `json:"message_id"`
+}
+
 func (o *Options) preparePayload() (*payload, error) {
 	if err := o.validate(); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -49,13 +79,18 @@ func (o *Options) preparePayload() (*payload, error) {
 		MessageThreadID:     o.MessageThreadID,
 		Text:                o.Text,
 		ParseMode:           parsemode.Normalize(o.ParseMode),
+		Entities:            o.Entities,
 		DisableNotification: o.DisableNotification,
 		ProtectContent:      o.ProtectContent,
+		ReplyMarkup:         o.ReplyMarkup,
 	}
 
 	if o.DisableLinkPreview {
 		payload.LinkPreviewOptions = &linkPreviewOptions{IsDisabled: true}
 	}
+	if o.ReplyToMessageID != 0 {
+		payload.ReplyParameters = &replyParameters{MessageID: o.ReplyToMessageID}
+	}
 
 	return payload, nil
 }
@@ -69,16 +104,47 @@ func (o *Options) validate() error {
 	if o.Text == "" {
 		validationErrors = append(validationErrors, "message is required")
 	}
-	// Telegram applies the limit after parsing entities. Without duplicating
-	// Telegram's parser, only plain-text length can be validated accurately.
-	textLen := utf8.RuneCountInString(o.Text)
-	if o.ParseMode == "" && textLen > MaxTextLength {
+	// Telegram applies the limit after parsing entities, so formatted text is
+	// parsed locally. Syntax errors then point at a line and column rather
+	// than at Telegram's byte offset.
+	if parsed, err := markup.Parse(o.Text, o.ParseMode); err != nil {
+		validationErrors = append(validationErrors, "cannot parse message: "+err.Error())
+	} else if textLen := parsed.Length(); textLen > MaxTextLength {
 		validationErrors = append(
 			validationErrors,
 			fmt.Sprintf("message is too long: must be <= %d characters, got %d", MaxTextLength, textLen),
 		)
 	}
 
+	if len(o.Entities) > 0 {
+		if o.ParseMode != "" {
+			validationErrors = append(validationErrors, "entities cannot be combined with a parse mode")
+		}
+		if err := entity.Validate(o.Text, o.Entities); err != nil {
+			validationErrors = append(validationErrors, err.Error())
+		}
+	}
+
+	if o.ReplyToMessageID < 0 {
+		validationErrors = append(validationErrors, "reply message ID must be positive")
+	}
+
+	if o.ReplyMarkup != nil {
+		for _, row := range o.ReplyMarkup.InlineKeyboard {
+			for _, button := range row {
+				if button.Text == "" {
+					validationErrors = append(validationErrors, "button text is required")
+				}
+				if len(button.CallbackData) == 0 || len(button.CallbackData) > MaxCallbackDataBytes {
+					validationErrors = append(
+						validationErrors,
+						fmt.Sprintf("button callback data must be 1-%d bytes", MaxCallbackDataBytes),
+					)
+				}
+			}
+		}
+	}
+
 	if len(validationErrors) > 0 {
 		return errors.New(strings.Join(validationErrors, "; "))
 	}
@@ -11,9 +11,16 @@ import (
 
 const telegramAPIEndpoint = "sendMessage"
 
+// Message is the subset of the sent Message returned by Telegram that callers
+// need to refer back to it.
+type Message struct {
+	MessageID int   `json:"message_id"`
+	Date      int64 `json:"date"`
+}
+
 // Sender sends text messages to a Telegram chat.
 type Sender interface {
-	Send(ctx context.Context, opts *Options) error
+	Send(ctx context.Context, opts *Options) (*Message, error)
 }
 
 type messageSender struct {
@@ -27,15 +34,16 @@ func New(httpClient httpclient.HTTPDoer) Sender {
 
 // Send validates opts and submits one sendMessage request.
 // See https://core.telegram.org/bots/api#sendmessage.
-func (s messageSender) Send(ctx context.Context, opts *Options) error {
+func (s messageSender) Send(ctx context.Context, opts *Options) (*Message, error) {
 	payload, err := opts.preparePayload()
 	if err != nil {
-		return fmt.Errorf("send: %w", err)
+		return nil, fmt.Errorf("send: %w", err)
 	}
 
-	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload); err != nil {
-		return fmt.Errorf("send: failed to send message: %w", err)
+	message := &Message{}
+	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, message); err != nil {
+		return nil, fmt.Errorf("send: failed to send message: %w", err)
 	}
 
-	return nil
+	return message, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/beeyev/telegram-owl/internal/telegram/common/entity"
 	"github.com/beeyev/telegram-owl/internal/telegram/method/sendmessage"
 	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
 )
@@ -36,6 +37,19 @@ func TestSend_ValidationErrors(t *testing.T) {
 			},
 			expectedErrors: []string{"message is too long"},
 		},
+		{
+			name: "entities with a parse mode or outside the text",
+			options: sendmessage.Options{
+				ChatID:    "123",
+				Text:      "hello",
+				ParseMode: "html",
+				Entities:  []entity.MessageEntity{{Type: "bold", Offset: 2, Length: 4}},
+			},
+			expectedErrors: []string{
+				"entities cannot be combined with a parse mode",
+				"entity 0 (bold): offset 2 and length 4 end after the text",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -43,7 +57,7 @@ func TestSend_ValidationErrors(t *testing.T) {
 			t.Parallel()
 
 			sender := sendmessage.New(testutils.NewMockHTTPDoer())
-			err := sender.Send(t.Context(), &tt.options)
+			_, err := sender.Send(t.Context(), &tt.options)
 			require.Error(t, err)
 			for _, expectedError := range tt.expectedErrors {
 				assert.Containsf(t, err.Error(), expectedError, "expected error not found")
@@ -56,6 +70,7 @@ func TestSend_Success(t *testing.T) {
 	t.Parallel()
 
 	mockHTTPClient := testutils.NewMockHTTPDoer()
+	mockHTTPClient.ResultJSON = map[string]string{"sendMessage": `{"message_id":42,"date":1700000000}`}
 	sender := sendmessage.New(mockHTTPClient)
 
 	options := &sendmessage.Options{
@@ -63,8 +78,9 @@ func TestSend_Success(t *testing.T) {
 		Text:   "Hello, world!",
 	}
 
-	err := sender.Send(t.Context(), options)
+	message, err := sender.Send(t.Context(), options)
 	require.NoError(t, err)
+	assert.Equal(t, &sendmessage.Message{MessageID: 42, Date: 1700000000}, message)
 
 	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
 	requestJSON, err := json.Marshal(mockHTTPClient.SubmitJSONResult[0].Body)
@@ -72,19 +88,129 @@ func TestSend_Success(t *testing.T) {
 	assert.JSONEq(t, `{"chat_id":"123","text":"Hello, world!"}`, string(requestJSON))
 }
 
-func TestSend_DelegatesFormattedLengthValidation(t *testing.T) {
+func TestSend_FormattedLengthValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{
+			name: "markup does not count towards the limit",
+			text: "<b>" + strings.Repeat("a", sendmessage.MaxTextLength) + "</b>",
+		},
+		{
+			name:    "parsed text over the limit",
+			text:    "<b>" + strings.Repeat("a", sendmessage.MaxTextLength+1) + "</b>",
+			wantErr: "validation failed: message is too long: must be <= 4096 characters, got 4097",
+		},
+		{
+			name:    "syntax error",
+			text:    "ok\nx < y",
+			wantErr: `validation failed: cannot parse message: line 2, column 3: unescaped "<", write &lt; to show it`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			sender := sendmessage.New(mockHTTPClient)
+
+			_, err := sender.Send(t.Context(), &sendmessage.Options{ChatID: "123", Text: tt.text, ParseMode: "html"})
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+
+				return
+			}
+			require.ErrorContains(t, err, tt.wantErr)
+			assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+		})
+	}
+}
+
+func TestSend_ReplyMarkup(t *testing.T) {
 	t.Parallel()
 
 	mockHTTPClient := testutils.NewMockHTTPDoer()
 	sender := sendmessage.New(mockHTTPClient)
 
-	options := &sendmessage.Options{
-		ChatID:    "123",
-		Text:      strings.Repeat("a", sendmessage.MaxTextLength+1),
-		ParseMode: "html",
-	}
+	_, err := sender.Send(t.Context(), &sendmessage.Options{
+		ChatID: "123",
+		Text:   "Deploy?",
+		ReplyMarkup: &sendmessage.InlineKeyboardMarkup{
+			InlineKeyboard: [][]sendmessage.InlineKeyboardButton{{
+				{Text: "Approve", CallbackData: "a:0"},
+				{Text: "Reject", CallbackData: "a:1"},
+			}},
+		},
+	})
+	require.NoError(t, err)
 
-	err := sender.Send(t.Context(), options)
+	requestJSON, err := json.Marshal(mockHTTPClient.SubmitJSONResult[0].Body)
 	require.NoError(t, err)
-	require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+	assert.JSONEq(t, `{
+		"chat_id":"123",
+		"text":"Deploy?",
+		"reply_markup":{"inline_keyboard":[[
+			{"text":"Approve","callback_data":"a:0"},
+			{"text":"Reject","callback_data":"a:1"}
+		]]}
+	}`, string(requestJSON))
+
+	_, err = sender.Send(t.Context(), &sendmessage.Options{
+		ChatID: "123",
+		Text:   "Deploy?",
+		ReplyMarkup: &sendmessage.InlineKeyboardMarkup{
+			InlineKeyboard: [][]sendmessage.InlineKeyboardButton{{
+				{Text: "", CallbackData: strings.Repeat("x", sendmessage.MaxCallbackDataBytes+1)},
+			}},
+		},
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "button text is required")
+	assert.ErrorContains(t, err, "button callback data must be 1-64 bytes")
+}
+
+func TestSend_ReplyToMessage(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendmessage.New(mockHTTPClient)
+
+	_, err := sender.Send(t.Context(), &sendmessage.Options{
+		ChatID:           "123",
+		Text:             "part 2",
+		ReplyToMessageID: 42,
+	})
+	require.NoError(t, err)
+
+	requestJSON, err := json.Marshal(mockHTTPClient.SubmitJSONResult[0].Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"chat_id":"123","text":"part 2","reply_parameters":{"message_id":42}}`, string(requestJSON))
+}
+
+func TestSend_Entities(t *testing.T) {
+	t.Parallel()
+
+	mockHTTPClient := testutils.NewMockHTTPDoer()
+	sender := sendmessage.New(mockHTTPClient)
+
+	_, err := sender.Send(t.Context(), &sendmessage.Options{
+		ChatID:   "123",
+		Text:     "Build *1* passed",
+		Entities: []entity.MessageEntity{{Type: "bold", Offset: 0, Length: 9}},
+	})
+	require.NoError(t, err)
+
+	requestJSON, err := json.Marshal(mockHTTPClient.SubmitJSONResult[0].Body)
+	require.NoError(t, err)
+	assert.JSONEq(
+		t,
+		`{"chat_id":"123","text":"Build *1* passed","entities":[{"type":"bold","offset":0,"length":9}]}`,
+		string(requestJSON),
+	)
 }
@@ -33,7 +33,7 @@ func (s messageSender) Send(ctx context.Context, opts *Options) error {
 		return fmt.Errorf("send: %w", err)
 	}
 
-	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload); err != nil {
+	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
 		return fmt.Errorf("send: failed to send rich message: %w", err)
 	}
 
@@ -0,0 +1,27 @@
+package setmessagereaction
+
+// allowedEmoji is the fixed reaction set Telegram accepts for
+// ReactionTypeEmoji, without variation selectors.
+// See https://core.telegram.org/bots/api#reactiontypeemoji.
+var allowedEmoji = map[string]bool{
+	"❤": true, "👍": true, "👎": true, "🔥": true, "🥰": true, "👏": true,
+	"😁": true, "🤔": true, "🤯": true, "😱": true, "🤬": true, "😢": true,
+	"🎉": true, "🤩": true, "🤮": true, "💩": true, "🙏": true, "👌": true,
+	"🕊": true, "🤡": true, "🥱": true, "🥴": true, "😍": true, "🐳": true,
+	"❤‍🔥": true, "🌚": true, "🌭": true, "💯": true, "🤣": true, "⚡": true,
+	"🍌": true, "🏆": true, "💔": true, "🤨": true, "😐": true, "🍓": true,
+	"🍾": true, "💋": true, "🖕": true, "😈": true, "😴": true, "😭": true,
+	"🤓": true, "👻": true, "👨‍💻": true, "👀": true, "🎃": true, "🙈": true,
+	"😇": true, "😨": true, "🤝": true, "✍": true, "🤗": true, "🫡": true,
+	"🎅": true, "🎄": true, "☃": true, "💅": true, "🤪": true, "🗿": true,
+	"🆒": true, "💘": true, "🙉": true, "🦄": true, "😘": true, "💊": true,
+	"🙊": true, "😎": true, "👾": true, "🤷‍♂": true, "🤷": true, "🤷‍♀": true,
+	"😡": true,
+}
+
+// IsAllowedEmoji reports whether emoji is in the fixed reaction set Telegram
+// accepts for ReactionTypeEmoji. Emoji variation selectors are ignored, so
+// both "❤" and "❤️" are accepted.
+func IsAllowedEmoji(emoji string) bool {
+	return allowedEmoji[normalizeEmoji(emoji)]
+}
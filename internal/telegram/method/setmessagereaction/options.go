@@ -0,0 +1,117 @@
+package setmessagereaction
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	reactionTypeEmoji       = "emoji"
+	reactionTypeCustomEmoji = "custom_emoji"
+
+	// variationSelector16 requests emoji presentation. Keyboards commonly add
+	// it (for example "❤️"), but Telegram's reaction set lists bare code points.
+	variationSelector16 = "\uFE0F"
+)
+
+// Options contains the setMessageReaction parameters supported by the CLI.
+// Each reaction is either an emoji from Telegram's allowed set or a numeric
+// custom emoji ID.
+type Options struct {
+	ChatID    string
+	MessageID int
+	Reactions []string
+	IsBig     bool
+}
+
+type payload struct {
+	ChatID    string         `json:"chat_id"`
+	MessageID int            `json:"message_id"`
+	Reaction  []reactionType `json:"reaction"`
+	IsBig     bool           `json:"is_big,omitempty"`
+}
+
+// reactionType is one ReactionTypeEmoji or ReactionTypeCustomEmoji entry.
+type reactionType struct {
+	Type          string `json:"type"`
+	Emoji         string `json:"emoji,omitempty"`
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
+}
+
+func (o *Options) preparePayload() (*payload, error) {
+	if err := o.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	reactions := make([]reactionType, 0, len(o.Reactions))
+	for _, reaction := range o.Reactions {
+		reactions = append(reactions, newReactionType(reaction))
+	}
+
+	return &payload{
+		ChatID:    o.ChatID,
+		MessageID: o.MessageID,
+		Reaction:  reactions,
+		IsBig:     o.IsBig,
+	}, nil
+}
+
+func (o *Options) validate() error {
+	var validationErrors []string
+
+	if o.ChatID == "" {
+		validationErrors = append(validationErrors, "chat ID is required")
+	}
+	if o.MessageID <= 0 {
+		validationErrors = append(validationErrors, "message ID must be a positive number")
+	}
+	if len(o.Reactions) == 0 {
+		validationErrors = append(validationErrors, "at least one reaction is required")
+	}
+	for _, reaction := range o.Reactions {
+		if isCustomEmojiID(reaction) {
+			continue
+		}
+		if !IsAllowedEmoji(reaction) {
+			validationErrors = append(
+				validationErrors,
+				fmt.Sprintf("reaction %q is not in Telegram's allowed emoji set", reaction),
+			)
+		}
+	}
+
+	if len(validationErrors) > 0 {
+		return errors.New(strings.Join(validationErrors, "; "))
+	}
+
+	return nil
+}
+
+func newReactionType(reaction string) reactionType {
+	if isCustomEmojiID(reaction) {
+		return reactionType{Type: reactionTypeCustomEmoji, CustomEmojiID: reaction}
+	}
+
+	return reactionType{Type: reactionTypeEmoji, Emoji: normalizeEmoji(reaction)}
+}
+
+// isCustomEmojiID reports whether reaction looks like a custom emoji
+// identifier. Telegram issues these as decimal strings.
+func isCustomEmojiID(reaction string) bool {
+	if reaction == "" {
+		return false
+	}
+
+	for _, r := range reaction {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func normalizeEmoji(emoji string) string {
+	return strings.ReplaceAll(strings.TrimSpace(emoji), variationSelector16, "")
+}
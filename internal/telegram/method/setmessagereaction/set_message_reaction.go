@@ -0,0 +1,42 @@
+// Package setmessagereaction validates and sends Telegram setMessageReaction
+// requests.
+package setmessagereaction
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
+)
+
+const telegramAPIEndpoint = "setMessageReaction"
+
+// Sender changes the reactions on an existing message.
+type Sender interface {
+	Send(ctx context.Context, opts *Options) error
+}
+
+type reactionSender struct {
+	httpClient httpclient.HTTPDoer
+}
+
+// New returns a reaction sender backed by httpClient.
+func New(httpClient httpclient.HTTPDoer) Sender {
+	return reactionSender{httpClient: httpClient}
+}
+
+// Send validates opts and submits one setMessageReaction request.
+// See https://core.telegram.org/bots/api#setmessagereaction.
+func (s reactionSender) Send(ctx context.Context, opts *Options) error {
+	payload, err := opts.preparePayload()
+	if err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	if err = s.httpClient.SubmitJSON(ctx, http.MethodPost, telegramAPIEndpoint, payload, nil); err != nil {
+		return fmt.Errorf("send: failed to set reaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package setmessagereaction_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/telegram/method/setmessagereaction"
+	"github.com/beeyev/telegram-owl/internal/telegram/testutils"
+)
+
+func TestSend_ValidationErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		options        setmessagereaction.Options
+		expectedErrors []string
+	}{
+		{
+			name:    "required fields",
+			options: setmessagereaction.Options{},
+			expectedErrors: []string{
+				"chat ID is required",
+				"message ID must be a positive number",
+				"at least one reaction is required",
+			},
+		},
+		{
+			name: "emoji outside the allowed set",
+			options: setmessagereaction.Options{
+				ChatID:    "123",
+				MessageID: 42,
+				Reactions: []string{"👍", "✅"},
+			},
+			expectedErrors: []string{`reaction "✅" is not in Telegram's allowed emoji set`},
+		},
+		{
+			name: "plain text is not a reaction",
+			options: setmessagereaction.Options{
+				ChatID:    "123",
+				MessageID: 42,
+				Reactions: []string{"thumbsup"},
+			},
+			expectedErrors: []string{`reaction "thumbsup" is not in Telegram's allowed emoji set`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			sender := setmessagereaction.New(mockHTTPClient)
+			err := sender.Send(t.Context(), &tt.options)
+			require.Error(t, err)
+			for _, expectedError := range tt.expectedErrors {
+				assert.ErrorContains(t, err, expectedError)
+			}
+			assert.Empty(t, mockHTTPClient.SubmitJSONResult)
+		})
+	}
+}
+
+func TestSend_Success(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		options         setmessagereaction.Options
+		expectedPayload string
+	}{
+		{
+			name: "single emoji",
+			options: setmessagereaction.Options{
+				ChatID:    "123",
+				MessageID: 42,
+				Reactions: []string{"👍"},
+			},
+			expectedPayload: `{"chat_id":"123","message_id":42,"reaction":[{"type":"emoji","emoji":"👍"}]}`,
+		},
+		{
+			name: "emoji presentation selector is removed",
+			options: setmessagereaction.Options{
+				ChatID:    "123",
+				MessageID: 42,
+				Reactions: []string{"❤️"},
+			},
+			expectedPayload: `{"chat_id":"123","message_id":42,"reaction":[{"type":"emoji","emoji":"❤"}]}`,
+		},
+		{
+			name: "custom emoji and big animation",
+			options: setmessagereaction.Options{
+				ChatID:    "@channel",
+				MessageID: 7,
+				Reactions: []string{"🔥", "5368324170671202286"},
+				IsBig:     true,
+			},
+			expectedPayload: `{
+				"chat_id":"@channel",
+				"message_id":7,
+				"reaction":[
+					{"type":"emoji","emoji":"🔥"},
+					{"type":"custom_emoji","custom_emoji_id":"5368324170671202286"}
+				],
+				"is_big":true
+			}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockHTTPClient := testutils.NewMockHTTPDoer()
+			sender := setmessagereaction.New(mockHTTPClient)
+
+			err := sender.Send(t.Context(), &tt.options)
+			require.NoError(t, err)
+
+			require.Len(t, mockHTTPClient.SubmitJSONResult, 1)
+			request := mockHTTPClient.SubmitJSONResult[0]
+			assert.Equal(t, http.MethodPost, request.Method)
+			assert.Equal(t, "setMessageReaction", request.Endpoint)
+
+			requestJSON, err := json.Marshal(request.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expectedPayload, string(requestJSON))
+		})
+	}
+}
+
+func TestIsAllowedEmoji(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		emoji string
+		want  bool
+	}{
+		{"👍", true},
+		{"👎", true},
+		{"❤", true},
+		{"❤️", true},
+		{"❤‍🔥", true},
+		{"👨‍💻", true},
+		{"✅", false},
+		{"❌", false},
+		{"", false},
+		{"👍👍", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.emoji, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, setmessagereaction.IsAllowedEmoji(tt.emoji))
+		})
+	}
+}
@@ -2,14 +2,18 @@ package testutils
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/beeyev/telegram-owl/internal/telegram/httpclient"
 )
 
-// MockHTTPDoer records HTTP requests made by tests.
+// MockHTTPDoer records HTTP requests made by tests. ResultJSON maps an endpoint
+// to the raw Telegram "result" value decoded for callers that request one.
+// Endpoints without an entry leave the result at its zero value.
 type MockHTTPDoer struct {
 	SubmitMultipartResult []submitMultipartPayload
 	SubmitJSONResult      []submitJSONPayload
+	ResultJSON            map[string]string
 }
 
 type submitMultipartPayload struct {
@@ -35,6 +39,7 @@ func (c *MockHTTPDoer) SubmitMultipart(
 	endpoint string,
 	fields map[string]string,
 	files []httpclient.MultipartFile,
+	result any,
 ) error {
 	c.SubmitMultipartResult = append(c.SubmitMultipartResult, submitMultipartPayload{
 		Method:   method,
@@ -43,15 +48,28 @@ func (c *MockHTTPDoer) SubmitMultipart(
 		Files:    files,
 	})
 
-	return nil
+	return c.decodeResult(endpoint, result)
 }
 
-func (c *MockHTTPDoer) SubmitJSON(_ context.Context, method, endpoint string, body any) error {
+func (c *MockHTTPDoer) SubmitJSON(_ context.Context, method, endpoint string, body, result any) error {
 	c.SubmitJSONResult = append(c.SubmitJSONResult, submitJSONPayload{
 		Method:   method,
 		Endpoint: endpoint,
 		Body:     body,
 	})
 
-	return nil
+	return c.decodeResult(endpoint, result)
+}
+
+func (c *MockHTTPDoer) decodeResult(endpoint string, result any) error {
+	if result == nil {
+		return nil
+	}
+
+	raw, ok := c.ResultJSON[endpoint]
+	if !ok {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(raw), result)
 }
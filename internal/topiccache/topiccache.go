@@ -0,0 +1,93 @@
+// Package topiccache remembers forum topic IDs by name between runs, so a
+// topic named on the command line resolves without an API call after the
+// first lookup.
+package topiccache
+
+import (
+	"path/filepath"
+
+	"github.com/beeyev/telegram-owl/internal/jsonstore"
+)
+
+// FileName is the cache file created inside the cache directory.
+const FileName = "topics.json"
+
+// Key scopes cached names to one bot and one chat reference. Topic IDs are
+// per chat, and different bots may see different topics.
+type Key struct {
+	BotID  string
+	ChatID string
+}
+
+// Store reads and writes the cache file on every call. The file is small and
+// each CLI run touches it at most a few times, so nothing is kept in memory.
+type Store struct {
+	file *jsonstore.File
+}
+
+// entries maps bot ID, then chat ID, then topic name to a thread ID.
+type entries map[string]map[string]map[string]int
+
+// New returns a store backed by FileName inside dir. The directory is created
+// on the first write.
+func New(dir string) *Store {
+	return &Store{file: jsonstore.New(filepath.Join(dir, FileName), "topic cache")}
+}
+
+// Lookup returns the thread ID cached for name.
+func (s *Store) Lookup(key Key, name string) (int, bool, error) {
+	data := make(entries)
+	if err := s.file.Load(&data); err != nil {
+		return 0, false, err
+	}
+
+	threadID, ok := data[key.BotID][key.ChatID][name]
+
+	return threadID, ok, nil
+}
+
+// Save records name for threadID. Names previously cached for the same thread
+// are dropped, which keeps the cache correct after a rename.
+func (s *Store) Save(key Key, name string, threadID int) error {
+	data := make(entries)
+
+	return s.file.Update(&data, func() error {
+		topics := data.topics(key)
+		for cachedName, cachedID := range topics {
+			if cachedID == threadID {
+				delete(topics, cachedName)
+			}
+		}
+		topics[name] = threadID
+
+		return nil
+	})
+}
+
+// Forget drops every name cached for threadID, for example after the topic
+// was deleted.
+func (s *Store) Forget(key Key, threadID int) error {
+	data := make(entries)
+
+	return s.file.Update(&data, func() error {
+		topics := data.topics(key)
+		for cachedName, cachedID := range topics {
+			if cachedID == threadID {
+				delete(topics, cachedName)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (e entries) topics(key Key) map[string]int {
+	if e[key.BotID] == nil {
+		e[key.BotID] = make(map[string]map[string]int)
+	}
+	if e[key.BotID][key.ChatID] == nil {
+		e[key.BotID][key.ChatID] = make(map[string]int)
+	}
+
+	return e[key.BotID][key.ChatID]
+}
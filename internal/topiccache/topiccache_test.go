@@ -0,0 +1,62 @@
+package topiccache_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/topiccache"
+)
+
+func TestStore_SaveLookupForget(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "nested")
+	store := topiccache.New(dir)
+	key := topiccache.Key{BotID: "123", ChatID: "-1002"}
+
+	_, found, err := store.Lookup(key, "Deployments")
+	require.NoError(t, err)
+	assert.False(t, found, "missing cache file means an empty cache")
+
+	require.NoError(t, store.Save(key, "Deployments", 11))
+	require.NoError(t, store.Save(key, "Alerts", 15))
+	require.NoError(t, store.Save(topiccache.Key{BotID: "456", ChatID: "-1002"}, "Deployments", 99))
+
+	threadID, found, err := store.Lookup(key, "Deployments")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 11, threadID)
+
+	// Renaming a topic replaces its old name.
+	require.NoError(t, store.Save(key, "Releases", 11))
+	_, found, err = store.Lookup(key, "Deployments")
+	require.NoError(t, err)
+	assert.False(t, found)
+	threadID, _, err = store.Lookup(key, "Releases")
+	require.NoError(t, err)
+	assert.Equal(t, 11, threadID)
+
+	require.NoError(t, store.Forget(key, 11))
+	_, found, err = store.Lookup(key, "Releases")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	threadID, found, err = store.Lookup(key, "Alerts")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 15, threadID)
+}
+
+func TestStore_CorruptFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, topiccache.FileName), []byte("{"), 0o600))
+
+	_, _, err := topiccache.New(dir).Lookup(topiccache.Key{BotID: "1", ChatID: "2"}, "x")
+	require.ErrorContains(t, err, "delete the file to reset it")
+}
@@ -0,0 +1,239 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+type albumMedia struct {
+	Type    string `json:"type"`
+	Media   string `json:"media"`
+	Caption string `json:"caption"`
+	// FileName is the name the file referenced by Media was uploaded under.
+	FileName string `json:"-"`
+}
+
+// createScreenshots writes count small PNG files and returns their paths in
+// order.
+func createScreenshots(t *testing.T, count int) []string {
+	t.Helper()
+
+	dir := t.TempDir()
+	paths := make([]string, 0, count)
+	for i := range count {
+		path := filepath.Join(dir, fmt.Sprintf("shot%02d.png", i+1))
+		require.NoError(t, os.WriteFile(path, []byte("png"), 0o600))
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+// runAlbums sends the screenshots and returns the media of every
+// sendMediaGroup request. failAt makes that request, counted from 1, fail.
+func runAlbums(t *testing.T, failAt int, args ...string) ([][]albumMedia, error) {
+	t.Helper()
+
+	var albums [][]albumMedia
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(32<<20)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var media []albumMedia
+		assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+		for i := range media {
+			if files := r.MultipartForm.File[strings.TrimPrefix(media[i].Media, "attach://")]; len(files) > 0 {
+				media[i].FileName = files[0].Filename
+			}
+		}
+		albums = append(albums, media)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(albums) == failAt {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: upload failed"}`))
+
+			return
+		}
+		_, _ = w.Write(okResponse(r))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	err := app.Run(t.Context(), getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, args...)))
+
+	return albums, err
+}
+
+func TestSendAlbums_MoreThanTenAttachments(t *testing.T) {
+	t.Parallel()
+
+	paths := createScreenshots(t, 12)
+	args := []string{"-m", "Nightly run: 12 screenshots"}
+	for _, path := range paths {
+		args = append(args, "--attach="+path)
+	}
+
+	albums, err := runAlbums(t, 0, args...)
+	require.NoError(t, err)
+
+	require.Len(t, albums, 2)
+	assert.Len(t, albums[0], 6)
+	assert.Len(t, albums[1], 6)
+	for _, media := range albums[0] {
+		assert.Equal(t, "photo", media.Type)
+		assert.Empty(t, media.Caption)
+	}
+	assert.Equal(t, "Nightly run: 12 screenshots", albums[1][5].Caption)
+}
+
+func TestSendAlbums_Counter(t *testing.T) {
+	t.Parallel()
+
+	paths := createScreenshots(t, 21)
+	args := []string{"--album-counter", "--format=markdown", "-m", "*Nightly run*"}
+	for _, path := range paths {
+		args = append(args, "--attach="+path)
+	}
+
+	albums, err := runAlbums(t, 0, args...)
+	require.NoError(t, err)
+
+	require.Len(t, albums, 3)
+	captions := make([]string, 0, len(albums))
+	for _, album := range albums {
+		assert.Len(t, album, 7)
+		captions = append(captions, album[len(album)-1].Caption)
+	}
+	assert.Equal(t, []string{"Part 1/3", "Part 2/3", "*Nightly run*\n\nPart 3/3"}, captions)
+}
+
+func TestSendAlbums_CounterReservesOnlyWhatItNeeds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		screenshots  int
+		captionChars int
+		wantCaptions []string
+	}{
+		{
+			name:         "single album has no counter",
+			screenshots:  1,
+			captionChars: 1024,
+			wantCaptions: []string{strings.Repeat("a", 1024)},
+		},
+		{
+			name:         "two albums reserve the length of Part 2/2",
+			screenshots:  12,
+			captionChars: 1024 - len("\n\nPart 2/2"),
+			wantCaptions: []string{"Part 1/2", strings.Repeat("a", 1014) + "\n\nPart 2/2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := []string{"--album-counter", "-m", strings.Repeat("a", tt.captionChars)}
+			for _, path := range createScreenshots(t, tt.screenshots) {
+				args = append(args, "--attach="+path)
+			}
+
+			albums, err := runAlbums(t, 0, args...)
+			require.NoError(t, err)
+
+			captions := make([]string, 0, len(albums))
+			for _, album := range albums {
+				captions = append(captions, album[len(album)-1].Caption)
+			}
+			assert.Equal(t, tt.wantCaptions, captions)
+		})
+	}
+}
+
+func TestSendAlbums_ReportsPartialDelivery(t *testing.T) {
+	t.Parallel()
+
+	paths := createScreenshots(t, 25)
+	args := make([]string, 0, len(paths))
+	for _, path := range paths {
+		args = append(args, "--attach="+path)
+	}
+
+	albums, err := runAlbums(t, 2, args...)
+	require.Error(t, err)
+	assert.Len(t, albums, 2, "albums after the failed one must not be sent")
+	assert.ErrorContains(t, err, "albums 1-1 of 3 sent, but album 2 failed: send attachments:")
+	assert.ErrorContains(t, err, "upload failed")
+}
+
+func TestSendAlbums_CounterRequiresAttach(t *testing.T) {
+	t.Parallel()
+
+	_, err := runAlbums(t, 0, "--album-counter", "-m", "hello")
+	require.EqualError(t, err, "--album-counter requires --attach")
+}
+
+func TestSendAlbums_MixedTypes(t *testing.T) {
+	t.Parallel()
+
+	shots := createScreenshots(t, 2)
+	report := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(report, []byte("%PDF"), 0o600))
+	attach := []string{"--attach=" + shots[0], "--attach=" + report, "--attach=" + shots[1], "-m", "Test report"}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantTypes [][]string
+	}{
+		{
+			name:      "grouped by type by default",
+			wantTypes: [][]string{{"photo", "photo"}, {"document"}},
+		},
+		{
+			name:      "one album of documents without grouping",
+			args:      []string{"--group-by-type=false"},
+			wantTypes: [][]string{{"document", "document", "document"}},
+		},
+		{
+			name:      "as document",
+			args:      []string{"--as-document"},
+			wantTypes: [][]string{{"document", "document", "document"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			albums, err := runAlbums(t, 0, append(tt.args, attach...)...)
+			require.NoError(t, err)
+
+			gotTypes := make([][]string, 0, len(albums))
+			for _, album := range albums {
+				types := make([]string, 0, len(album))
+				for _, media := range album {
+					types = append(types, media.Type)
+				}
+				gotTypes = append(gotTypes, types)
+			}
+			assert.Equal(t, tt.wantTypes, gotTypes)
+
+			lastAlbum := albums[len(albums)-1]
+			assert.Equal(t, "Test report", lastAlbum[len(lastAlbum)-1].Caption)
+			assert.Empty(t, albums[0][0].Caption)
+		})
+	}
+}
@@ -26,6 +26,16 @@ func setupMockServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server,
 	return mockServer, outputBuf
 }
 
+// okResponse is Telegram's successful response to r, with an empty result of
+// the shape the method returns.
+func okResponse(r *http.Request) []byte {
+	if strings.HasSuffix(r.URL.Path, "/sendMediaGroup") {
+		return []byte(`{"ok":true,"result":[]}`)
+	}
+
+	return []byte(`{"ok":true,"result":{}}`)
+}
+
 func TestNoFlags(t *testing.T) {
 	t.Parallel()
 
@@ -86,7 +96,7 @@ func TestSendMessage_FromStdin(t *testing.T) { //nolint:paralleltest // Reassign
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"ok": true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	args := getTestArgs([]string{"--token=123:abc", "--chat=75757", "--stdin"})
@@ -124,7 +134,7 @@ func TestSendRichMessage_FromStdin(t *testing.T) { //nolint:paralleltest // Reas
 		capturedPath = r.URL.Path
 
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"ok":true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	stdinReader, stdinWriter, err := os.Pipe()
@@ -173,7 +183,7 @@ func TestSendAttachment_WithStdinFromCharacterDevice(
 		assert.NoError(t, err)
 
 		w.Header().Set("Content-Type", "application/json")
-		_, err = w.Write([]byte(`{"ok": true}`))
+		_, err = w.Write(okResponse(r))
 		assert.NoError(t, err)
 	})
 
@@ -292,7 +302,7 @@ func TestSendMessage_Success(t *testing.T) {
 
 				w.Header().Set("Content-Type", "application/json")
 				w.WriteHeader(http.StatusOK)
-				_, _ = w.Write([]byte(`{"ok": true}`))
+				_, _ = w.Write(okResponse(r))
 			})
 
 			ctx := t.Context()
@@ -372,7 +382,7 @@ func TestSendRichMessage_Success(t *testing.T) {
 				capturedPath = r.URL.Path
 
 				w.Header().Set("Content-Type", "application/json")
-				_, _ = w.Write([]byte(`{"ok":true}`))
+				_, _ = w.Write(okResponse(r))
 			})
 
 			app := cli.NewApp(mockServer.URL)
@@ -412,7 +422,7 @@ func TestSendMediaGroup_Success(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"ok": true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	app := cli.NewApp(mockServer.URL)
@@ -484,7 +494,7 @@ func TestSendMediaGroup_LongMessageSendsTextSeparately(t *testing.T) {
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"ok": true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	app := cli.NewApp(mockServer.URL)
@@ -583,7 +593,7 @@ func testRichAttachmentRouting(t *testing.T, format, message, expectedRichMessag
 		captured = append(captured, request)
 
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = w.Write([]byte(`{"ok":true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	attachmentFile, err := os.CreateTemp(t.TempDir(), "report.txt")
@@ -640,7 +650,7 @@ func TestSendMediaGroup_RichMessageReportsPartialDelivery(t *testing.T) {
 			return
 		}
 
-		_, _ = w.Write([]byte(`{"ok":true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	attachmentFile, err := os.CreateTemp(t.TempDir(), "report.txt")
@@ -700,7 +710,7 @@ func TestSendMediaGroup_RichMessageStopsAfterAttachmentFailure(t *testing.T) {
 	assert.Empty(t, outputBuf.String())
 }
 
-func TestSendMediaGroup_FormattedCaptionDelegatesLengthValidation(t *testing.T) {
+func TestSendMediaGroup_FormattedCaptionMeasuredAfterParsing(t *testing.T) {
 	t.Parallel()
 
 	var capturedPath string
@@ -716,7 +726,7 @@ func TestSendMediaGroup_FormattedCaptionDelegatesLengthValidation(t *testing.T)
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"ok": true}`))
+		_, _ = w.Write(okResponse(r))
 	})
 
 	app := cli.NewApp(mockServer.URL)
@@ -746,6 +756,156 @@ func TestSendMediaGroup_FormattedCaptionDelegatesLengthValidation(t *testing.T)
 	assert.Empty(t, outputBuf.String())
 }
 
+func TestSendMediaGroup_LongFormattedCaptionSendsTextSeparately(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		format          string
+		message         string
+		wantMessageJSON string
+	}{
+		{
+			name:    "html",
+			format:  "html",
+			message: "<b>" + strings.Repeat("a", 1025) + "</b>",
+			wantMessageJSON: `{"chat_id":"75757","text":"<b>` + strings.Repeat("a", 1025) +
+				`</b>","parse_mode":"html"}`,
+		},
+		{
+			name:    "markdown",
+			format:  "markdown",
+			message: "*" + strings.Repeat("a", 1024) + `\.*`,
+			wantMessageJSON: `{"chat_id":"75757","text":"*` + strings.Repeat("a", 1024) +
+				`\\.*","parse_mode":"MarkdownV2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var paths, bodies []string
+			mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				if strings.Contains(r.Header.Get("Content-Type"), "multipart/form-data") {
+					if !assert.NoError(t, r.ParseMultipartForm(32<<20)) {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					bodies = append(bodies, r.FormValue("media"))
+				} else {
+					bodyBytes, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					bodies = append(bodies, strings.TrimSpace(string(bodyBytes)))
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(okResponse(r))
+			})
+
+			app := cli.NewApp(mockServer.URL)
+			app.Writer = outputBuf
+
+			photoFile, err := os.CreateTemp(t.TempDir(), "photo.jpg")
+			require.NoError(t, err)
+			defer photoFile.Close()
+
+			err = app.Run(t.Context(), getTestArgs([]string{
+				"--token=123:abc",
+				"--chat=75757",
+				"--attach=" + photoFile.Name(),
+				"--message=" + tt.message,
+				"--format=" + tt.format,
+				"--as-document=true",
+			}))
+			require.NoError(t, err)
+
+			require.Equal(t, []string{"/bot123:abc/sendMediaGroup", "/bot123:abc/sendMessage"}, paths)
+			assert.JSONEq(t, `[{"type":"document","media":"attach://file0"}]`, bodies[0])
+			assert.JSONEq(t, tt.wantMessageJSON, bodies[1])
+			assert.Empty(t, outputBuf.String())
+		})
+	}
+}
+
+func TestSendMediaGroup_CaptionLimitCountsUTF16(t *testing.T) {
+	t.Parallel()
+
+	// Each emoji is two UTF-16 code units, so 512 of them fill the caption.
+	tests := []struct {
+		name      string
+		message   string
+		wantPaths []string
+	}{
+		{
+			name:      "at the limit",
+			message:   strings.Repeat("😀", 512),
+			wantPaths: []string{"/bot123:abc/sendMediaGroup"},
+		},
+		{
+			name:      "over the limit",
+			message:   strings.Repeat("😀", 512) + "a",
+			wantPaths: []string{"/bot123:abc/sendMediaGroup", "/bot123:abc/sendMessage"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var paths []string
+			mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				_, _ = io.Copy(io.Discard, r.Body)
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write(okResponse(r))
+			})
+
+			photoFile, err := os.CreateTemp(t.TempDir(), "photo.jpg")
+			require.NoError(t, err)
+			defer photoFile.Close()
+
+			err = cli.NewApp(mockServer.URL).Run(t.Context(), getTestArgs([]string{
+				"--token=123:abc",
+				"--chat=75757",
+				"--attach=" + photoFile.Name(),
+				"--message=" + tt.message,
+				"--as-document=true",
+			}))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPaths, paths)
+		})
+	}
+}
+
+func TestSendMediaGroup_CaptionSyntaxErrorStopsBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL.Path)
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	photoFile, err := os.CreateTemp(t.TempDir(), "photo.jpg")
+	require.NoError(t, err)
+	defer photoFile.Close()
+
+	err = app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=75757",
+		"--attach=" + photoFile.Name(),
+		"--message=<b>Build " + strings.Repeat("a", 1100) + "</i>",
+		"--format=html",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot parse caption: line 1, column 1110: end tag </i> does not match <b>")
+	assert.Empty(t, outputBuf.String())
+}
+
 func Test_ErrorResponse(t *testing.T) {
 	t.Parallel()
 
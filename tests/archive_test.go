@@ -0,0 +1,129 @@
+package tests_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestSendArchive(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"artifacts/build.log":        strings.Repeat("compiling\n", 200),
+		"artifacts/report/index.txt": "ok",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	var media []albumMedia
+	var fileName string
+	var uploaded []byte
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(32<<20)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+		if !assert.Len(t, media, 1) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if files := r.MultipartForm.File[strings.TrimPrefix(media[0].Media, "attach://")]; assert.Len(t, files, 1) {
+			fileName = files[0].Filename
+			file, err := files[0].Open()
+			assert.NoError(t, err)
+			uploaded, err = io.ReadAll(file)
+			assert.NoError(t, err)
+			assert.NoError(t, file.Close())
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(okResponse(r))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=75757",
+		"--attach=" + filepath.Join(dir, "artifacts"),
+		"--recursive",
+		"--archive=zip",
+		"--verbose",
+	}))
+	require.NoError(t, err)
+
+	require.Len(t, media, 1)
+	assert.Equal(t, "document", media[0].Type)
+	assert.Equal(t, "artifacts.zip", fileName)
+
+	archive, err := zip.NewReader(bytes.NewReader(uploaded), int64(len(uploaded)))
+	require.NoError(t, err)
+	names := make([]string, 0, len(archive.File))
+	for _, file := range archive.File {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"artifacts/build.log", "artifacts/report/index.txt"}, names)
+
+	assert.Contains(t, outputBuf.String(), "attachments=1")
+	assert.Contains(t, outputBuf.String(), "Archive artifacts.zip: 2 file(s), 2002 bytes compressed to ")
+}
+
+func TestSendArchive_Errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "run.log"), []byte("x"), 0o600))
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "unknown format",
+			args:    []string{"--attach=" + dir, "--archive=rar"},
+			wantErr: "incorrect value for --archive flag, possible values: zip, tar.gz",
+		},
+		{
+			name:    "name without archive",
+			args:    []string{"--attach=" + dir, "--archive-name=logs"},
+			wantErr: "--archive-name requires --archive",
+		},
+		{
+			name:    "archive without attach",
+			args:    []string{"--archive=zip", "-m", "hi"},
+			wantErr: "--archive requires --attach",
+		},
+		{
+			name:    "pattern matches nothing",
+			args:    []string{"--attach=" + filepath.Join(dir, "*.png"), "--archive=tar.gz"},
+			wantErr: "resolve --attach: \"" + filepath.Join(dir, "*.png") + "\" matches no files",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			albums, err := runAlbums(t, 0, tt.args...)
+			require.EqualError(t, err, tt.wantErr)
+			assert.Empty(t, albums)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+// fakeAskAPI emulates the Bot API for ask. getUpdates returns one batch per
+// call from batches, built from the callback data of the sent question.
+type fakeAskAPI struct {
+	mu            sync.Mutex
+	callbackData  []string
+	batches       []func(callbackData []string) string
+	updateBodies  []string
+	answerBodies  []string
+	editBody      string
+	questionBody  string
+	getUpdatesGap time.Duration
+}
+
+func (f *fakeAskAPI) handler(t *testing.T) http.HandlerFunc {
+	t.Helper()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body := strings.TrimSpace(string(bodyBytes))
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch path.Base(r.URL.Path) {
+		case "sendMessage":
+			f.questionBody = body
+			var payload struct {
+				ReplyMarkup struct {
+					InlineKeyboard [][]struct {
+						CallbackData string `json:"callback_data"`
+					} `json:"inline_keyboard"`
+				} `json:"reply_markup"`
+			}
+			assert.NoError(t, json.Unmarshal(bodyBytes, &payload))
+			for _, row := range payload.ReplyMarkup.InlineKeyboard {
+				for _, button := range row {
+					f.callbackData = append(f.callbackData, button.CallbackData)
+				}
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":42,"date":1700000000}}`))
+		case "getUpdates":
+			f.updateBodies = append(f.updateBodies, body)
+			result := "[]"
+			if len(f.batches) > 0 {
+				result = f.batches[0](f.callbackData)
+				f.batches = f.batches[1:]
+			}
+			time.Sleep(f.getUpdatesGap)
+			_, _ = w.Write([]byte(`{"ok":true,"result":` + result + `}`))
+		case "answerCallbackQuery":
+			f.answerBodies = append(f.answerBodies, body)
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		case "editMessageText":
+			f.editBody = body
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}
+}
+
+func callbackUpdate(updateID int, queryID string, userID int64, data string) string {
+	return fmt.Sprintf(
+		`{"update_id":%d,"callback_query":{"id":%q,"from":{"id":%d,"first_name":"Ada","username":"ada"},"data":%q}}`,
+		updateID, queryID, userID, data,
+	)
+}
+
+func askArgs(extra ...string) []string {
+	return append([]string{
+		"ask", "--token=123:abc", "--chat=-1002", "--allow-user=12345", "-m", "Deploy?",
+		"--option=Approve", "--option=Reject",
+	}, extra...)
+}
+
+func TestAsk_ChosenOptionSetsExitCode(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAskAPI{
+		batches: []func([]string) string{
+			func([]string) string { return `[]` },
+			func(data []string) string {
+				return "[" + strings.Join([]string{
+					`{"update_id":100,"message":{"message_id":7,"chat":{"id":-1002,"type":"supergroup"},"text":"hi"}}`,
+					callbackUpdate(101, "foreign", 12345, "other-bot:1"),
+					callbackUpdate(102, "stale", 12345, "owl:0011223344556677:0"),
+					callbackUpdate(103, "intruder", 999, data[0]),
+					callbackUpdate(104, "decision", 12345, data[1]),
+				}, ",") + "]"
+			},
+		},
+	}
+	mockServer, outputBuf := setupMockServer(t, fake.handler(t))
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs(askArgs("--thread=11")))
+	require.Error(t, err)
+	assert.Equal(t, 11, cli.ExitCode(err), "option codes stay clear of the usage error code 1")
+	assert.EqualError(t, err, `"Reject" was chosen by Ada (@ada) [ID 12345]`)
+	assert.Equal(t, "Reject\n", outputBuf.String())
+
+	require.Len(t, fake.callbackData, 2)
+	assert.Regexp(t, `^owl:[0-9a-f]{16}:0$`, fake.callbackData[0])
+	assert.JSONEq(t, fmt.Sprintf(`{
+		"chat_id":"-1002",
+		"message_thread_id":"11",
+		"text":"Deploy?",
+		"reply_markup":{"inline_keyboard":[[
+			{"text":"Approve","callback_data":%q},
+			{"text":"Reject","callback_data":%q}
+		]]}
+	}`, fake.callbackData[0], fake.callbackData[1]), fake.questionBody)
+
+	// The message at the front stays pending for chats and topics, so no
+	// update is confirmed.
+	assert.Equal(t, []string{`{"timeout":25}`, `{"timeout":25}`}, fake.updateBodies)
+
+	require.Len(t, fake.answerBodies, 3, "the foreign button must not be answered")
+	assert.JSONEq(t, `{"callback_query_id":"stale","text":"This question is no longer active."}`, fake.answerBodies[0])
+	assert.JSONEq(t, `{
+		"callback_query_id":"intruder",
+		"text":"You are not allowed to answer this question.",
+		"show_alert":true
+	}`, fake.answerBodies[1])
+	assert.JSONEq(t, `{"callback_query_id":"decision","text":"You chose: Reject"}`, fake.answerBodies[2])
+
+	var edit struct {
+		ChatID    string `json:"chat_id"`
+		MessageID int    `json:"message_id"`
+		Text      string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(fake.editBody), &edit))
+	assert.Equal(t, "-1002", edit.ChatID)
+	assert.Equal(t, 42, edit.MessageID)
+	assert.Regexp(
+		t,
+		`^Deploy\?\n\nReject by Ada \(@ada\) \[ID 12345\] at \d{4}-\d{2}-\d{2} \d{2}:\d{2} UTC$`,
+		edit.Text,
+	)
+}
+
+func TestAsk_FirstOptionSucceeds(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAskAPI{
+		batches: []func([]string) string{
+			func(data []string) string { return "[" + callbackUpdate(7, "decision", 12345, data[0]) + "]" },
+		},
+	}
+	mockServer, outputBuf := setupMockServer(t, fake.handler(t))
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs(askArgs()))
+	require.NoError(t, err)
+	assert.Equal(t, 0, cli.ExitCode(err))
+	assert.Equal(t, "Approve\n", outputBuf.String())
+	assert.Equal(t, []string{`{"timeout":25}`, `{"offset":8,"limit":1}`}, fake.updateBodies)
+}
+
+func TestAsk_FullPageOfForeignUpdates(t *testing.T) {
+	t.Parallel()
+
+	foreign := func([]string) string {
+		updates := make([]string, 100)
+		for i := range updates {
+			updates[i] = fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"chat":{"id":-1002}}}`, i+1, i+1)
+		}
+
+		return "[" + strings.Join(updates, ",") + "]"
+	}
+	fake := &fakeAskAPI{batches: []func([]string) string{foreign}}
+	mockServer, outputBuf := setupMockServer(t, fake.handler(t))
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs(askArgs("--timeout=1m")))
+	require.ErrorContains(t, err, "100 or more pending updates for other commands come before the answer")
+	assert.Equal(t, 125, cli.ExitCode(err))
+	assert.Equal(t, []string{`{"timeout":25}`}, fake.updateBodies, "nothing is confirmed")
+}
+
+func TestAsk_Timeout(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeAskAPI{getUpdatesGap: 10 * time.Millisecond}
+	mockServer, outputBuf := setupMockServer(t, fake.handler(t))
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs(askArgs("--timeout=100ms")))
+	require.EqualError(t, err, "no answer within 100ms")
+	assert.Equal(t, 124, cli.ExitCode(err))
+	assert.Empty(t, outputBuf.String())
+	assert.JSONEq(t, `{"chat_id":"-1002","message_id":42,"text":"Deploy?\n\nNo answer within 100ms."}`, fake.editBody)
+}
+
+func TestAsk_ErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "no allowlist",
+			args: []string{"ask", "--token=x", "--chat=y", "-m", "Deploy?", "--option=Yes"},
+			want: "missing required flag: --allow-user",
+		},
+		{
+			name: "no options",
+			args: []string{"ask", "--token=x", "--chat=y", "-m", "Deploy?", "--allow-user=1"},
+			want: "between 1 and 10 --option values are required, got 0",
+		},
+		{
+			name: "too many options",
+			args: []string{
+				"ask", "--token=x", "--chat=y", "-m", "Deploy?", "--allow-user=1", "--option=1,2,3,4,5,6,7,8,9,10,11",
+			},
+			want: "between 1 and 10 --option values are required, got 11",
+		},
+		{
+			name: "no message",
+			args: []string{"ask", "--token=x", "--chat=y", "--option=Yes", "--allow-user=1"},
+			want: "missing required flag: --message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := cli.NewApp("dummy")
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.ErrorContains(t, err, tt.want)
+			assert.Equal(t, 125, cli.ExitCode(err))
+		})
+	}
+}
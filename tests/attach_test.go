@@ -0,0 +1,193 @@
+package tests_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendAttachments_PatternsAndDirectories(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"reports/login.png":          "1",
+		"reports/nightly/search.png": "22",
+		"reports/nightly/debug.png":  "333",
+		"reports/nightly/run.log":    "4",
+		"logs/b.txt":                 "5",
+		"logs/a.txt":                 "66",
+		"logs/old/c.txt":             "7",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	tests := []struct {
+		name      string
+		args      []string
+		wantFiles [][]string
+	}{
+		{
+			name:      "double star glob",
+			args:      []string{"--attach=" + filepath.Join(dir, "reports", "**", "*.png")},
+			wantFiles: [][]string{{"login.png", "debug.png", "search.png"}},
+		},
+		{
+			name:      "directory",
+			args:      []string{"--attach=" + filepath.Join(dir, "logs")},
+			wantFiles: [][]string{{"a.txt", "b.txt"}},
+		},
+		{
+			name: "recursive directory with filters and sort",
+			args: []string{
+				"--attach=" + filepath.Join(dir, "reports"), "-r",
+				"--exclude=debug.*", "--include=*.png", "--sort=size",
+			},
+			wantFiles: [][]string{{"login.png", "search.png"}},
+		},
+		{
+			name: "mixed classes after expansion",
+			args: []string{
+				"--attach=" + filepath.Join(dir, "logs"), "--attach=" + filepath.Join(dir, "reports", "*.png"),
+			},
+			wantFiles: [][]string{{"login.png"}, {"a.txt", "b.txt"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			albums, err := runAlbums(t, 0, tt.args...)
+			require.NoError(t, err)
+
+			gotFiles := make([][]string, 0, len(albums))
+			for _, album := range albums {
+				names := make([]string, 0, len(album))
+				for _, media := range album {
+					names = append(names, media.FileName)
+				}
+				gotFiles = append(gotFiles, names)
+			}
+			assert.Equal(t, tt.wantFiles, gotFiles)
+		})
+	}
+}
+
+func TestSendAttachments_PatternErrors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "run.log"), []byte("x"), 0o600))
+	pattern := filepath.Join(dir, "*.png")
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "pattern matches nothing",
+			args:    []string{"--attach=" + pattern, "-m", "hi"},
+			wantErr: "resolve --attach: \"" + pattern + "\" matches no files",
+		},
+		{
+			name: "filters leave nothing",
+			args: []string{"--attach=" + dir, "--exclude=*.log"},
+			wantErr: "resolve --attach: \"" + dir + "\" matches 1 file(s), " +
+				"but none pass the include and exclude filters",
+		},
+		{
+			name:    "filter without attach",
+			args:    []string{"--include=*.png", "-m", "hi"},
+			wantErr: "--include requires --attach",
+		},
+		{
+			name:    "unknown sort",
+			args:    []string{"--attach=" + dir, "--sort=date"},
+			wantErr: "incorrect value for --sort flag, possible values: name, mtime, size",
+		},
+		{
+			name:    "unknown detect",
+			args:    []string{"--attach=" + dir, "--detect=mime"},
+			wantErr: "incorrect value for --detect flag, possible values: extension, content, both",
+		},
+		{
+			name:    "photo dimension without optimize",
+			args:    []string{"--attach=" + dir, "--max-photo-dimension=800"},
+			wantErr: "--max-photo-dimension requires --optimize-photos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			albums, err := runAlbums(t, 0, tt.args...)
+			require.EqualError(t, err, tt.wantErr)
+			assert.Empty(t, albums)
+		})
+	}
+}
+
+func TestSendAttachments_Detect(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	screenshot := filepath.Join(dir, "screenshot")
+	renamed := filepath.Join(dir, "notes.jpg")
+	require.NoError(t, os.WriteFile(screenshot, []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), 0o600))
+	require.NoError(t, os.WriteFile(renamed, []byte("plain text"), 0o600))
+
+	tests := []struct {
+		detect    string
+		wantTypes [][]string
+	}{
+		{detect: "extension", wantTypes: [][]string{{"notes.jpg:photo"}, {"screenshot:document"}}},
+		{detect: "content", wantTypes: [][]string{{"screenshot:photo"}, {"notes.jpg:document"}}},
+		{detect: "both", wantTypes: [][]string{{"screenshot:photo"}, {"notes.jpg:document"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.detect, func(t *testing.T) {
+			t.Parallel()
+
+			albums, err := runAlbums(t, 0, "--attach="+screenshot, "--attach="+renamed, "--detect="+tt.detect)
+			require.NoError(t, err)
+
+			gotTypes := make([][]string, 0, len(albums))
+			for _, album := range albums {
+				types := make([]string, 0, len(album))
+				for _, media := range album {
+					types = append(types, media.FileName+":"+media.Type)
+				}
+				gotTypes = append(gotTypes, types)
+			}
+			assert.Equal(t, tt.wantTypes, gotTypes)
+		})
+	}
+}
+
+func TestSendAttachments_OptimizePhotos(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "chart.png")
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 400, 200))))
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o600))
+
+	albums, err := runAlbums(t, 0, "--attach="+path, "--optimize-photos", "--max-photo-dimension=100")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	require.Len(t, albums[0], 1)
+	assert.Equal(t, "photo", albums[0][0].Type)
+	assert.Equal(t, "chart.jpg", albums[0][0].FileName)
+}
@@ -0,0 +1,187 @@
+package tests_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+const pendingUpdatesResponse = `{"ok":true,"result":[
+	{"update_id":100,"message":{"message_id":1,"chat":{"id":75757,"type":"private","first_name":"Ada","last_name":"L","username":"ada"},"text":"hi"}},
+	{"update_id":101,"my_chat_member":{"chat":{"id":-1001,"type":"channel","title":"Alerts"}}},
+	{"update_id":102,"message":{"message_id":5,"message_thread_id":11,"is_topic_message":true,
+		"chat":{"id":-1002,"type":"supergroup","title":"Dev team","is_forum":true},
+		"forum_topic_created":{"name":"Deployments"}}},
+	{"update_id":103,"message":{"message_id":6,"message_thread_id":15,"is_topic_message":true,
+		"chat":{"id":-1002,"type":"supergroup","title":"Dev team","is_forum":true},"text":"hello"}},
+	{"update_id":104,"message":{"message_id":2,"chat":{"id":75757,"type":"private","first_name":"Ada"},"text":"again"}}
+]}`
+
+func TestChats_Output(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "table",
+			args: []string{"chats", "--token=123:abc"},
+			expected: "CHAT ID  TYPE        TITLE     USERNAME  TOPICS\n" +
+				"75757    private     Ada L     @ada      -\n" +
+				"-1001    channel     Alerts    -         -\n" +
+				"-1002    supergroup  Dev team  -         11 (Deployments), 15\n",
+		},
+		{
+			name: "json",
+			args: []string{"chats", "--token=123:abc", "--output=json"},
+			expected: `[
+  {
+    "id": 75757,
+    "type": "private",
+    "title": "Ada L",
+    "username": "ada"
+  },
+  {
+    "id": -1001,
+    "type": "channel",
+    "title": "Alerts"
+  },
+  {
+    "id": -1002,
+    "type": "supergroup",
+    "title": "Dev team",
+    "topics": [
+      {
+        "id": 11,
+        "name": "Deployments"
+      },
+      {
+        "id": 15
+      }
+    ]
+  }
+]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var capturedBodies []string
+			mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+				bodyBytes, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, "/bot123:abc/getUpdates", r.URL.Path)
+				capturedBodies = append(capturedBodies, strings.TrimSpace(string(bodyBytes)))
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(pendingUpdatesResponse))
+			})
+
+			app := cli.NewApp(mockServer.URL)
+			app.Writer = outputBuf
+
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.NoError(t, err)
+
+			// Without --ack the command must not send an offset, which would
+			// consume the updates.
+			assert.Equal(t, []string{`{}`}, capturedBodies)
+			assert.Equal(t, tt.expected, outputBuf.String())
+		})
+	}
+}
+
+func TestChats_WaitAndAck(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var capturedBodies []string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mu.Lock()
+		capturedBodies = append(capturedBodies, strings.TrimSpace(string(bodyBytes)))
+		requestCount := len(capturedBodies)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if requestCount == 1 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(pendingUpdatesResponse))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"chats", "--token=123:abc", "--wait", "--ack"}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`{"timeout":25}`,
+		`{"timeout":25}`,
+		`{"offset":105,"limit":1}`,
+	}, capturedBodies)
+	assert.Contains(t, outputBuf.String(), "-1002    supergroup")
+}
+
+func TestChats_NoUpdates(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"chats", "--token=123:abc"}))
+	require.NoError(t, err)
+	assert.Contains(t, outputBuf.String(), "No chats found.")
+}
+
+func TestChats_ErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "no token",
+			args: []string{"chats"},
+			want: "missing required flag: --token",
+		},
+		{
+			name: "invalid output",
+			args: []string{"chats", "--token=whatever", "--output=xml"},
+			want: "incorrect value for --output flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := cli.NewApp("dummy")
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
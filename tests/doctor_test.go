@@ -0,0 +1,176 @@
+package tests_test
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestDoctor_ForumAdministrator(t *testing.T) {
+	t.Parallel()
+
+	var actionBody string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch path.Base(r.URL.Path) {
+		case "getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":123,"is_bot":true,"first_name":"Owl",` +
+				`"username":"owl_bot"}}`))
+		case "getChat":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":-1002,"type":"supergroup","title":"Dev team",` +
+				`"is_forum":true}}`))
+		case "getChatMember":
+			assert.JSONEq(t, `{"chat_id":"-1002","user_id":123}`, string(bodyBytes))
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"status":"administrator","can_pin_messages":true}}`))
+		case "sendChatAction":
+			actionBody = strings.TrimSpace(string(bodyBytes))
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"doctor", "--token=123:abc", "--chat=-1002", "--thread=11"}))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":"11","action":"typing"}`, actionBody)
+	assert.Equal(t, `[PASS] Token          @owl_bot (ID 123)
+[PASS] Chat           Dev team (supergroup forum, ID -1002)
+[PASS] Membership     administrator
+[PASS] Send messages
+[PASS] Send media
+[PASS] Pin messages
+[WARN] Manage topics  the bot cannot create or edit topics
+[PASS] Thread         topic 11 exists
+`, outputBuf.String())
+}
+
+func TestDoctor_FailingChecksJSON(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch path.Base(r.URL.Path) {
+		case "getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":123,"is_bot":true,"first_name":"Owl"}}`))
+		case "getChat":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":-1003,"type":"group","title":"Ops",
+				"permissions":{"can_send_messages":true,"can_send_photos":true,"can_send_documents":true}}}`))
+		case "getChatMember":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"status":"member"}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"doctor", "--token=123:abc", "--chat=-1003", "--thread=11", "--output=json",
+	}))
+	require.EqualError(t, err, "doctor found 1 failing check(s)")
+
+	assert.JSONEq(t, `{
+		"ok": false,
+		"checks": [
+			{"id":"token","status":"pass","detail":"Owl (ID 123)"},
+			{"id":"chat","status":"pass","detail":"Ops (group, ID -1003)"},
+			{"id":"membership","status":"pass","detail":"member"},
+			{"id":"send_messages","status":"pass"},
+			{"id":"send_media","status":"warn","detail":"not allowed: videos, audios"},
+			{"id":"pin_messages","status":"warn","detail":"the bot cannot pin messages"},
+			{"id":"thread","status":"fail","detail":"--thread is set but the chat is not a forum"}
+		]
+	}`, outputBuf.String())
+}
+
+func TestDoctor_RestrictedNonMember(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch path.Base(r.URL.Path) {
+		case "getMe":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":123,"is_bot":true,"first_name":"Owl"}}`))
+		case "getChat":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":-1003,"type":"supergroup","title":"Ops"}}`))
+		case "getChatMember":
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"status":"restricted","is_member":false,` +
+				`"can_send_messages":true}}`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"doctor", "--token=123:abc", "--chat=-1003"}))
+	require.EqualError(t, err, "doctor found 3 failing check(s)")
+	assert.Contains(t, outputBuf.String(), "[FAIL] Membership     bot is not a member (restricted)\n")
+}
+
+func TestDoctor_InvalidToken(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/botbad:token/getMe", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"doctor", "--token=bad:token", "--chat=-1002"}))
+	require.EqualError(t, err, "doctor found 1 failing check(s)")
+	assert.Equal(t, `[FAIL] Token  get me: telegram api error [getMe] (http 401): 401 - Unauthorized
+[SKIP] Chat   requires a valid token
+`, outputBuf.String())
+}
+
+func TestDoctor_ErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "no chat",
+			args: []string{"doctor", "--token=whatever"},
+			want: "missing required flag: --chat",
+		},
+		{
+			name: "invalid output",
+			args: []string{"doctor", "--token=whatever", "--chat=whatever", "--output=yaml"},
+			want: "incorrect value for --output flag",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := cli.NewApp("dummy")
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package tests_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func writeEntities(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "entities.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestEntities_SendMessage(t *testing.T) {
+	t.Parallel()
+
+	entities := `[{"type":"bold","offset":3,"length":6},{"type":"code","offset":13,"length":6}]`
+
+	payloads, err := runSendMessages(t, "--entities", writeEntities(t, entities), "-m", "🚀 Deploy of *v1.2* done")
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "🚀 Deploy of *v1.2* done", payloads[0].Text)
+	assert.Empty(t, payloads[0].ParseMode)
+	assert.JSONEq(t, entities, string(payloads[0].Entities))
+}
+
+func TestEntities_Caption(t *testing.T) {
+	t.Parallel()
+
+	var capturedMedia string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(32<<20)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		capturedMedia = r.FormValue("media")
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(okResponse(r))
+	})
+
+	reportFile, err := os.CreateTemp(t.TempDir(), "report.txt")
+	require.NoError(t, err)
+	require.NoError(t, reportFile.Close())
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+	err = app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=75757",
+		"--attach=" + reportFile.Name(),
+		"--entities", writeEntities(t, `[{"type":"bold","offset":0,"length":6}]`),
+		"--caption-entities", writeEntities(t, `[{"type":"italic","offset":0,"length":6}]`),
+		"--message=Report",
+	}))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `[{"type":"document","media":"attach://file0","caption":"Report",`+
+		`"caption_entities":[{"type":"italic","offset":0,"length":6}]}]`, capturedMedia)
+}
+
+func TestEntities_Validation(t *testing.T) {
+	t.Parallel()
+
+	valid := writeEntities(t, `[{"type":"bold","offset":0,"length":2}]`)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "with format",
+			args:    []string{"--entities", valid, "--format=html", "-m", "hello"},
+			wantErr: "--entities cannot be combined with --format, entities replace the parse mode",
+		},
+		{
+			name:    "caption entities without attachments",
+			args:    []string{"--caption-entities", valid, "-m", "hello"},
+			wantErr: "--caption-entities requires --attach",
+		},
+		{
+			name:    "with split",
+			args:    []string{"--entities", valid, "--split", "-m", "hello"},
+			wantErr: "--entities cannot be combined with --overflow=split",
+		},
+		{
+			name:    "not an array",
+			args:    []string{"--entities", writeEntities(t, `{"type":"bold"}`), "-m", "hello"},
+			wantErr: "as a JSON array of MessageEntity",
+		},
+		{
+			name: "crossing entities",
+			args: []string{
+				"--entities",
+				writeEntities(t, `[{"type":"bold","offset":0,"length":3},{"type":"italic","offset":2,"length":3}]`),
+				"-m", "hello",
+			},
+			wantErr: "invalid --entities: entity 0 (bold) and entity 1 (italic) cross each other",
+		},
+		{
+			name:    "past the end of the text",
+			args:    []string{"--entities", valid, "-m", "h"},
+			wantErr: "invalid --entities: entity 0 (bold): offset 0 and length 2 end after the text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			app := cli.NewApp("http://127.0.0.1:0")
+			app.Writer = new(bytes.Buffer)
+			app.ErrWriter = new(bytes.Buffer)
+			err := app.Run(t.Context(), args)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package tests_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestEscape_WholeMessage(t *testing.T) {
+	t.Parallel()
+
+	payloads, err := runSendMessages(t, "--format=markdown", "--escape", "-m", "Release v1.2.3 (beta) is out!")
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, `Release v1\.2\.3 \(beta\) is out\!`, payloads[0].Text)
+	assert.Equal(t, "MarkdownV2", payloads[0].ParseMode)
+}
+
+func TestEscape_TemplateVariables(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		text   string
+		want   string
+	}{
+		{
+			name:   "markdown",
+			format: "markdown",
+			text:   "*Deployed* {{ .version | escape }} by {{ .user | escape }}",
+			want:   `*Deployed* 1\.2\-rc,final by a\_b`,
+		},
+		{
+			name:   "html",
+			format: "html",
+			text:   "<b>Deployed</b> {{ .version | escape }} by {{ .user | escape }}",
+			want:   "<b>Deployed</b> 1.2-rc,final by a_b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			payloads, err := runSendMessages(
+				t, "--format="+tt.format, "--var", "version=1.2-rc,final", "--var=user=a_b", "-m", tt.text,
+			)
+			require.NoError(t, err)
+			require.Len(t, payloads, 1)
+			assert.Equal(t, tt.want, payloads[0].Text)
+		})
+	}
+}
+
+func TestEscape_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "escape without format",
+			args:    []string{"--escape", "-m", "hello"},
+			wantErr: "--escape requires --format, plain text needs no escaping",
+		},
+		{
+			name:    "escape with variables",
+			args:    []string{"--escape", "--format=html", "--var=a=1", "-m", "hello"},
+			wantErr: "--escape cannot be combined with --var, use {{ .name | escape }} in the message instead",
+		},
+		{
+			name:    "variable without value",
+			args:    []string{"--var=version", "-m", "hello"},
+			wantErr: `expected name=value, got "version"`,
+		},
+		{
+			name:    "variable with an invalid name",
+			args:    []string{"--var=build-id=7", "-m", "hello"},
+			wantErr: `variable name "build-id" must start with a letter or underscore`,
+		},
+		{
+			name:    "variable set twice",
+			args:    []string{"--var=a=1", "--var=a=2", "-m", "hello"},
+			wantErr: `variable "a" is set more than once`,
+		},
+		{
+			name:    "missing variable",
+			args:    []string{"--var=a=1", "-m", "{{ .b }}"},
+			wantErr: `map has no entry for key "b"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			app := cli.NewApp("http://127.0.0.1:0")
+			app.Writer = new(bytes.Buffer)
+			app.ErrWriter = new(bytes.Buffer)
+			err := app.Run(t.Context(), args)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
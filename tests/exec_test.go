@@ -0,0 +1,178 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+const execSendMessageOK = `{"ok":true,"result":{"message_id":1}}`
+
+func runExec(t *testing.T, serverURL string, args ...string) (string, error) {
+	t.Helper()
+
+	outputBuf := new(bytes.Buffer)
+	app := cli.NewApp(serverURL)
+	app.Writer = outputBuf
+	app.ErrWriter = new(bytes.Buffer)
+
+	err := app.Run(t.Context(), getTestArgs(append([]string{"exec", "--token=123:abc", "--chat=75757"}, args...)))
+
+	return outputBuf.String(), err
+}
+
+func sentText(t *testing.T, body string) string {
+	t.Helper()
+
+	var payload struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &payload))
+
+	return payload.Text
+}
+
+func TestExec_FailureReportsAndKeepsExitCode(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMessage": execSendMessageOK})
+
+	output, err := runExec(t, serverURL, "--name", "nightly <backup>", "--", "sh", "-c", "echo 'copying <db>'; exit 3")
+	require.Error(t, err)
+	assert.Equal(t, 3, cli.ExitCode(err))
+	assert.Equal(t, "sh failed with exit code 3", err.Error())
+	assert.Equal(t, "copying <db>\n", output, "child output is passed through")
+
+	require.Equal(t, []string{"sendMessage"}, rs.calls)
+	text := sentText(t, rs.bodies["sendMessage"])
+	assert.Contains(t, text, "❌ <b>nightly &lt;backup&gt;</b> failed with exit code 3\n")
+	assert.Contains(t, text, `Command: <code>sh -c &#34;echo &#39;copying &lt;db&gt;&#39;; exit 3&#34;</code>`)
+	assert.Contains(t, text, "\n\n<pre>copying &lt;db&gt;</pre>")
+	assert.Contains(t, rs.bodies["sendMessage"], `"parse_mode":"html"`)
+}
+
+func TestExec_PassesFlagsAfterCommandToChild(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{})
+
+	output, err := runExec(t, serverURL, "echo", "-n", "--on", "always")
+	require.NoError(t, err)
+	assert.Equal(t, "--on always", output)
+	assert.Empty(t, rs.calls, "--on failure is the default and the command succeeded")
+}
+
+func TestExec_ChildReadsCommandReader(t *testing.T) {
+	t.Parallel()
+
+	_, serverURL := newRecordingServer(t, map[string]string{"sendMessage": execSendMessageOK})
+
+	outputBuf := new(bytes.Buffer)
+	app := cli.NewApp(serverURL)
+	app.Writer = outputBuf
+	app.ErrWriter = new(bytes.Buffer)
+	app.Reader = strings.NewReader("from stdin\n")
+
+	err := app.Run(t.Context(), getTestArgs([]string{"exec", "--token=123:abc", "--chat=75757", "--", "cat"}))
+	require.NoError(t, err)
+	assert.Equal(t, "from stdin\n", outputBuf.String())
+}
+
+func TestExec_Always(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMessage": execSendMessageOK})
+
+	_, err := runExec(t, serverURL, "--on", "always", "--", "true")
+	require.NoError(t, err)
+	require.Equal(t, []string{"sendMessage"}, rs.calls)
+	text := sentText(t, rs.bodies["sendMessage"])
+	assert.Contains(t, text, "✅ <b>true</b> succeeded\n")
+	assert.Contains(t, text, "\n\n<i>No output.</i>")
+}
+
+func TestExec_OnChange(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMessage": execSendMessageOK})
+	cacheDir := t.TempDir()
+	run := func(command string) {
+		_, _ = runExec(
+			t, serverURL, "--on", "change", "--name", "backup", "--cache-dir", cacheDir, "--", "sh", "-c", command,
+		)
+	}
+
+	run("exit 0")
+	assert.Empty(t, rs.calls, "a first successful run is not a change")
+
+	run("exit 1")
+	assert.Len(t, rs.calls, 1)
+
+	run("exit 2")
+	assert.Len(t, rs.calls, 1, "still failing")
+
+	run("exit 0")
+	assert.Len(t, rs.calls, 2, "recovered")
+	assert.Contains(t, sentText(t, rs.bodies["sendMessage"]), "✅ <b>backup</b> succeeded")
+}
+
+func TestExec_LongOutputIsAttached(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMediaGroup": `{"ok":true,"result":[]}`})
+
+	_, err := runExec(t, serverURL, "--", "sh", "-c", "seq 1 3000; exit 1")
+	require.Error(t, err)
+
+	require.Equal(t, []string{"sendMediaGroup"}, rs.calls)
+	body := rs.bodies["sendMediaGroup"]
+	assert.Contains(t, body, `filename="sh_-c_seq_1_3000_exit_1.log"`)
+	assert.Contains(t, body, "1\n2\n3\n")
+	assert.Contains(t, body, `2999\n3000\u003c/pre\u003e"`, "the caption ends with the last lines")
+}
+
+func TestExec_CommandNotFound(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMessage": execSendMessageOK})
+
+	_, err := runExec(t, serverURL, "--", "telegram-owl-missing-command")
+	require.Error(t, err)
+	assert.Equal(t, 127, cli.ExitCode(err))
+	assert.Contains(t, sentText(t, rs.bodies["sendMessage"]), "could not start")
+}
+
+func TestExec_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "missing command",
+			wantErr: "missing command to run",
+		},
+		{
+			name:    "unknown policy",
+			args:    []string{"--on", "sometimes", "--", "true"},
+			wantErr: "incorrect value for --on flag, possible values: failure, always, change",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := runExec(t, "http://127.0.0.1:0", tt.args...)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
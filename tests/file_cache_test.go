@@ -0,0 +1,200 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+// fileIDServer answers sendMediaGroup like Telegram, returning the file_id
+// "id-<name>" for every uploaded file. With rejectFileIDs set, albums that
+// reference a file_id fail with Telegram's wrong file identifier error.
+type fileIDServer struct {
+	rejectFileIDs atomic.Bool
+	// media records the media field of every album.
+	media []string
+}
+
+func (s *fileIDServer) start(t *testing.T) string {
+	t.Helper()
+
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var media []albumMedia
+		assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+		s.media = append(s.media, r.FormValue("media"))
+
+		messages := make([]string, 0, len(media))
+		for i, item := range media {
+			fileID := item.Media
+			if files := r.MultipartForm.File[strings.TrimPrefix(item.Media, "attach://")]; len(files) > 0 {
+				fileID = "id-" + files[0].Filename
+			} else if s.rejectFileIDs.Load() {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"ok":false,"error_code":400,` +
+					`"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+
+				return
+			}
+
+			if item.Type == "photo" {
+				messages = append(messages, fmt.Sprintf(
+					`{"message_id":%d,"photo":[{"file_id":"thumb"},{"file_id":%q}]}`, i+1, fileID,
+				))
+			} else {
+				messages = append(messages, fmt.Sprintf(`{"message_id":%d,%q:{"file_id":%q}}`, i+1, item.Type, fileID))
+			}
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":[` + strings.Join(messages, ",") + `]}`))
+	})
+
+	return mockServer.URL
+}
+
+func runFileIDSend(t *testing.T, serverURL string, args ...string) (string, error) {
+	t.Helper()
+
+	outputBuf := new(bytes.Buffer)
+	app := cli.NewApp(serverURL)
+	app.Writer = outputBuf
+	err := app.Run(t.Context(), getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, args...)))
+
+	return outputBuf.String(), err
+}
+
+func TestSendAttachments_ReusesCachedFileIDs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	logo := filepath.Join(dir, "logo.png")
+	report := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(logo, []byte("logo"), 0o600))
+	require.NoError(t, os.WriteFile(report, []byte("report"), 0o600))
+	cacheDir := "--cache-dir=" + filepath.Join(dir, "cache")
+
+	server := &fileIDServer{}
+	serverURL := server.start(t)
+
+	_, err := runFileIDSend(t, serverURL, cacheDir, "--attach="+logo, "--attach="+report)
+	require.NoError(t, err)
+
+	// A copy under another name has the same content, so it is not uploaded.
+	renamed := filepath.Join(dir, "logo-copy.png")
+	require.NoError(t, os.WriteFile(renamed, []byte("logo"), 0o600))
+	output, err := runFileIDSend(t, serverURL, cacheDir, "--verbose", "--attach="+renamed, "--attach="+report)
+	require.NoError(t, err)
+	assert.Contains(t, output, "Reused 2 cached file(s) instead of uploading")
+
+	_, err = runFileIDSend(t, serverURL, cacheDir, "--no-cache", "--attach="+report)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`[{"type":"photo","media":"attach://file0"}]`,
+		`[{"type":"document","media":"attach://file0"}]`,
+		`[{"type":"photo","media":"id-logo.png"}]`,
+		`[{"type":"document","media":"id-report.pdf"}]`,
+		`[{"type":"document","media":"attach://file0"}]`,
+	}, server.media)
+}
+
+func TestSendAttachments_ForgetsWrongFileIDs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(report, []byte("report"), 0o600))
+	cacheDir := "--cache-dir=" + filepath.Join(dir, "cache")
+
+	server := &fileIDServer{}
+	serverURL := server.start(t)
+
+	_, err := runFileIDSend(t, serverURL, cacheDir, "--attach="+report)
+	require.NoError(t, err)
+
+	// Telegram no longer knows the file, so the album is uploaded again and
+	// the new file_id replaces the stale one.
+	server.rejectFileIDs.Store(true)
+	_, err = runFileIDSend(t, serverURL, cacheDir, "--attach="+report)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`[{"type":"document","media":"attach://file0"}]`,
+		`[{"type":"document","media":"id-report.pdf"}]`,
+		`[{"type":"document","media":"attach://file0"}]`,
+	}, server.media)
+
+	content, err := os.ReadFile(filepath.Join(dir, "cache", "files.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"file_id": "id-report.pdf"`)
+}
+
+func TestSendAttachments_StreamedArchiveIsNotCached(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	artifacts := filepath.Join(dir, "artifacts")
+	require.NoError(t, os.MkdirAll(artifacts, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(artifacts, "report.txt"), []byte("report"), 0o600))
+	cacheDir := "--cache-dir=" + filepath.Join(dir, "cache")
+
+	server := &fileIDServer{}
+	serverURL := server.start(t)
+
+	// The archive is compressed while it is uploaded, so it cannot be hashed
+	// beforehand and is uploaded every time.
+	for range 2 {
+		_, err := runFileIDSend(t, serverURL, cacheDir, "--attach="+artifacts, "--archive=zip")
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{
+		`[{"type":"document","media":"attach://file0"}]`,
+		`[{"type":"document","media":"attach://file0"}]`,
+	}, server.media)
+}
+
+func TestCachePrune(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	report := filepath.Join(dir, "report.pdf")
+	require.NoError(t, os.WriteFile(report, []byte("report"), 0o600))
+	cacheDir := "--cache-dir=" + filepath.Join(dir, "cache")
+
+	server := &fileIDServer{}
+	_, err := runFileIDSend(t, server.start(t), cacheDir, "--attach="+report)
+	require.NoError(t, err)
+
+	prune := func(args ...string) string {
+		t.Helper()
+
+		outputBuf := new(bytes.Buffer)
+		app := cli.NewApp("http://127.0.0.1:0")
+		app.Writer = outputBuf
+		require.NoError(t, app.Run(t.Context(), getTestArgs(append([]string{"cache", "prune", cacheDir}, args...))))
+
+		return outputBuf.String()
+	}
+
+	assert.Equal(t, "Removed 0 cached file ID(s)\n", prune())
+	assert.Equal(t, "Removed 1 cached file ID(s)\n", prune("--all"))
+
+	err = cli.NewApp("http://127.0.0.1:0").Run(t.Context(), getTestArgs([]string{"cache", "prune", "--older-than=0s"}))
+	require.EqualError(t, err, "--older-than must be positive, use --all to remove every file ID")
+}
@@ -0,0 +1,120 @@
+package tests_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+const releaseNotes = "## v1.2.0\n\n- **Fixed** crash on `--stdin`\n- Faster uploads\n\n" +
+	"| OS | Status |\n|----|--------|\n| linux | ok |\n\n![demo](https://example.com/demo.gif)"
+
+func TestGFM_SendsTelegramHTML(t *testing.T) {
+	t.Parallel()
+
+	payloads, err := runSendMessages(t, "--format=gfm", "-m", releaseNotes)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "html", payloads[0].ParseMode)
+	assert.Equal(
+		t,
+		"<b>v1.2.0</b>\n\n• <b>Fixed</b> crash on <code>--stdin</code>\n• Faster uploads\n\n"+
+			"<pre>OS    | Status\n------|-------\nlinux | ok</pre>\n\n"+
+			`<a href="https://example.com/demo.gif">demo</a>`,
+		payloads[0].Text,
+	)
+}
+
+func TestGFM_EscapedTemplateVariables(t *testing.T) {
+	t.Parallel()
+
+	payloads, err := runSendMessages(
+		t, "--format=gfm", "--var=branch=fix_*all*", "-m", "**Branch** {{ .branch | escape }}",
+	)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "<b>Branch</b> fix_*all*", payloads[0].Text)
+}
+
+func TestGFM_SendsRichHTML(t *testing.T) {
+	t.Parallel()
+
+	var capturedPath, capturedBody string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		capturedPath = r.URL.Path
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		capturedBody = string(bodyBytes)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=75757",
+		"--format=gfm",
+		"--gfm-target=rich-html",
+		"--message=# Deployment\n\n![graph](https://example.com/g.png)",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, `/bot123:abc/sendRichMessage`, capturedPath)
+	assert.JSONEq(t, `{
+		"chat_id":"75757",
+		"rich_message":{"html":"<h1>Deployment</h1>\n<p><a href=\"https://example.com/g.png\">graph</a></p>"}
+	}`, capturedBody)
+}
+
+func TestGFM_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "unknown format",
+			args: []string{"--format=github", "-m", "hello"},
+			wantErr: "incorrect value for --format flag, " +
+				"possible values: markdown, html, rich-markdown, rich-html, gfm",
+		},
+		{
+			name:    "unknown target",
+			args:    []string{"--format=gfm", "--gfm-target=markdown", "-m", "hello"},
+			wantErr: "incorrect value for --gfm-target flag, possible values: html, rich-html",
+		},
+		{
+			name:    "target without gfm",
+			args:    []string{"--format=html", "--gfm-target=rich-html", "-m", "hello"},
+			wantErr: "--gfm-target requires --format gfm",
+		},
+		{
+			name:    "rich target without link preview",
+			args:    []string{"--format=gfm", "--gfm-target=rich-html", "--no-link-preview", "-m", "hello"},
+			wantErr: "--no-link-preview is not supported with rich message formats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			app := cli.NewApp("http://127.0.0.1:0")
+			app.Writer = new(bytes.Buffer)
+			app.ErrWriter = new(bytes.Buffer)
+			err := app.Run(t.Context(), args)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package tests_test
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestLint_Files(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	okFile := filepath.Join(dir, "ok.html")
+	brokenFile := filepath.Join(dir, "broken.html")
+	require.NoError(t, os.WriteFile(okFile, []byte("<b>Deploy</b> <i>done</i> &lt;3\n"), 0o600))
+	require.NoError(t, os.WriteFile(brokenFile, []byte("<b>Deploy\nfailed</i>\n"), 0o600))
+
+	outputBuf := new(bytes.Buffer)
+	app := cli.NewApp("")
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{"lint", "--format=html", okFile, brokenFile}))
+	require.EqualError(t, err, "lint found 1 problem(s)")
+
+	assert.Equal(t, okFile+": ok, 14 characters, 2 entities\n"+
+		brokenFile+":2:7: end tag </i> does not match <b> opened at line 1, column 1\n", outputBuf.String())
+}
+
+func TestLint_Message(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "valid markdown",
+			args:       []string{"--format=markdown", `--message=Build *passed*\.`},
+			wantOutput: "message: ok, 13 characters, 1 entities\n",
+		},
+		{
+			name:       "reserved character",
+			args:       []string{"--format=markdown", "--message=Build *passed*\nin 1.5s"},
+			wantOutput: `message:2:5: character "." is reserved and must be escaped with a preceding "\"` + "\n",
+			wantErr:    "lint found 1 problem(s)",
+		},
+		{
+			name:       "caption over the limit after parsing",
+			args:       []string{"--format=html", "--caption", "--message=<b>" + strings.Repeat("a", 1025) + "</b>"},
+			wantOutput: "message: too long: must be <= 1024 characters after parsing, got 1025\n",
+			wantErr:    "lint found 1 problem(s)",
+		},
+		{
+			name:    "unknown format",
+			args:    []string{"--format=gfm", "--message=x"},
+			wantErr: "incorrect value for --format flag, possible values: markdown, html",
+		},
+		{
+			name:    "message and files",
+			args:    []string{"--format=html", "--message=x", "notes.html"},
+			wantErr: "--message cannot be combined with FILE arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputBuf := new(bytes.Buffer)
+			app := cli.NewApp("")
+			app.Writer = outputBuf
+
+			err := app.Run(t.Context(), getTestArgs(append([]string{"lint"}, tt.args...)))
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, tt.wantErr)
+			}
+			assert.Equal(t, tt.wantOutput, outputBuf.String())
+		})
+	}
+}
+
+func TestLint_Stdin(t *testing.T) {
+	t.Parallel()
+
+	outputBuf := new(bytes.Buffer)
+	app := cli.NewApp("")
+	app.Writer = outputBuf
+	app.Reader = strings.NewReader("<code>x < y</code>\n")
+
+	err := app.Run(t.Context(), getTestArgs([]string{"lint", "--format=html"}))
+	require.EqualError(t, err, "lint found 1 problem(s)")
+	assert.Equal(t, "stdin:1:9: unescaped \"<\", write &lt; to show it\n", outputBuf.String())
+}
+
+func TestSendMessage_SyntaxErrorStopsBeforeSending(t *testing.T) {
+	t.Parallel()
+
+	mockServer, outputBuf := setupMockServer(t, func(_ http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request: %s", r.URL.Path)
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=75757",
+		"--format=markdown",
+		"--message=*Deploy* finished\nSee run #42",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(),
+		`cannot parse message: line 2, column 9: character "#" is reserved and must be escaped with a preceding "\"`)
+	assert.Empty(t, outputBuf.String())
+}
@@ -0,0 +1,123 @@
+package tests_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestOverflow_Document(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMediaGroup": `{"ok":true,"result":[]}`})
+	message := strings.Repeat("log line\n", 500)
+
+	app := cli.NewApp(serverURL)
+	app.Writer = new(bytes.Buffer)
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--overflow=document", "--overflow-filename=output.txt", "-m", message,
+	}))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"sendMediaGroup"}, rs.calls)
+	body := rs.bodies["sendMediaGroup"]
+	assert.Contains(t, body, `filename="output.txt"`)
+	assert.Contains(t, body, message)
+	assert.Contains(t, body, `"type":"document"`)
+	assert.Contains(t, body, `"caption":"The message is too long for Telegram (4500 characters, 500 lines)`)
+	assert.NotContains(t, body, "parse_mode")
+}
+
+func TestOverflow_ShortMessageIsSentAsText(t *testing.T) {
+	t.Parallel()
+
+	rs, serverURL := newRecordingServer(t, map[string]string{"sendMessage": `{"ok":true,"result":{"message_id":1}}`})
+
+	app := cli.NewApp(serverURL)
+	app.Writer = new(bytes.Buffer)
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--overflow=document", "-m", "Hello",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sendMessage"}, rs.calls)
+}
+
+func TestOverflow_Truncate(t *testing.T) {
+	t.Parallel()
+
+	lines := make([]string, 0, 1000)
+	for i := range 1000 {
+		lines = append(lines, strings.Repeat("x", 3)+string(rune('a'+i%26)))
+	}
+	message := "<b>" + strings.Join(lines, "\n") + "</b>"
+
+	payloads, err := runSendMessages(t, "--format=html", "--overflow=truncate", "--overflow-keep=tail", "-m", message)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	text := payloads[0].Text
+	assert.True(t, strings.HasPrefix(text, "… 186 lines omitted …\n<b>xxxe\n"))
+	assert.True(t, strings.HasSuffix(text, "\nxxxl</b>"))
+	assert.LessOrEqual(t, len([]rune(text))-len("<b></b>"), 4096)
+}
+
+func TestOverflow_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "unknown mode",
+			args:    []string{"--overflow=drop"},
+			wantErr: "incorrect value for --overflow flag, possible values: error, split, document, truncate",
+		},
+		{
+			name:    "split conflicts with another mode",
+			args:    []string{"--split", "--overflow=document"},
+			wantErr: "--split cannot be combined with --overflow=document",
+		},
+		{
+			name:    "file name without document",
+			args:    []string{"--overflow-filename=out.txt"},
+			wantErr: "--overflow-filename requires --overflow=document",
+		},
+		{
+			name:    "file name with a directory",
+			args:    []string{"--overflow=document", "--overflow-filename=logs/out.txt"},
+			wantErr: "--overflow-filename must be a file name without a directory",
+		},
+		{
+			name:    "keep without truncate",
+			args:    []string{"--overflow-keep=head"},
+			wantErr: "--overflow-keep requires --overflow=truncate",
+		},
+		{
+			name:    "unknown keep",
+			args:    []string{"--overflow=truncate", "--overflow-keep=middle"},
+			wantErr: "incorrect value for --overflow-keep flag, possible values: head, tail, both",
+		},
+		{
+			name:    "rich formats",
+			args:    []string{"--overflow=truncate", "--format=rich-html"},
+			wantErr: "--overflow=truncate is not supported with rich message formats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757", "-m", "hello"}, tt.args...))
+			err := cli.NewApp("http://127.0.0.1:0").Run(t.Context(), args)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestReact_Success(t *testing.T) {
+	t.Parallel()
+
+	var capturedBody string
+	var capturedPath string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		capturedBody = strings.TrimSpace(string(bodyBytes))
+		capturedPath = r.URL.Path
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"react",
+		"--token=123:abc",
+		"--chat=75757",
+		"--message-id=42",
+		"--big",
+		"👍",
+		"5368324170671202286",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, `/bot123:abc/setMessageReaction`, capturedPath)
+	assert.JSONEq(t, `{
+		"chat_id":"75757",
+		"message_id":42,
+		"reaction":[
+			{"type":"emoji","emoji":"👍"},
+			{"type":"custom_emoji","custom_emoji_id":"5368324170671202286"}
+		],
+		"is_big":true
+	}`, capturedBody)
+	assert.Empty(t, outputBuf.String())
+}
+
+func TestReact_ErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "no token",
+			args: []string{"react", "--chat=whatever", "--message-id=1", "👍"},
+			want: "missing required flag: --token",
+		},
+		{
+			name: "no chat",
+			args: []string{"react", "--token=whatever", "--message-id=1", "👍"},
+			want: "missing required flag: --chat",
+		},
+		{
+			name: "no message ID",
+			args: []string{"react", "--token=whatever", "--chat=whatever", "👍"},
+			want: "message ID must be a positive number",
+		},
+		{
+			name: "no reaction",
+			args: []string{"react", "--token=whatever", "--chat=whatever", "--message-id=1"},
+			want: "at least one reaction is required",
+		},
+		{
+			name: "reaction outside the allowed set",
+			args: []string{"react", "--token=whatever", "--chat=whatever", "--message-id=1", "✅"},
+			want: `reaction "✅" is not in Telegram's allowed emoji set`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			outputBuf := new(bytes.Buffer)
+			app := cli.NewApp("dummy")
+			app.Writer = outputBuf
+
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.ErrorContains(t, err, tt.want)
+			assert.Empty(t, outputBuf.String())
+		})
+	}
+}
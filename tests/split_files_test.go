@@ -0,0 +1,217 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+func TestSendSplitLargeFiles(t *testing.T) {
+	t.Parallel()
+
+	// A sparse file just over the 50 MB limit becomes a full part and a small
+	// one without writing 50 MB to disk.
+	backup := filepath.Join(t.TempDir(), "backup.tar")
+	file, err := os.Create(backup)
+	require.NoError(t, err)
+	require.NoError(t, file.Truncate(50*attachment.BytesPerMegabyte+100))
+	require.NoError(t, file.Close())
+
+	var requests []string
+	var albums [][]albumMedia
+	var manifest string
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, filepath.Base(r.URL.Path))
+		switch filepath.Base(r.URL.Path) {
+		case "sendMediaGroup":
+			if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var media []albumMedia
+			assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+			for i := range media {
+				if files := r.MultipartForm.File[strings.TrimPrefix(media[i].Media, "attach://")]; len(files) > 0 {
+					media[i].FileName = files[0].Filename
+				}
+			}
+			albums = append(albums, media)
+			assert.NoError(t, r.MultipartForm.RemoveAll())
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"ok": true, "result": []}`))
+
+			return
+		case "sendMessage":
+			var payload struct {
+				Text string `json:"text"`
+			}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			manifest = payload.Text
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true, "result": {"message_id": 1}}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	err = app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "-m", "Nightly backup", "--attach=" + backup, "--split-large-files",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sendMediaGroup", "sendMediaGroup", "sendMessage"}, requests)
+	require.Len(t, albums, 2)
+	require.Len(t, albums[0], 1)
+	assert.Equal(t, "document", albums[0][0].Type)
+	assert.Equal(t, "backup.tar.part001", albums[0][0].FileName)
+	assert.Empty(t, albums[0][0].Caption)
+	assert.Equal(t, "backup.tar.part002", albums[1][0].FileName)
+	assert.Equal(t, "Nightly backup", albums[1][0].Caption)
+
+	assert.True(t, strings.HasPrefix(manifest, "backup.tar was split into 2 parts, 52428900 bytes in total."))
+	assert.Contains(t, manifest, "  backup.tar.part002\n")
+	assert.Contains(t, manifest, "telegram-owl join --manifest backup.tar.sha256 backup.tar.part*")
+}
+
+func TestSendSplitLargeFiles_RejectsArchive(t *testing.T) {
+	t.Parallel()
+
+	albums, err := runAlbums(t, 0, "--attach="+t.TempDir(), "--archive=zip", "--split-large-files")
+	require.EqualError(t, err, "--split-large-files cannot be combined with --archive")
+	assert.Empty(t, albums)
+}
+
+// writeParts splits content into parts of partSize bytes below a new
+// directory, the way a chat downloads them, and saves the manifest next to
+// them. It returns the part paths.
+func writeParts(t *testing.T, content string, partSize int64) []string {
+	t.Helper()
+
+	source := filepath.Join(t.TempDir(), "backup.tar")
+	require.NoError(t, os.WriteFile(source, []byte(content), 0o600))
+	opener, _, err := attachment.NewSplitOpener([]string{source}, partSize)
+	require.NoError(t, err)
+	split := opener.Files[0]
+
+	downloads := t.TempDir()
+	parts := make([]string, 0, len(split.Parts))
+	for _, part := range split.Parts {
+		opened, openErr := opener.Open(part)
+		require.NoError(t, openErr)
+		data, readErr := io.ReadAll(opened.File)
+		require.NoError(t, readErr)
+		require.NoError(t, opened.File.Close())
+
+		path := filepath.Join(downloads, filepath.Base(part))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		parts = append(parts, path)
+	}
+	manifest := filepath.Join(downloads, "backup.tar.sha256")
+	require.NoError(t, os.WriteFile(manifest, []byte(split.Manifest()), 0o600))
+
+	return parts
+}
+
+func runJoin(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var output bytes.Buffer
+	app := cli.NewApp("dummy")
+	app.Writer = &output
+	err := app.Run(t.Context(), getTestArgs(append([]string{"join"}, args...)))
+
+	return output.String(), err
+}
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	const content = "0123456789abcdefghijKLMNO"
+	parts := writeParts(t, content, 10)
+	dir := filepath.Dir(parts[0])
+	manifest := filepath.Join(dir, "backup.tar.sha256")
+
+	// Parts are ordered by number whatever order the shell passes them in.
+	output, err := runJoin(t, "--manifest", manifest, parts[2], parts[0], parts[1])
+	require.NoError(t, err)
+	assert.Equal(t, "Joined 3 parts into "+filepath.Join(dir, "backup.tar")+": 25 bytes, SHA-256 "+
+		"074af3ea8c41e380ac052d9170c0d2cc4f8e0db42c1bc47cec00813131268d90, verified against the manifest\n", output)
+
+	joined, err := os.ReadFile(filepath.Join(dir, "backup.tar"))
+	require.NoError(t, err)
+	assert.Equal(t, content, string(joined))
+
+	_, err = runJoin(t, parts...)
+	require.ErrorContains(t, err, "create output: ")
+}
+
+func TestJoin_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, parts []string) []string
+		wantErr string
+	}{
+		{
+			name: "missing part",
+			prepare: func(_ *testing.T, parts []string) []string {
+				return []string{"--manifest", manifestOf(parts), parts[0], parts[2]}
+			},
+			wantErr: `part 2 of "backup.tar" is missing`,
+		},
+		{
+			name: "missing last part",
+			prepare: func(_ *testing.T, parts []string) []string {
+				return []string{"--manifest", manifestOf(parts), parts[0], parts[1]}
+			},
+			wantErr: `lists 3 parts of "backup.tar", got 2`,
+		},
+		{
+			name: "corrupt part",
+			prepare: func(t *testing.T, parts []string) []string {
+				t.Helper()
+				require.NoError(t, os.WriteFile(parts[1], []byte("abcdefghiX"), 0o600))
+
+				return append([]string{"--manifest", manifestOf(parts)}, parts...)
+			},
+			wantErr: "backup.tar.part002: SHA-256 mismatch",
+		},
+		{
+			name: "not a part",
+			prepare: func(_ *testing.T, parts []string) []string {
+				return []string{manifestOf(parts)}
+			},
+			wantErr: "backup.tar.sha256\" is not a part: the name must end with .partNNN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			parts := writeParts(t, "0123456789abcdefghijKLMNO", 10)
+			_, err := runJoin(t, tt.prepare(t, parts)...)
+			require.ErrorContains(t, err, tt.wantErr)
+
+			// A failed join leaves no output that could pass for the file.
+			assert.NoFileExists(t, filepath.Join(filepath.Dir(parts[0]), "backup.tar"))
+		})
+	}
+}
+
+func manifestOf(parts []string) string {
+	return filepath.Join(filepath.Dir(parts[0]), "backup.tar.sha256")
+}
@@ -0,0 +1,135 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+type messagePayload struct {
+	Text            string          `json:"text"`
+	ParseMode       string          `json:"parse_mode"`
+	Entities        json.RawMessage `json:"entities"`
+	ReplyParameters *struct {
+		MessageID int `json:"message_id"`
+	} `json:"reply_parameters"`
+}
+
+// runSendMessages sends a message and returns every sendMessage payload in order.
+// The mock server answers with message IDs 41, 42 and so on.
+func runSendMessages(t *testing.T, args ...string) ([]messagePayload, error) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var payloads []messagePayload
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var payload messagePayload
+		assert.NoError(t, json.Unmarshal(bodyBytes, &payload))
+
+		mu.Lock()
+		payloads = append(payloads, payload)
+		messageID := 40 + len(payloads)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":` + strconv.Itoa(messageID) + `}}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+
+	err := app.Run(t.Context(), getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, args...)))
+
+	return payloads, err
+}
+
+func TestSplit_SendsPartsAsRepliesWithCounters(t *testing.T) {
+	t.Parallel()
+
+	paragraph := strings.TrimSpace(strings.Repeat("word ", 600))
+	message := "<b>Report\n\n" + paragraph + "\n\n" + paragraph + "</b>"
+
+	payloads, err := runSendMessages(t, "--format=html", "--split", "--split-counter", "--split-reply", "-m", message)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+
+	assert.Equal(t, "<b>Report\n\n"+paragraph+"</b>\n\n(1/2)", payloads[0].Text)
+	assert.Nil(t, payloads[0].ReplyParameters)
+
+	assert.Equal(t, "<b>"+paragraph+"</b>\n\n(2/2)", payloads[1].Text)
+	assert.Equal(t, "html", payloads[1].ParseMode)
+	require.NotNil(t, payloads[1].ReplyParameters)
+	assert.Equal(t, 41, payloads[1].ReplyParameters.MessageID)
+}
+
+func TestSplit_ShortMessageIsSentOnce(t *testing.T) {
+	t.Parallel()
+
+	payloads, err := runSendMessages(t, "--split", "--split-counter", "-m", "Hello")
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "Hello", payloads[0].Text)
+}
+
+func TestSplit_PlainTextWithoutReplies(t *testing.T) {
+	t.Parallel()
+
+	message := strings.Repeat("line\n", 1000)
+
+	payloads, err := runSendMessages(t, "--split", "-m", message)
+	require.NoError(t, err)
+	require.Len(t, payloads, 2)
+	for _, payload := range payloads {
+		assert.Nil(t, payload.ReplyParameters)
+		assert.LessOrEqual(t, len(payload.Text), 4096)
+	}
+	assert.Equal(t, message, payloads[0].Text+"\n"+payloads[1].Text, "only the line ending at the split is dropped")
+}
+
+func TestSplit_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "counter requires split",
+			args:    []string{"--split-counter", "-m", "hello"},
+			wantErr: "--split-counter requires --split",
+		},
+		{
+			name:    "reply requires split",
+			args:    []string{"--split-reply", "-m", "hello"},
+			wantErr: "--split-reply requires --split",
+		},
+		{
+			name:    "rich formats",
+			args:    []string{"--split", "--format=rich-markdown", "-m", "hello"},
+			wantErr: "--split is not supported with rich message formats",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			err := cli.NewApp("http://127.0.0.1:0").Run(t.Context(), args)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
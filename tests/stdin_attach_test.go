@@ -0,0 +1,170 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+	"github.com/beeyev/telegram-owl/internal/telegram/common/attachment"
+)
+
+// zeros is an endless stream of zero bytes.
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+
+	return len(p), nil
+}
+
+func TestSendAttachments_Stdin(t *testing.T) {
+	t.Parallel()
+
+	// Every byte value, as a gzip stream would contain.
+	var dump strings.Builder
+	for b := range 256 {
+		dump.WriteByte(byte(b))
+	}
+
+	var media []albumMedia
+	var uploaded string
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+
+		file, header, err := r.FormFile("file0")
+		if assert.NoError(t, err) {
+			data, readErr := io.ReadAll(file)
+			assert.NoError(t, readErr)
+			uploaded = string(data)
+			media[0].FileName = header.Filename
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Reader = strings.NewReader(dump.String())
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "-m", "Nightly dump", "--attach=-", "--attach-name=dump.sql.gz",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []albumMedia{{
+		Type: "document", Media: "attach://file0", Caption: "Nightly dump", FileName: "dump.sql.gz",
+	}}, media)
+	assert.Equal(t, dump.String(), uploaded)
+}
+
+func TestSendAttachments_StdinPhotoJoinsAlbum(t *testing.T) {
+	t.Parallel()
+
+	report := filepath.Join(t.TempDir(), "report.jpg")
+	require.NoError(t, os.WriteFile(report, []byte("report"), 0o600))
+
+	var albums [][]albumMedia
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var media []albumMedia
+		if assert.NoError(t, r.ParseMultipartForm(1<<20)) {
+			assert.NoError(t, json.Unmarshal([]byte(r.FormValue("media")), &media))
+		}
+		albums = append(albums, media)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+
+	// A short pipe has a known size, so it is neither demoted to a document
+	// nor put in an album of its own.
+	app := cli.NewApp(mockServer.URL)
+	app.Reader = strings.NewReader("picture")
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--attach=-", "--attach-name=x.jpg", "--attach=" + report,
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]albumMedia{{
+		{Type: "photo", Media: "attach://file0"},
+		{Type: "photo", Media: "attach://file1"},
+	}}, albums)
+}
+
+func TestSendAttachments_StdinOverLimit(t *testing.T) {
+	t.Parallel()
+
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Reader = io.LimitReader(zeros{}, 50*attachment.BytesPerMegabyte+1)
+	err := app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--attach=-", "--attach-name=dump.sql.gz",
+	}))
+	require.ErrorContains(t, err, "standard input exceeds the max allowed attachment size of 50 MB")
+}
+
+func TestSendAttachments_StdinValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "name required",
+			args:    []string{"--attach=-"},
+			wantErr: "--attach - requires --attach-name, the file name to send standard input under",
+		},
+		{
+			name:    "name requires stdin attachment",
+			args:    []string{"--attach=report.pdf", "--attach-name=dump.sql.gz"},
+			wantErr: "--attach-name requires --attach -",
+		},
+		{
+			name:    "name is not a path",
+			args:    []string{"--attach=-", "--attach-name=backups/dump.sql.gz"},
+			wantErr: "--attach-name must be a file name, not a path",
+		},
+		{
+			name:    "stdin message",
+			args:    []string{"--attach=-", "--attach-name=dump.sql.gz", "--stdin"},
+			wantErr: "--attach - and --stdin cannot both read standard input",
+		},
+		{
+			name:    "given twice",
+			args:    []string{"--attach=-", "--attach=-", "--attach-name=dump.sql.gz"},
+			wantErr: "--attach - can be given only once, standard input is read once",
+		},
+		{
+			name:    "archive",
+			args:    []string{"--attach=-", "--attach-name=dump.sql.gz", "--archive=zip"},
+			wantErr: "--archive cannot be combined with --attach -",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			err := cli.NewApp("http://127.0.0.1:0").Run(t.Context(), args)
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
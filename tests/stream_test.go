@@ -0,0 +1,112 @@
+package tests_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+func TestStream_PostsEditsAndFinalStatus(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var endpoints, bodies []string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mu.Lock()
+		endpoints = append(endpoints, path.Base(r.URL.Path))
+		bodies = append(bodies, strings.TrimSpace(string(bodyBytes)))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7}}`))
+	})
+
+	args := getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--thread=11", "--stream", "--stream-lines=2", "--stream-interval=1h",
+	})
+
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+	app.Reader = strings.NewReader("building\ncompiling <main>\ndone\n")
+
+	require.NoError(t, app.Run(t.Context(), args))
+
+	assert.Equal(t, []string{"sendMessage", "editMessageText", "sendMessage"}, endpoints)
+	require.Len(t, bodies, 3)
+	assert.JSONEq(
+		t,
+		`{"chat_id":"75757","message_thread_id":"11","text":"<pre>building</pre>",`+
+			`"parse_mode":"html","link_preview_options":{"is_disabled":true}}`,
+		bodies[0],
+	)
+	assert.JSONEq(
+		t,
+		`{"chat_id":"75757","message_id":7,"text":"<pre>compiling &lt;main&gt;\ndone</pre>",`+
+			`"parse_mode":"html","link_preview_options":{"is_disabled":true}}`,
+		bodies[1],
+	)
+	assert.Contains(t, bodies[2], `"text":"Stream ended: 3 lines in`)
+	assert.Contains(t, bodies[2], `"message_thread_id":"11"`)
+	assert.Empty(t, outputBuf.String())
+}
+
+func TestStream_RolloverReportsFailedFinalEdit(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var endpoints []string
+	mockServer, outputBuf := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		endpoint := path.Base(r.URL.Path)
+		mu.Lock()
+		endpoints = append(endpoints, endpoint)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if endpoint == "editMessageText" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"Bad Request: message to edit not found"}`))
+
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7}}`))
+	})
+
+	warnings := new(bytes.Buffer)
+	app := cli.NewApp(mockServer.URL)
+	app.Writer = outputBuf
+	app.ErrWriter = warnings
+	// The third line does not fit next to the second, so the first message is
+	// left behind and its last edit, which shows the second line, fails.
+	app.Reader = strings.NewReader(
+		"first\n" + strings.Repeat("b", 2000) + "\n" + strings.Repeat("c", 3000) + "\n",
+	)
+
+	require.NoError(t, app.Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=75757", "--stream", "--stream-lines=0", "--stream-interval=1h",
+	})))
+
+	assert.Equal(t, []string{"sendMessage", "editMessageText", "sendMessage", "sendMessage"}, endpoints)
+	assert.Contains(t, warnings.String(), "warning: the stream message is missing its last lines: ")
+	assert.Contains(t, warnings.String(), "message to edit not found")
+}
+
+func TestStream_RejectsMessageFlag(t *testing.T) {
+	t.Parallel()
+
+	args := getTestArgs([]string{"--token=123:abc", "--chat=75757", "--stream", "-m", "hello"})
+
+	err := cli.NewApp("http://127.0.0.1:0").Run(t.Context(), args)
+	require.EqualError(t, err, "--stream reads the message from stdin and cannot be combined with --message")
+}
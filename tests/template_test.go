@@ -0,0 +1,148 @@
+package tests_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+//nolint:paralleltest // Sets a process environment variable.
+func TestTemplate_FileWithVariablesAndEnvironment(t *testing.T) {
+	t.Setenv("TELEGRAM_OWL_TEST_RUNNER", "ci-7")
+
+	templatePath := filepath.Join(t.TempDir(), "deploy.tmpl")
+	require.NoError(t, os.WriteFile(templatePath, []byte(
+		"{{ .status | statusEmoji }} <b>{{ .service | escape }}</b> on {{ .env.TELEGRAM_OWL_TEST_RUNNER }}\n"+
+			"Took {{ .seconds | duration }}\n",
+	), 0o600))
+
+	payloads, err := runSendMessages(
+		t, "--format=html", "--template", templatePath,
+		"--var=status=success", "--var=service=api<v2>", "--var=seconds=125",
+	)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "✅ <b>api&lt;v2&gt;</b> on ci-7\nTook 2m5s", payloads[0].Text)
+	assert.Equal(t, "html", payloads[0].ParseMode)
+}
+
+func TestTemplate_StringWithJSONData(t *testing.T) {
+	t.Parallel()
+
+	dataPath := filepath.Join(t.TempDir(), "release.json")
+	require.NoError(t, os.WriteFile(dataPath, []byte(
+		`{"tag":"v1.2.0","id":1234567890123,"published":"2026-03-01T10:30:00Z",`+
+			`"changes":["fix_login","faster uploads"]}`,
+	), 0o600))
+
+	payloads, err := runSendMessages(
+		t, "--format=markdown", "--data", dataPath, "--template-string",
+		"*{{ .data.tag | escape }}* \\#{{ .data.id }} {{ .data.published | date \"Jan 2\" }}"+
+			"{{ range .data.changes }}\n• {{ . | escape }}{{ end }}",
+	)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "*v1\\.2\\.0* \\#1234567890123 Mar 1\n• fix\\_login\n• faster uploads", payloads[0].Text)
+}
+
+//nolint:paralleltest // Reassigns process-global os.Stdin.
+func TestTemplate_DataFromStdin(t *testing.T) {
+	stdinReader, stdinWriter, err := os.Pipe()
+	require.NoError(t, err)
+	_, err = stdinWriter.WriteString(`{"job":"nightly","log":"line 1 line 2 line 3"}`)
+	require.NoError(t, err)
+	require.NoError(t, stdinWriter.Close())
+	t.Cleanup(func() {
+		require.NoError(t, stdinReader.Close())
+	})
+
+	originalStdin := os.Stdin
+	t.Cleanup(func() {
+		//nolint:reassign // Restore process-global stdin after this test.
+		os.Stdin = originalStdin
+	})
+	//nolint:reassign // Exercise --data - with JSON from a pipe.
+	os.Stdin = stdinReader
+
+	payloads, err := runSendMessages(
+		t, "--data=-", "--template-string", "{{ .data.job }}: {{ .data.log | truncate 8 }}",
+	)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "nightly: line 1 …", payloads[0].Text)
+}
+
+func TestTemplate_Validation(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	invalidJSON := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalidJSON, []byte(`{"a":1} {"b":2}`), 0o600))
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "template file and string",
+			args:    []string{"--template=a.tmpl", "--template-string=hi"},
+			wantErr: "--template and --template-string cannot be combined",
+		},
+		{
+			name: "template and message",
+			args: []string{"--template-string=hi", "-m", "hello"},
+			wantErr: "--template and --template-string replace the message and cannot be combined with " +
+				"--message or --stdin",
+		},
+		{
+			name:    "data and message both from stdin",
+			args:    []string{"--data=-", "--stdin"},
+			wantErr: "--data - and --stdin cannot both read standard input",
+		},
+		{
+			name:    "template with escape",
+			args:    []string{"--template-string=hi", "--escape", "--format=html"},
+			wantErr: "--escape cannot be combined with templates",
+		},
+		{
+			name:    "reserved variable name",
+			args:    []string{"--var=env=prod", "--template-string=hi"},
+			wantErr: `variable name "env" is reserved, templates use .env for it`,
+		},
+		{
+			name:    "missing template file",
+			args:    []string{"--template", filepath.Join(dir, "missing.tmpl")},
+			wantErr: "read template:",
+		},
+		{
+			name:    "trailing content after JSON",
+			args:    []string{"--data", invalidJSON, "--template-string={{ .data.a }}"},
+			wantErr: "unexpected content after the JSON value",
+		},
+		{
+			name:    "unset environment variable",
+			args:    []string{"--template-string={{ .env.TELEGRAM_OWL_TEST_UNSET }}"},
+			wantErr: `map has no entry for key "TELEGRAM_OWL_TEST_UNSET"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			app := cli.NewApp("http://127.0.0.1:0")
+			app.Writer = new(bytes.Buffer)
+			app.ErrWriter = new(bytes.Buffer)
+			err := app.Run(t.Context(), args)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package tests_test
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+const topicCreatedUpdates = `{"ok":true,"result":[
+	{"update_id":100,"message":{"message_id":5,"message_thread_id":11,"is_topic_message":true,
+		"chat":{"id":-1002,"type":"supergroup","title":"Dev team","is_forum":true},
+		"forum_topic_created":{"name":"Deployments"}}}
+]}`
+
+// recordingServer answers each endpoint with a fixed response and records the
+// endpoints and request bodies in call order.
+type recordingServer struct {
+	mu        sync.Mutex
+	responses map[string]string
+	calls     []string
+	bodies    map[string]string
+}
+
+func newRecordingServer(t *testing.T, responses map[string]string) (*recordingServer, string) {
+	t.Helper()
+
+	rs := &recordingServer{responses: responses, bodies: make(map[string]string)}
+	mockServer, _ := setupMockServer(t, func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		endpoint := path.Base(r.URL.Path)
+
+		rs.mu.Lock()
+		rs.calls = append(rs.calls, endpoint)
+		rs.bodies[endpoint] = strings.TrimSpace(string(bodyBytes))
+		response, ok := rs.responses[endpoint]
+		rs.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			t.Errorf("unexpected request: %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		if strings.Contains(response, `"ok":false`) {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		_, _ = w.Write([]byte(response))
+	})
+
+	return rs, mockServer.URL
+}
+
+func TestSendMessage_TopicResolvedFromUpdatesThenCache(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	args := []string{
+		"--token=123:abc", "--chat=-1002", "--topic=Deployments", "--cache-dir=" + cacheDir, "-m", "Deployed",
+	}
+
+	rs, serverURL := newRecordingServer(t, map[string]string{
+		"getUpdates":  topicCreatedUpdates,
+		"sendMessage": `{"ok":true,"result":{}}`,
+	})
+
+	require.NoError(t, cli.NewApp(serverURL).Run(t.Context(), getTestArgs(args)))
+	assert.Equal(t, []string{"getUpdates", "sendMessage"}, rs.calls)
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":"11","text":"Deployed"}`, rs.bodies["sendMessage"])
+
+	rs.calls = nil
+	require.NoError(t, cli.NewApp(serverURL).Run(t.Context(), getTestArgs(args)))
+	assert.Equal(t, []string{"sendMessage"}, rs.calls, "second run must use the cached ID")
+}
+
+func TestSendMessage_CreateTopic(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	rs, serverURL := newRecordingServer(t, map[string]string{
+		"getUpdates":       `{"ok":true,"result":[]}`,
+		"createForumTopic": `{"ok":true,"result":{"message_thread_id":27,"name":"Backups","icon_color":16478047}}`,
+		"sendMessage":      `{"ok":true,"result":{}}`,
+	})
+
+	err := cli.NewApp(serverURL).Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc",
+		"--chat=@devteam",
+		"--topic=Backups",
+		"--create-topic",
+		"--topic-icon-color=red",
+		"--cache-dir=" + cacheDir,
+		"-m", "Backup done",
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"getUpdates", "createForumTopic", "sendMessage"}, rs.calls)
+	assert.JSONEq(t, `{"chat_id":"@devteam","name":"Backups","icon_color":16478047}`, rs.bodies["createForumTopic"])
+	assert.JSONEq(t, `{"chat_id":"@devteam","message_thread_id":"27","text":"Backup done"}`, rs.bodies["sendMessage"])
+
+	// The created topic is cached, so it is not created twice.
+	rs.calls = nil
+	err = cli.NewApp(serverURL).Run(t.Context(), getTestArgs([]string{
+		"--token=123:abc", "--chat=@devteam", "--topic=Backups", "--create-topic",
+		"--cache-dir=" + cacheDir, "-m", "Backup done",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sendMessage"}, rs.calls)
+}
+
+func TestSendMessage_CreateTopicOnlyWhenNotFound(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		updates   string
+		wantCalls []string
+		wantErr   string
+	}{
+		{
+			name: "webhook blocks the lookup",
+			updates: `{"ok":false,"error_code":409,` +
+				`"description":"Conflict: can't use getUpdates method while webhook is active"}`,
+			wantCalls: []string{"getUpdates", "createForumTopic", "sendMessage"},
+		},
+		{
+			name:      "other lookup errors are returned",
+			updates:   `{"ok":false,"error_code":401,"description":"Unauthorized"}`,
+			wantCalls: []string{"getUpdates"},
+			wantErr:   `look up topic "Backups" in pending updates`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rs, serverURL := newRecordingServer(t, map[string]string{
+				"getUpdates":       tt.updates,
+				"createForumTopic": `{"ok":true,"result":{"message_thread_id":27,"name":"Backups"}}`,
+				"sendMessage":      `{"ok":true,"result":{}}`,
+			})
+
+			err := cli.NewApp(serverURL).Run(t.Context(), getTestArgs([]string{
+				"--token=123:abc", "--chat=-1002", "--topic=Backups", "--create-topic",
+				"--cache-dir=" + t.TempDir(), "-m", "Backup done",
+			}))
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, rs.calls)
+		})
+	}
+}
+
+func TestSendMessage_StaleCachedTopicIsForgotten(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	rs, serverURL := newRecordingServer(t, map[string]string{
+		"createForumTopic": `{"ok":true,"result":{"message_thread_id":11,"name":"Deployments","icon_color":7322096}}`,
+		"sendMessage":      `{"ok":false,"error_code":400,"description":"Bad Request: message thread not found"}`,
+		"getUpdates":       `{"ok":true,"result":[]}`,
+	})
+
+	require.NoError(t, cli.NewApp(serverURL).Run(t.Context(), getTestArgs([]string{
+		"topics", "create", "--token=123:abc", "--chat=-1002", "--name=Deployments", "--cache-dir=" + cacheDir,
+	})))
+
+	args := []string{"--token=123:abc", "--chat=-1002", "--topic=Deployments", "--cache-dir=" + cacheDir, "-m", "Hi"}
+	err := cli.NewApp(serverURL).Run(t.Context(), getTestArgs(args))
+	require.ErrorContains(t, err, "message thread not found")
+
+	rs.calls = nil
+	err = cli.NewApp(serverURL).Run(t.Context(), getTestArgs(args))
+	require.ErrorContains(t, err, `topic not found: "Deployments" in chat -1002; add --create-topic`)
+	assert.Equal(t, []string{"getUpdates"}, rs.calls)
+}
+
+func TestTopics_Subcommands(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := t.TempDir()
+	rs, serverURL := newRecordingServer(t, map[string]string{
+		"createForumTopic": `{"ok":true,"result":{"message_thread_id":11,"name":"Deployments","icon_color":9367192}}`,
+		"editForumTopic":   `{"ok":true,"result":true}`,
+		"closeForumTopic":  `{"ok":true,"result":true}`,
+		"reopenForumTopic": `{"ok":true,"result":true}`,
+		"deleteForumTopic": `{"ok":true,"result":true}`,
+	})
+	run := func(subcommand string, flags ...string) string {
+		t.Helper()
+
+		outputBuf := new(strings.Builder)
+		app := cli.NewApp(serverURL)
+		app.Writer = outputBuf
+		args := []string{"topics", subcommand, "--token=123:abc", "--chat=-1002", "--cache-dir=" + cacheDir}
+		args = append(args, flags...)
+		require.NoError(t, app.Run(t.Context(), getTestArgs(args)))
+
+		return outputBuf.String()
+	}
+
+	assert.Equal(t, "11\n", run("create", "--name=Deployments", "--icon-color=green", "--icon-emoji=123"))
+	assert.JSONEq(t, `{"chat_id":"-1002","name":"Deployments","icon_color":9367192,"icon_custom_emoji_id":"123"}`,
+		rs.bodies["createForumTopic"])
+
+	run("edit", "--topic=Deployments", "--name=Releases")
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11,"name":"Releases"}`, rs.bodies["editForumTopic"])
+
+	// The rename is cached, so the new name resolves without reading updates.
+	run("close", "--topic=Releases")
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11}`, rs.bodies["closeForumTopic"])
+
+	assert.Equal(
+		t,
+		"Topic reopened successfully. Chat ID: -1002. Thread ID: 11\n",
+		run("reopen", "--thread=11", "--verbose"),
+	)
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11}`, rs.bodies["reopenForumTopic"])
+
+	run("delete", "--topic=Releases")
+	assert.JSONEq(t, `{"chat_id":"-1002","message_thread_id":11}`, rs.bodies["deleteForumTopic"])
+
+	assert.Equal(t, []string{
+		"createForumTopic", "editForumTopic", "closeForumTopic", "reopenForumTopic", "deleteForumTopic",
+	}, rs.calls)
+}
+
+func TestTopics_ErrorResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "topic and thread together",
+			args: []string{"--token=x", "--chat=y", "--topic=A", "--thread=1", "-m", "hi"},
+			want: "--topic and --thread cannot be used together",
+		},
+		{
+			name: "create topic without topic",
+			args: []string{"--token=x", "--chat=y", "--create-topic", "-m", "hi"},
+			want: "--create-topic requires --topic",
+		},
+		{
+			name: "icon without create topic",
+			args: []string{"--token=x", "--chat=y", "--topic=A", "--topic-icon-color=red", "-m", "hi"},
+			want: "--topic-icon-color and --topic-icon-emoji require --create-topic",
+		},
+		{
+			name: "subcommand without target",
+			args: []string{"topics", "close", "--token=x", "--chat=y"},
+			want: "either --topic or --thread is required",
+		},
+		{
+			name: "subcommand with invalid thread",
+			args: []string{"topics", "delete", "--token=x", "--chat=y", "--thread=abc"},
+			want: `--thread must be a positive number, got "abc"`,
+		},
+		{
+			name: "unsupported icon color",
+			args: []string{"topics", "create", "--token=x", "--chat=y", "--name=A", "--icon-color=orange"},
+			want: `icon color "orange" is not supported`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			app := cli.NewApp("dummy")
+			err := app.Run(t.Context(), getTestArgs(tt.args))
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tests_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/beeyev/telegram-owl/internal/cli"
+)
+
+// newChartServer serves a chart as image/png under a path without an
+// extension, so only the Content-Type tells it is a photo.
+func newChartServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/charts/daily" {
+			http.NotFound(w, r)
+
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", "5")
+		if r.Method != http.MethodHead {
+			_, _ = io.WriteString(w, "chart")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSendAttachments_URLDownload(t *testing.T) {
+	t.Parallel()
+
+	chartServer := newChartServer(t)
+	local := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(local, []byte("notes"), 0o600))
+
+	albums, err := runAlbums(t, 0, "--attach="+chartServer.URL+"/charts/daily", "--attach="+local)
+	require.NoError(t, err)
+	require.Len(t, albums, 2)
+	assert.Equal(t, []albumMedia{{Type: "photo", Media: "attach://file0", FileName: "daily"}}, albums[0])
+	assert.Equal(t, []albumMedia{{Type: "document", Media: "attach://file0", FileName: "notes.txt"}}, albums[1])
+}
+
+func TestSendAttachments_URLTelegram(t *testing.T) {
+	t.Parallel()
+
+	const chartURL = "https://example.com/charts/daily.png"
+
+	albums, err := runAlbums(t, 0, "--attach="+chartURL, "--url-mode=telegram")
+	require.NoError(t, err)
+	require.Len(t, albums, 1)
+	assert.Equal(t, []albumMedia{{Type: "photo", Media: chartURL}}, albums[0])
+}
+
+func TestSendAttachments_URLErrors(t *testing.T) {
+	t.Parallel()
+
+	chartServer := newChartServer(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "missing file",
+			args:    []string{"--attach=" + chartServer.URL + "/missing.png"},
+			wantErr: "GET " + chartServer.URL + "/missing.png: 404 Not Found",
+		},
+		{
+			name:    "incorrect mode",
+			args:    []string{"--attach=https://example.com/a.png", "--url-mode=proxy"},
+			wantErr: "incorrect value for --url-mode flag, possible values: download, telegram",
+		},
+		{
+			name:    "mode requires attach",
+			args:    []string{"-m", "hello", "--url-mode=telegram"},
+			wantErr: "--url-mode requires --attach",
+		},
+		{
+			name:    "archive",
+			args:    []string{"--attach=https://example.com/a.png", "--archive=zip"},
+			wantErr: "--archive cannot be combined with URL attachments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			args := getTestArgs(append([]string{"--token=123:abc", "--chat=75757"}, tt.args...))
+			err := cli.NewApp("http://127.0.0.1:0").Run(t.Context(), args)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}